@@ -0,0 +1,282 @@
+// Package analysis computes word statistics over the subtitles of an
+// archive: token frequencies, n-grams and per-episode type/token ratios,
+// exportable as CSV for linguistic analysis.
+//
+// Example usage:
+//
+//	corpus, err := analysis.Analyze("rtve-videos", "es", 2)
+//	if err != nil {
+//		log.Println(err) // Episodes that couldn't be read are skipped
+//	}
+//	for _, c := range corpus.Tokens.Top(10) {
+//		fmt.Println(c.Term, c.Count)
+//	}
+package analysis
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	rtve "github.com/rubiojr/rtve-go"
+	"github.com/rubiojr/rtve-go/archive"
+)
+
+// Tokenize splits text into lowercase word tokens. Words are runs of
+// letters, so Spanish accented letters and ñ are kept and punctuation such
+// as ¿ ¡ « » is dropped. Hyphenated words ("franco-alemán") and numbers with
+// separators ("1.500", "3,5") are single tokens.
+func Tokenize(text string) []string {
+	runes := []rune(strings.ToLower(text))
+
+	var tokens []string
+	start := -1
+	for i, r := range runes {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		// Hyphens between words and separators between digits don't end
+		// the word
+		if start >= 0 && i+1 < len(runes) && joins(r, runes[i-1], runes[i+1]) {
+			continue
+		}
+
+		if start >= 0 {
+			tokens = append(tokens, string(runes[start:i]))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, string(runes[start:]))
+	}
+
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+func joins(r, prev, next rune) bool {
+	switch r {
+	case '-':
+		return isWordRune(prev) && isWordRune(next)
+	case '.', ',':
+		return unicode.IsDigit(prev) && unicode.IsDigit(next)
+	}
+	return false
+}
+
+// NGrams returns the n-grams of tokens, their tokens joined by spaces.
+func NGrams(tokens []string, n int) []string {
+	if n <= 0 || len(tokens) < n {
+		return nil
+	}
+
+	ngrams := make([]string, 0, len(tokens)-n+1)
+	for i := 0; i+n <= len(tokens); i++ {
+		ngrams = append(ngrams, strings.Join(tokens[i:i+n], " "))
+	}
+	return ngrams
+}
+
+// Count is the number of occurrences of a term.
+type Count struct {
+	Term  string
+	Count int
+}
+
+// Counter counts term occurrences.
+type Counter map[string]int
+
+// Add counts an occurrence of each of terms.
+func (c Counter) Add(terms []string) {
+	for _, term := range terms {
+		c[term]++
+	}
+}
+
+// Top returns the k most frequent terms, all of them if k <= 0, most
+// frequent first. Ties are sorted alphabetically.
+func (c Counter) Top(k int) []Count {
+	counts := make([]Count, 0, len(c))
+	for term, n := range c {
+		counts = append(counts, Count{Term: term, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Term < counts[j].Term
+	})
+
+	if k > 0 && k < len(counts) {
+		counts = counts[:k]
+	}
+	return counts
+}
+
+// EpisodeStats are the word statistics of an episode's transcript.
+type EpisodeStats struct {
+	// ID is the RTVE video ID
+	ID string
+	// Folder is the day folder holding the episode's files
+	Folder string
+	// Tokens is the number of words
+	Tokens int
+	// Types is the number of distinct words
+	Types int
+}
+
+// TypeTokenRatio returns Types / Tokens, a measure of lexical variety, or
+// zero for episodes without words.
+func (e EpisodeStats) TypeTokenRatio() float64 {
+	if e.Tokens == 0 {
+		return 0
+	}
+	return float64(e.Types) / float64(e.Tokens)
+}
+
+// Corpus accumulates the word statistics of a set of transcripts.
+type Corpus struct {
+	// N is the size of the n-grams counted in NGrams
+	N int
+	// Tokens counts the words of all transcripts
+	Tokens Counter
+	// NGrams counts the n-grams of all transcripts. N-grams don't span
+	// lines, which hold a sentence each in transcripts.
+	NGrams Counter
+	// Episodes holds the statistics of each transcript, in the order they
+	// were added
+	Episodes []EpisodeStats
+}
+
+// NewCorpus returns an empty corpus counting n-grams of size n, none if
+// n < 2.
+func NewCorpus(n int) *Corpus {
+	return &Corpus{N: n, Tokens: Counter{}, NGrams: Counter{}}
+}
+
+// Add adds the transcript of an episode to the corpus (see
+// rtve.Transcript), returning its statistics.
+func (c *Corpus) Add(id, folder, transcript string) EpisodeStats {
+	stats := EpisodeStats{ID: id, Folder: folder}
+	types := make(map[string]bool)
+
+	for _, line := range strings.Split(transcript, "\n") {
+		tokens := Tokenize(line)
+		c.Tokens.Add(tokens)
+		if c.N >= 2 {
+			c.NGrams.Add(NGrams(tokens, c.N))
+		}
+
+		stats.Tokens += len(tokens)
+		for _, token := range tokens {
+			types[token] = true
+		}
+	}
+	stats.Types = len(types)
+
+	c.Episodes = append(c.Episodes, stats)
+	return stats
+}
+
+// Analyze builds a corpus from the subtitles in language lang of the
+// episodes archived under root, counting n-grams of size n. Downloaded
+// subtitles are preferred to imported WebVTT ones; episodes without either
+// are skipped. Episodes that can't be read are skipped too, and their
+// errors returned joined along with the corpus.
+func Analyze(root, lang string, n int) (*Corpus, error) {
+	corpus := NewCorpus(n)
+	var errs []error
+
+	for episode, err := range archive.Episodes(root) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		path := subtitlePath(episode, lang)
+		if path == "" {
+			continue
+		}
+
+		transcript, err := readTranscript(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		corpus.Add(episode.ID, episode.Folder, transcript)
+	}
+
+	return corpus, errors.Join(errs...)
+}
+
+// subtitlePath returns the WebVTT subtitles of episode in lang, or an empty
+// path if there are none.
+func subtitlePath(episode *archive.Episode, lang string) string {
+	var imported string
+	for _, sub := range episode.Subtitles {
+		if sub.Lang != lang || !strings.HasSuffix(sub.Path, ".vtt") {
+			continue
+		}
+		if !sub.Imported {
+			return sub.Path
+		}
+		imported = sub.Path
+	}
+	return imported
+}
+
+func readTranscript(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	cues, err := rtve.ParseVTT(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return rtve.Transcript(cues), nil
+}
+
+// WriteCountsCSV writes counts as CSV, with a term,count header.
+func WriteCountsCSV(w io.Writer, counts []Count) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"term", "count"})
+	for _, c := range counts {
+		cw.Write([]string{c.Term, strconv.Itoa(c.Count)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteEpisodesCSV writes the statistics of episodes as CSV, with an
+// id,folder,tokens,types,type_token_ratio header.
+func WriteEpisodesCSV(w io.Writer, episodes []EpisodeStats) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "folder", "tokens", "types", "type_token_ratio"})
+	for _, e := range episodes {
+		cw.Write([]string{
+			e.ID,
+			e.Folder,
+			strconv.Itoa(e.Tokens),
+			strconv.Itoa(e.Types),
+			strconv.FormatFloat(e.TypeTokenRatio(), 'f', 4, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,126 @@
+package analysis
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := map[string][]string{
+		"¿Qué tal, España?":                {"qué", "tal", "españa"},
+		"¡El niño pingüino!":               {"el", "niño", "pingüino"},
+		"La cumbre franco-alemana - ayer":  {"la", "cumbre", "franco-alemana", "ayer"},
+		"Subió un 3,5% hasta 1.500 euros.": {"subió", "un", "3,5", "hasta", "1.500", "euros"},
+		"«Adiós», dijo.Luego":              {"adiós", "dijo", "luego"},
+		"Cafe\u0301 con leche":             {"cafe\u0301", "con", "leche"},
+		"":                                 nil,
+		"-- ... --":                        nil,
+		"ÉL DIJO":                          {"él", "dijo"},
+		"mitad-":                           {"mitad"},
+	}
+
+	for text, expected := range tests {
+		tokens := Tokenize(text)
+		if !reflect.DeepEqual(tokens, expected) {
+			t.Errorf("Tokenize(%q) = %q, expected %q", text, tokens, expected)
+		}
+	}
+}
+
+func TestNGrams(t *testing.T) {
+	tokens := []string{"buenas", "noches", "a", "todos"}
+	expected := []string{"buenas noches", "noches a", "a todos"}
+	if got := NGrams(tokens, 2); !reflect.DeepEqual(got, expected) {
+		t.Errorf("NGrams = %q, expected %q", got, expected)
+	}
+	if got := NGrams(tokens, 5); got != nil {
+		t.Errorf("Expected no 5-grams, got %q", got)
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	c := NewCorpus(2)
+	stats := c.Add("1", "2025/2025-03-14", "Buenas noches.\nBuenas noches a todos.\n")
+
+	if stats.Tokens != 6 || stats.Types != 4 {
+		t.Errorf("Expected 6 tokens and 4 types, got %+v", stats)
+	}
+	if ratio := stats.TypeTokenRatio(); ratio < 0.66 || ratio > 0.67 {
+		t.Errorf("Unexpected type/token ratio %f", ratio)
+	}
+
+	expected := []Count{{"buenas", 2}, {"noches", 2}}
+	if top := c.Tokens.Top(2); !reflect.DeepEqual(top, expected) {
+		t.Errorf("Top tokens = %v, expected %v", top, expected)
+	}
+
+	// N-grams don't span sentences
+	if c.NGrams["noches buenas"] != 0 {
+		t.Error("Expected n-grams not to span lines")
+	}
+	if c.NGrams["buenas noches"] != 2 {
+		t.Errorf("Expected 2 occurrences of \"buenas noches\", got %d", c.NGrams["buenas noches"])
+	}
+
+	if (EpisodeStats{}).TypeTokenRatio() != 0 {
+		t.Error("Expected a zero ratio for empty episodes")
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"2025/2025-03-14/video_1.json":           `{"id":"1"}`,
+		"2025/2025-03-14/subs/1_es.vtt":          "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nBuenas noches.\n",
+		"2025/2025-03-14/subs/1_es.imported.vtt": "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nIgnorado.\n",
+		"2025/2025-03-15/video_2.json":           `{"id":"2"}`,
+		"2025/2025-03-15/subs/2_es.imported.vtt": "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nBuenos días.\n",
+		"2025/2025-03-16/video_3.json":           `{"id":"3"}`,
+		"2025/2025-03-16/subs/3_en.vtt":          "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nGood evening.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	corpus, err := Analyze(root, "es", 2)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(corpus.Episodes) != 2 || corpus.Episodes[0].ID != "1" || corpus.Episodes[1].ID != "2" {
+		t.Fatalf("Expected episodes 1 and 2, got %+v", corpus.Episodes)
+	}
+	if corpus.Tokens["ignorado"] != 0 {
+		t.Error("Expected downloaded subtitles to be preferred to imported ones")
+	}
+	if corpus.Tokens["días"] != 1 || corpus.NGrams["buenas noches"] != 1 {
+		t.Errorf("Unexpected counts: %v %v", corpus.Tokens, corpus.NGrams)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteCountsCSV(&b, []Count{{"buenas noches", 2}, {"a, b", 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "term,count\nbuenas noches,2\n\"a, b\",1\n"; b.String() != expected {
+		t.Errorf("Unexpected CSV:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := WriteEpisodesCSV(&b, []EpisodeStats{{ID: "1", Folder: "2025/2025-03-14", Tokens: 6, Types: 4}}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "id,folder,tokens,types,type_token_ratio\n1,2025/2025-03-14,6,4,0.6667\n"; b.String() != expected {
+		t.Errorf("Unexpected CSV:\n%s", b.String())
+	}
+}
@@ -35,9 +35,13 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"runtime/debug"
+	"slices"
 	"sort"
+	"strconv"
 	"time"
 
 	rtve "github.com/rubiojr/rtve-go"
@@ -46,6 +50,15 @@ import (
 // ErrMaxVideosReached is returned when the maximum number of videos has been fetched.
 var ErrMaxVideosReached = errors.New("maximum video count reached")
 
+// ErrTooManyErrors is returned when the non-fatal errors of a fetch exceed
+// FetchOptions.MaxErrors or FetchOptions.MaxErrorRate, or when no video of
+// a listing page could be dated.
+var ErrTooManyErrors = errors.New("too many errors")
+
+// ErrStopPaging can be returned by a PageVisitorFunc to stop fetching further
+// pages without reporting an error.
+var ErrStopPaging = errors.New("stop paging")
+
 // VideoResult represents the complete data for a single video,
 // including its metadata and subtitles (if available).
 type VideoResult struct {
@@ -54,12 +67,15 @@ type VideoResult struct {
 	Metadata *rtve.VideoMetadata
 
 	// Subtitles contains the subtitle data for the video.
-	// This field will be nil if subtitles are not available or
-	// if there was an error fetching them.
+	// This field will be nil if there was an error fetching them, and
+	// have no tracks if RTVE publishes no subtitles for the video.
 	Subtitles *rtve.Subtitles
 
 	// SubtitlesError contains any error that occurred while fetching subtitles.
 	// If this is non-nil, the Subtitles field will be nil.
+	//
+	// Use errors.As with *rtve.SubtitlesError to inspect the HTTP status code
+	// and URL of the failed request, e.g. to retry later on 5xx responses.
 	SubtitlesError error
 }
 
@@ -100,6 +116,249 @@ type FetchStats struct {
 
 	// PagesScraped is the number of web pages that were scraped to find videos.
 	PagesScraped int
+
+	// VideosFiltered is the number of videos within the date range that were
+	// skipped because of the duration filters in FetchOptions.
+	VideosFiltered int
+
+	// VideosWithoutSubtitles is the number of videos RTVE publishes no
+	// subtitles for, common for older episodes. They are visited with empty
+	// Subtitles and aren't counted as errors.
+	VideosWithoutSubtitles int
+
+	// TerminationReason records why the fetch operation ended, so automation
+	// can tell a run that finished naturally from one that gave up.
+	TerminationReason TerminationReason
+
+	// ResumePage is the first listing page that wasn't fully processed when
+	// the fetch ended with TerminationTimeBudget. Passing it as
+	// FetchOptions.PageRange.Start resumes the fetch without re-scanning
+	// earlier pages.
+	ResumePage int
+}
+
+// TerminationReason describes why a fetch operation ended.
+type TerminationReason string
+
+const (
+	// TerminationCompleted means all pages in range were processed.
+	TerminationCompleted TerminationReason = "completed"
+
+	// TerminationStopped means a PageVisitorFunc returned ErrStopPaging.
+	TerminationStopped TerminationReason = "stopped"
+
+	// TerminationMaxVideos means the maximum number of videos was reached
+	// while more videos were available.
+	TerminationMaxVideos TerminationReason = "max-videos"
+
+	// TerminationVisitorError means the VisitorFunc or PageVisitorFunc
+	// returned an error.
+	TerminationVisitorError TerminationReason = "visitor-error"
+
+	// TerminationScrapeError means a listing page couldn't be fetched,
+	// even after retrying.
+	TerminationScrapeError TerminationReason = "scrape-error"
+
+	// TerminationTimeBudget means FetchOptions.TimeBudget ran out.
+	TerminationTimeBudget TerminationReason = "time-budget"
+
+	// TerminationTooManyErrors means non-fatal errors exceeded
+	// FetchOptions.MaxErrors or FetchOptions.MaxErrorRate, or no video of a
+	// listing page could be dated.
+	TerminationTooManyErrors TerminationReason = "too-many-errors"
+
+	// TerminationCanceled means the context passed to one of the Context
+	// variants was canceled or its deadline expired.
+	TerminationCanceled TerminationReason = "canceled"
+)
+
+// snapshot returns a copy of the stats that is safe to keep while the
+// fetch operation keeps updating the original.
+func (s *FetchStats) snapshot() FetchStats {
+	c := *s
+	c.Errors = append([]error(nil), s.Errors...)
+	return c
+}
+
+// StatsTicker delivers periodic FetchStats snapshots while a fetch is running,
+// so long-running callers can report progress without waiting for completion.
+//
+// A snapshot is delivered when either condition is met; zero disables a
+// condition. Func is called from the fetching goroutine, so a slow callback
+// slows down fetching.
+type StatsTicker struct {
+	// Interval delivers a snapshot when at least this much time has passed
+	// since the previous one.
+	Interval time.Duration
+
+	// EveryVideos delivers a snapshot every time this many more videos have
+	// been processed.
+	EveryVideos int
+
+	// Func receives each snapshot.
+	Func func(stats FetchStats)
+}
+
+// tickState tracks the snapshots a StatsTicker delivered during a fetch,
+// so the caller's StatsTicker is never modified and can be reused.
+type tickState struct {
+	ticker     *StatsTicker
+	lastTick   time.Time
+	lastVideos int
+}
+
+func (t *tickState) tick(stats *FetchStats) {
+	if t.ticker == nil || t.ticker.Func == nil {
+		return
+	}
+
+	now := time.Now()
+	if t.lastTick.IsZero() {
+		t.lastTick = now
+	}
+
+	due := t.ticker.Interval > 0 && now.Sub(t.lastTick) >= t.ticker.Interval
+	due = due || (t.ticker.EveryVideos > 0 && stats.VideosProcessed-t.lastVideos >= t.ticker.EveryVideos)
+	if !due {
+		return
+	}
+
+	t.lastTick = now
+	t.lastVideos = stats.VideosProcessed
+	t.ticker.Func(stats.snapshot())
+}
+
+// PageInfo describes a listing page once all of its videos have been processed.
+type PageInfo struct {
+	// Page is the zero-based page number.
+	Page int
+
+	// ItemsFound is the number of video links found on the page.
+	ItemsFound int
+
+	// InRange is the number of videos on the page published within
+	// the requested date range.
+	InRange int
+}
+
+// PageVisitorFunc is a function type that observes pagination progress.
+// It is called once per listing page, after the videos on that page have
+// been passed to the VisitorFunc.
+//
+// Returning ErrStopPaging stops fetching further pages and the fetch completes
+// without error. Any other error stops fetching and is returned to the caller.
+type PageVisitorFunc func(info *PageInfo) error
+
+// FetchOptions contains optional settings for FetchShowWithOptions.
+// The zero value is valid and behaves like FetchShow.
+type FetchOptions struct {
+	// PageVisitor, if set, is called after each listing page is processed.
+	// Useful for progress reporting or custom termination logic.
+	PageVisitor PageVisitorFunc
+
+	// MinDuration skips videos shorter than this (e.g. short summary clips).
+	// Zero disables the filter.
+	MinDuration time.Duration
+
+	// MaxDuration skips videos longer than this. Zero disables the filter.
+	MaxDuration time.Duration
+
+	// Corrections overrides known-wrong metadata fields before date
+	// filtering and before results reach the visitor.
+	Corrections rtve.Corrections
+
+	// StatsTicker, if set, receives periodic FetchStats snapshots.
+	StatsTicker *StatsTicker
+
+	// PageRange, if set, limits fetching to a range of listing pages, e.g.
+	// to jump straight to content known to live deep in the catalog.
+	PageRange *PageRange
+
+	// TimeBudget stops fetching cleanly once this much time has elapsed,
+	// setting TerminationReason to TerminationTimeBudget. Zero means no limit.
+	TimeBudget time.Duration
+
+	// RecoverPanics turns panics in the VisitorFunc into *VisitorPanicError
+	// values recorded in FetchStats.Errors, and fetching continues with the
+	// next video. By default panics are not recovered.
+	RecoverPanics bool
+
+	// MaxErrors aborts the fetch with ErrTooManyErrors once more than this
+	// many non-fatal errors have been recorded, catching systemic problems
+	// such as a broken selector early. Zero means no limit.
+	MaxErrors int
+
+	// MaxErrorRate aborts the fetch with ErrTooManyErrors once the ratio of
+	// non-fatal errors to videos attempted exceeds this value (e.g. 0.5 for
+	// 50%). It's only checked after minErrorRateSample videos, so a couple of
+	// early failures don't abort the run. Zero means no limit.
+	MaxErrorRate float64
+
+	// PageSize asks RTVE for this many videos per listing page, see
+	// rtve.WithPageSize. PageRange then counts pages of this size. Zero
+	// keeps RTVE's default.
+	PageSize int
+
+	// Source, if set, replaces RTVE as the source of listing pages,
+	// metadata and subtitles, e.g. a FixtureSource to run the visitor and
+	// everything downstream of it in tests without network access. The
+	// show ID isn't checked against the registered shows, and Corrections
+	// and PageSize, which configure the RTVE source, are ignored.
+	Source Source
+}
+
+// minErrorRateSample is the number of videos that must be attempted before
+// FetchOptions.MaxErrorRate is enforced.
+const minErrorRateSample = 20
+
+// PageRange is a range of zero-based listing page numbers.
+type PageRange struct {
+	// Start is the first page to fetch.
+	Start int
+
+	// End is the last page to fetch (inclusive), so {Start: 0, End: 0}
+	// fetches the first page only. rtve.NoPageLimit, or zero with a
+	// positive Start, fetches up to the last page.
+	End int
+}
+
+// last returns the last page to fetch, or rtve.NoPageLimit.
+func (r *PageRange) last() int {
+	if r.End == 0 && r.Start > 0 {
+		return rtve.NoPageLimit
+	}
+	return r.End
+}
+
+// VisitorPanicError records a panic recovered from a VisitorFunc when
+// FetchOptions.RecoverPanics is set.
+type VisitorPanicError struct {
+	// VideoID is the ID of the video the visitor was processing.
+	VideoID string
+
+	// Value is the value passed to panic.
+	Value any
+
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (e *VisitorPanicError) Error() string {
+	return fmt.Sprintf("visitor panicked on video %s: %v", e.VideoID, e.Value)
+}
+
+// visit calls visitor with result, converting a panic into a
+// *VisitorPanicError if recoverPanics is set.
+func visit(visitor VisitorFunc, result *VideoResult, recoverPanics bool) (err error) {
+	if recoverPanics {
+		defer func() {
+			if v := recover(); v != nil {
+				err = &VisitorPanicError{VideoID: result.Metadata.ID, Value: v, Stack: debug.Stack()}
+			}
+		}()
+	}
+
+	return visitor(result)
 }
 
 // FetchShow fetches video metadata and subtitles for a specific RTVE show
@@ -154,16 +413,53 @@ type FetchStats struct {
 //
 //	fmt.Printf("Successfully processed %d videos\n", stats.VideosProcessed)
 func FetchShow(showID string, startDate, endDate time.Time, visitor VisitorFunc) (*FetchStats, error) {
-	// Validate show ID
-	availableShows := rtve.ListShows()
-	validShow := false
-	for _, show := range availableShows {
-		if show == showID {
-			validShow = true
-			break
-		}
+	return FetchShowWithOptions(showID, startDate, endDate, visitor, nil)
+}
+
+// FetchShowContext works like FetchShow, but stops fetching once ctx is
+// canceled or its deadline expires. The context error is then returned along
+// with the stats gathered so far, and TerminationReason is set to
+// TerminationCanceled.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+//	defer cancel()
+//
+//	stats, err := api.FetchShowContext(ctx, "telediario-1", start, end, visitor)
+//	if errors.Is(err, context.DeadlineExceeded) {
+//		fmt.Printf("Timed out after %d videos\n", stats.VideosProcessed)
+//	}
+func FetchShowContext(ctx context.Context, showID string, startDate, endDate time.Time, visitor VisitorFunc) (*FetchStats, error) {
+	return FetchShowWithOptionsContext(ctx, showID, startDate, endDate, visitor, nil)
+}
+
+// FetchShowWithOptions works like FetchShow but accepts additional options.
+// A nil opts is equivalent to calling FetchShow.
+//
+// Example:
+//
+//	opts := &api.FetchOptions{
+//		PageVisitor: func(info *api.PageInfo) error {
+//			fmt.Printf("Page %d: %d videos, %d in range\n", info.Page, info.ItemsFound, info.InRange)
+//			return nil
+//		},
+//	}
+//
+//	stats, err := api.FetchShowWithOptions("telediario-1", start, end, visitor, opts)
+func FetchShowWithOptions(showID string, startDate, endDate time.Time, visitor VisitorFunc, opts *FetchOptions) (*FetchStats, error) {
+	return FetchShowWithOptionsContext(context.Background(), showID, startDate, endDate, visitor, opts)
+}
+
+// FetchShowWithOptionsContext works like FetchShowWithOptions, stopping once
+// ctx is done. See FetchShowContext.
+func FetchShowWithOptionsContext(ctx context.Context, showID string, startDate, endDate time.Time, visitor VisitorFunc, opts *FetchOptions) (*FetchStats, error) {
+	if opts == nil {
+		opts = &FetchOptions{}
 	}
-	if !validShow {
+
+	// Validate show ID
+	if opts.Source == nil && !slices.Contains(rtve.ListShows(), showID) {
 		return nil, fmt.Errorf("invalid show ID: %s (use rtve.ListShows() to see available shows)", showID)
 	}
 
@@ -172,147 +468,334 @@ func FetchShow(showID string, startDate, endDate time.Time, visitor VisitorFunc)
 		return nil, fmt.Errorf("end date (%s) is before start date (%s)", endDate.Format(time.RFC3339), startDate.Format(time.RFC3339))
 	}
 
+	// Validate page range
+	if r := opts.PageRange; r != nil && r.last() != rtve.NoPageLimit && r.last() < r.Start {
+		return nil, fmt.Errorf("last page (%d) is before first page (%d)", r.End, r.Start)
+	}
+
+	src := opts.Source
+	if src == nil {
+		src = rtve.NewScrapper(showID, rtve.WithCorrections(opts.Corrections), rtve.WithPageSize(opts.PageSize))
+	}
+
+	return fetchShow(ctx, src, startDate, endDate, visitor, opts)
+}
+
+// Source is where FetchShowWithOptions gets a show's listing pages, video
+// metadata and subtitles from: the subset of *rtve.Scrapper it uses. See
+// FetchOptions.Source and FixtureSource.
+//
+// ScrapePageContext returns the videos on a zero-based listing page, newest
+// first, and an error wrapping rtve.ErrPageNotFound, or no videos, past the
+// last page.
+type Source interface {
+	ScrapePageContext(ctx context.Context, page int) ([]*rtve.VideoInfo, error)
+	DownloadVideoMetaContext(ctx context.Context, videoID string) (*rtve.VideoMetadata, error)
+	FetchSubtitlesContext(ctx context.Context, meta *rtve.VideoMetadata) (*rtve.Subtitles, error)
+}
+
+// fetchShow walks the listing pages of src, newest first, visiting every video
+// published within [startDate, endDate].
+//
+// Pages are sorted by publication date in descending order, so fetching stops
+// at the first page whose videos all predate startDate. Because RTVE's ordering
+// is not strict, the next listing page is peeked before stopping: if it contains
+// video IDs newer than the oldest one on the current page, the pages overlap and
+// fetching continues. The peek only uses listing data, and a peeked page is
+// reused by the next iteration, so no page or metadata is downloaded twice.
+func fetchShow(ctx context.Context, src Source, startDate, endDate time.Time, visitor VisitorFunc, opts *FetchOptions) (*FetchStats, error) {
 	stats := &FetchStats{
 		Errors: make([]error, 0),
 	}
 
-	scraper := rtve.NewScrapper(showID)
+	var peeked []*rtve.VideoInfo
 
-	// The date format used by RTVE
-	const rtveLayout = "02-01-2006 15:04:05"
+	// Listing pages can shift while paginating (e.g. a new episode is
+	// published mid-run), so the same video may show up on more than one
+	// page. Metadata is memoized per run so it's never downloaded twice.
+	memo := make(map[string]*rtve.VideoMetadata)
 
-	// Iterate through pages until we're outside the date range
-	// or hit an error
-	page := 0
-	foundVideosInRange := false
+	ticks := &tickState{ticker: opts.StatsTicker}
 
-	for {
-		videos, err := scraper.ScrapePage(page)
-		if err != nil {
-			// If we've found at least one video in range and now hit an error,
-			// we might have just run out of pages - this is OK
-			if foundVideosInRange && (err == rtve.ErrPageNotFound || err == rtve.ErrForbidden) {
-				break
+	started := time.Now()
+	budgetSpent := func() bool {
+		return opts.TimeBudget > 0 && time.Since(started) >= opts.TimeBudget
+	}
+
+	attempted := 0
+	tooManyErrors := func() error {
+		if opts.MaxErrors > 0 && stats.ErrorCount > opts.MaxErrors {
+			return fmt.Errorf("%w: %d errors, the limit is %d", ErrTooManyErrors, stats.ErrorCount, opts.MaxErrors)
+		}
+		if opts.MaxErrorRate > 0 && attempted >= minErrorRateSample {
+			if rate := float64(stats.ErrorCount) / float64(attempted); rate > opts.MaxErrorRate {
+				return fmt.Errorf("%w: %d errors in %d videos, the limit is %.0f%%", ErrTooManyErrors, stats.ErrorCount, attempted, opts.MaxErrorRate*100)
+			}
+		}
+		return nil
+	}
+
+	firstPage, lastPage := 0, rtve.NoPageLimit
+	if opts.PageRange != nil {
+		firstPage, lastPage = opts.PageRange.Start, opts.PageRange.last()
+	}
+
+	for page := firstPage; lastPage == rtve.NoPageLimit || page <= lastPage; page++ {
+		videos := peeked
+		peeked = nil
+
+		if videos == nil {
+			if err := ctx.Err(); err != nil {
+				stats.TerminationReason = TerminationCanceled
+				return stats, err
 			}
-			// Otherwise, it's a real error
-			if err == rtve.ErrPageNotFound || err == rtve.ErrForbidden {
-				// No videos found at all - might be valid if date range is in the future
+
+			var err error
+			videos, err = src.ScrapePageContext(ctx, page)
+			if errors.Is(err, rtve.ErrPageNotFound) || errors.Is(err, rtve.ErrForbidden) {
+				// Ran out of pages
 				break
 			}
-			return stats, fmt.Errorf("error scraping page %d: %w", page, err)
+			if err != nil {
+				if ctx.Err() != nil {
+					stats.TerminationReason = TerminationCanceled
+					return stats, ctx.Err()
+				}
+				stats.TerminationReason = TerminationScrapeError
+				return stats, fmt.Errorf("error scraping page %d: %w", page, err)
+			}
+			stats.PagesScraped++
 		}
 
-		stats.PagesScraped++
-
 		if len(videos) == 0 {
 			// No more videos to process
 			break
 		}
 
-		videosProcessedThisPage := 0
-		allVideosBeforeRange := true
+		inRange := 0
+		dated := 0
+		beforeRange := 0
 
 		for _, videoInfo := range videos {
+			ticks.tick(stats)
+
+			if err := ctx.Err(); err != nil {
+				stats.TerminationReason = TerminationCanceled
+				return stats, err
+			}
+
+			if budgetSpent() {
+				stats.TerminationReason = TerminationTimeBudget
+				stats.ResumePage = page
+				return stats, nil
+			}
+
+			if err := tooManyErrors(); err != nil {
+				stats.TerminationReason = TerminationTooManyErrors
+				return stats, err
+			}
+
 			// Fetch metadata
-			metadata, err := scraper.DownloadVideoMeta(videoInfo.ID)
-			if err != nil {
-				stats.ErrorCount++
-				stats.Errors = append(stats.Errors, fmt.Errorf("error fetching metadata for video %s: %w", videoInfo.ID, err))
-				continue
+			metadata, seen := memo[videoInfo.ID]
+			if !seen {
+				attempted++
+				var err error
+				metadata, err = src.DownloadVideoMetaContext(ctx, videoInfo.ID)
+				if err != nil {
+					if ctx.Err() != nil {
+						stats.TerminationReason = TerminationCanceled
+						return stats, ctx.Err()
+					}
+					stats.ErrorCount++
+					stats.Errors = append(stats.Errors, fmt.Errorf("error fetching metadata for video %s: %w", videoInfo.ID, err))
+					continue
+				}
+				memo[videoInfo.ID] = metadata
 			}
 
 			// Parse publication date
-			pubDate, err := time.Parse(rtveLayout, metadata.PublicationDate)
+			pubDate, err := rtve.ParseRTVEDate(metadata.PublicationDate)
 			if err != nil {
 				stats.ErrorCount++
 				stats.Errors = append(stats.Errors, fmt.Errorf("error parsing date for video %s: %w", videoInfo.ID, err))
 				continue
 			}
+			dated++
 
 			// Check if video is in date range
 			if pubDate.Before(startDate) {
-				// Video is before our range, continue checking others on this page
+				beforeRange++
 				continue
 			}
 
 			if pubDate.After(endDate) {
-				// Video is after our range, but there might be older videos on this page
-				allVideosBeforeRange = false
 				continue
 			}
 
-			// Video is in range!
-			foundVideosInRange = true
-			allVideosBeforeRange = false
+			if seen {
+				// Already visited on a previous page
+				continue
+			}
+
+			if !metadata.DurationInRange(opts.MinDuration, opts.MaxDuration) {
+				stats.VideosFiltered++
+				continue
+			}
 
 			// Fetch subtitles
 			result := &VideoResult{
 				Metadata: metadata,
 			}
 
-			subtitles, err := scraper.FetchSubtitles(metadata)
-			if err != nil {
-				result.SubtitlesError = err
-				stats.ErrorCount++
-				stats.Errors = append(stats.Errors, fmt.Errorf("error fetching subtitles for video %s: %w", videoInfo.ID, err))
-			} else {
-				result.Subtitles = subtitles
+			subtitles, err := src.FetchSubtitlesContext(ctx, metadata)
+			if err != nil && ctx.Err() != nil {
+				stats.TerminationReason = TerminationCanceled
+				return stats, ctx.Err()
 			}
+			recordSubtitles(stats, result, subtitles, err)
 
 			// Call visitor function
-			if err := visitor(result); err != nil {
+			if err := visit(visitor, result, opts.RecoverPanics); err != nil {
+				var panicErr *VisitorPanicError
+				if errors.As(err, &panicErr) {
+					stats.ErrorCount++
+					stats.Errors = append(stats.Errors, err)
+					continue
+				}
+				stats.TerminationReason = TerminationVisitorError
 				return stats, fmt.Errorf("visitor function returned error for video %s: %w", videoInfo.ID, err)
 			}
 
 			stats.VideosProcessed++
-			videosProcessedThisPage++
+			inRange++
 		}
 
-		// If we've found videos in range before, and now all videos on this page
-		// are before our start date, we can stop - pages are sorted by date descending
-		if foundVideosInRange && allVideosBeforeRange {
-			break
+		ticks.tick(stats)
+
+		if err := tooManyErrors(); err != nil {
+			stats.TerminationReason = TerminationTooManyErrors
+			return stats, err
 		}
 
-		// If we didn't process any videos on this page and we've already found some,
-		// we might be past our date range
-		if videosProcessedThisPage == 0 && foundVideosInRange {
-			// Continue for one more page to be sure, but if the next page also
-			// has no results in range, we'll stop
-			page++
-			videos, err := scraper.ScrapePage(page)
-			if err != nil || len(videos) == 0 {
-				break
+		if opts.PageVisitor != nil {
+			info := &PageInfo{
+				Page:       page,
+				ItemsFound: len(videos),
+				InRange:    inRange,
 			}
-			// Check if any videos on next page are in range
-			anyInRange := false
-			for _, videoInfo := range videos {
-				metadata, err := scraper.DownloadVideoMeta(videoInfo.ID)
-				if err != nil {
-					continue
-				}
-				pubDate, err := time.Parse(rtveLayout, metadata.PublicationDate)
-				if err != nil {
-					continue
-				}
-				if !pubDate.Before(startDate) && !pubDate.After(endDate) {
-					anyInRange = true
+			if err := opts.PageVisitor(info); err != nil {
+				if errors.Is(err, ErrStopPaging) {
+					stats.TerminationReason = TerminationStopped
 					break
 				}
+				stats.TerminationReason = TerminationVisitorError
+				return stats, fmt.Errorf("page visitor returned error for page %d: %w", page, err)
 			}
-			if !anyInRange {
-				break
+		}
+
+		// A page without a single dated video, e.g. because the metadata
+		// API is down, says nothing about the date range. Give up rather
+		// than walk the whole catalogue with every download failing.
+		if dated == 0 {
+			stats.TerminationReason = TerminationTooManyErrors
+			return stats, fmt.Errorf("%w: no video on page %d could be dated", ErrTooManyErrors, page)
+		}
+
+		// Keep going while the page still reaches into or above the date range
+		if beforeRange < dated {
+			continue
+		}
+
+		if lastPage != rtve.NoPageLimit && page >= lastPage {
+			break
+		}
+
+		// Every video on this page predates the range. Peek at the next
+		// listing and only continue if it overlaps with this page.
+		next, err := src.ScrapePageContext(ctx, page+1)
+		if errors.Is(err, rtve.ErrPageNotFound) || errors.Is(err, rtve.ErrForbidden) {
+			// Ran out of pages
+			break
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				stats.TerminationReason = TerminationCanceled
+				return stats, ctx.Err()
 			}
-			// If we found some in range, decrement page so the main loop processes it
-			page--
+			stats.TerminationReason = TerminationScrapeError
+			return stats, fmt.Errorf("error scraping page %d: %w", page+1, err)
 		}
+		if len(next) == 0 {
+			break
+		}
+		stats.PagesScraped++
+
+		if !hasNewerVideos(next, oldestVideoID(videos)) {
+			break
+		}
+		peeked = next
+	}
 
-		page++
+	if stats.TerminationReason == "" {
+		stats.TerminationReason = TerminationCompleted
 	}
 
 	return stats, nil
 }
 
+// recordSubtitles stores the outcome of fetching the subtitles of a video
+// in result and stats. Videos without subtitles aren't errors: they get an
+// empty Subtitles and are counted in VideosWithoutSubtitles.
+func recordSubtitles(stats *FetchStats, result *VideoResult, subtitles *rtve.Subtitles, err error) {
+	id := result.Metadata.ID
+	switch {
+	case errors.Is(err, rtve.ErrNoSubtitles):
+		stats.VideosWithoutSubtitles++
+		result.Subtitles = &rtve.Subtitles{VideoID: id}
+	case err != nil:
+		result.SubtitlesError = err
+		stats.ErrorCount++
+		stats.Errors = append(stats.Errors, fmt.Errorf("error fetching subtitles for video %s: %w", id, err))
+	default:
+		if len(subtitles.Subtitles) == 0 {
+			stats.VideosWithoutSubtitles++
+		}
+		result.Subtitles = subtitles
+	}
+}
+
+// oldestVideoID returns the smallest numeric video ID in videos, or -1 if
+// none of the IDs are numeric. RTVE assigns video IDs incrementally, so the
+// ID is a reasonable proxy for publication order in listing data.
+func oldestVideoID(videos []*rtve.VideoInfo) int {
+	oldest := -1
+	for _, v := range videos {
+		id, err := strconv.Atoi(v.ID)
+		if err != nil {
+			continue
+		}
+		if oldest == -1 || id < oldest {
+			oldest = id
+		}
+	}
+	return oldest
+}
+
+// hasNewerVideos reports whether any video in videos has a numeric ID
+// greater than id.
+func hasNewerVideos(videos []*rtve.VideoInfo, id int) bool {
+	if id == -1 {
+		return false
+	}
+	for _, v := range videos {
+		n, err := strconv.Atoi(v.ID)
+		if err == nil && n > id {
+			return true
+		}
+	}
+	return false
+}
+
 // FetchShowAll is a convenience function that fetches all available videos for a show
 // without date restrictions. It's equivalent to calling FetchShow with a very wide date range.
 //
@@ -331,10 +814,16 @@ func FetchShow(showID string, startDate, endDate time.Time, visitor VisitorFunc)
 //		return nil
 //	})
 func FetchShowAll(showID string, visitor VisitorFunc) (*FetchStats, error) {
+	return FetchShowAllContext(context.Background(), showID, visitor)
+}
+
+// FetchShowAllContext works like FetchShowAll, stopping once ctx is done.
+// See FetchShowContext.
+func FetchShowAllContext(ctx context.Context, showID string, visitor VisitorFunc) (*FetchStats, error) {
 	// Use a very wide date range
 	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
 	end := time.Now().Add(24 * time.Hour) // Include today and tomorrow
-	return FetchShow(showID, start, end, visitor)
+	return FetchShowContext(ctx, showID, start, end, visitor)
 }
 
 // FetchShowLatest fetches the most recent videos for a show, up to maxVideos count.
@@ -356,6 +845,12 @@ func FetchShowAll(showID string, visitor VisitorFunc) (*FetchStats, error) {
 //		return nil
 //	})
 func FetchShowLatest(showID string, maxVideos int, visitor VisitorFunc) (*FetchStats, error) {
+	return FetchShowLatestContext(context.Background(), showID, maxVideos, visitor)
+}
+
+// FetchShowLatestContext works like FetchShowLatest, stopping once ctx is
+// done. See FetchShowContext.
+func FetchShowLatestContext(ctx context.Context, showID string, maxVideos int, visitor VisitorFunc) (*FetchStats, error) {
 	// Validate show ID
 	availableShows := rtve.ListShows()
 	validShow := false
@@ -369,13 +864,14 @@ func FetchShowLatest(showID string, maxVideos int, visitor VisitorFunc) (*FetchS
 		return nil, fmt.Errorf("invalid show ID: %s (use rtve.ListShows() to see available shows)", showID)
 	}
 
+	return fetchShowLatest(ctx, rtve.NewScrapper(showID), maxVideos, visitor)
+}
+
+func fetchShowLatest(ctx context.Context, scraper Source, maxVideos int, visitor VisitorFunc) (*FetchStats, error) {
 	stats := &FetchStats{
 		Errors: make([]error, 0),
 	}
 
-	scraper := rtve.NewScrapper(showID)
-	const rtveLayout = "02-01-2006 15:04:05"
-
 	// Collect all videos from the first page(s) to ensure we get the most recent ones
 	// RTVE doesn't return videos in chronological order, so we need to sort them
 	type videoWithDate struct {
@@ -388,17 +884,17 @@ func FetchShowLatest(showID string, maxVideos int, visitor VisitorFunc) (*FetchS
 	maxPagesToScan := 3                   // Scan first 3 pages to ensure we get recent videos
 
 	for page := 0; page < maxPagesToScan; page++ {
-		videos, err := scraper.ScrapePage(page)
+		videos, err := scraper.ScrapePageContext(ctx, page)
 		if err != nil {
-			// If we've found at least one video and now hit an error, stop scanning
-			if len(videosWithDates) > 0 && (err == rtve.ErrPageNotFound || err == rtve.ErrForbidden) {
-				break
+			if ctx.Err() != nil {
+				stats.TerminationReason = TerminationCanceled
+				return stats, ctx.Err()
 			}
-			// Otherwise, it's a real error
-			if err == rtve.ErrPageNotFound || err == rtve.ErrForbidden {
-				// No videos found at all
+			// Ran out of pages
+			if errors.Is(err, rtve.ErrPageNotFound) || errors.Is(err, rtve.ErrForbidden) {
 				break
 			}
+			stats.TerminationReason = TerminationScrapeError
 			return stats, fmt.Errorf("error scraping page %d: %w", page, err)
 		}
 
@@ -415,16 +911,25 @@ func FetchShowLatest(showID string, maxVideos int, visitor VisitorFunc) (*FetchS
 			}
 			seenVideoIDs[videoInfo.ID] = true
 
+			if err := ctx.Err(); err != nil {
+				stats.TerminationReason = TerminationCanceled
+				return stats, err
+			}
+
 			// Fetch metadata
-			metadata, err := scraper.DownloadVideoMeta(videoInfo.ID)
+			metadata, err := scraper.DownloadVideoMetaContext(ctx, videoInfo.ID)
 			if err != nil {
+				if ctx.Err() != nil {
+					stats.TerminationReason = TerminationCanceled
+					return stats, ctx.Err()
+				}
 				stats.ErrorCount++
 				stats.Errors = append(stats.Errors, fmt.Errorf("error fetching metadata for video %s: %w", videoInfo.ID, err))
 				continue
 			}
 
 			// Parse publication date
-			pubDate, err := time.Parse(rtveLayout, metadata.PublicationDate)
+			pubDate, err := rtve.ParseRTVEDate(metadata.PublicationDate)
 			if err != nil {
 				stats.ErrorCount++
 				stats.Errors = append(stats.Errors, fmt.Errorf("error parsing date for video %s: %w", videoInfo.ID, err))
@@ -436,14 +941,12 @@ func FetchShowLatest(showID string, maxVideos int, visitor VisitorFunc) (*FetchS
 				Metadata: metadata,
 			}
 
-			subtitles, err := scraper.FetchSubtitles(metadata)
-			if err != nil {
-				result.SubtitlesError = err
-				stats.ErrorCount++
-				stats.Errors = append(stats.Errors, fmt.Errorf("error fetching subtitles for video %s: %w", videoInfo.ID, err))
-			} else {
-				result.Subtitles = subtitles
+			subtitles, err := scraper.FetchSubtitlesContext(ctx, metadata)
+			if err != nil && ctx.Err() != nil {
+				stats.TerminationReason = TerminationCanceled
+				return stats, ctx.Err()
 			}
+			recordSubtitles(stats, result, subtitles, err)
 
 			videosWithDates = append(videosWithDates, videoWithDate{
 				result:  result,
@@ -458,13 +961,16 @@ func FetchShowLatest(showID string, maxVideos int, visitor VisitorFunc) (*FetchS
 	})
 
 	// Process the most recent videos up to maxVideos
+	stats.TerminationReason = TerminationCompleted
 	count := 0
 	for _, vwd := range videosWithDates {
 		if maxVideos > 0 && count >= maxVideos {
+			stats.TerminationReason = TerminationMaxVideos
 			break
 		}
 
 		if err := visitor(vwd.result); err != nil {
+			stats.TerminationReason = TerminationVisitorError
 			return stats, fmt.Errorf("visitor function returned error for video %s: %w", vwd.result.Metadata.ID, err)
 		}
 
@@ -475,6 +981,78 @@ func FetchShowLatest(showID string, maxVideos int, visitor VisitorFunc) (*FetchS
 	return stats, nil
 }
 
+// LatestEpisode returns the most recently published episode of a show.
+//
+// It is optimized for dashboards that only need to know what the newest episode
+// is and when it was published: a single listing page is downloaded and only the
+// metadata of the newest episode is fetched. The newest episode is picked by video
+// ID, which RTVE assigns incrementally.
+//
+// Parameters:
+//   - showID: The identifier of the show (e.g., "telediario-1").
+//
+// Returns:
+//   - *VideoResult: The newest episode. Only Metadata is populated; subtitles
+//     are not fetched (use SubtitleLanguages or FetchShowLatest if needed).
+//   - error: Any error that occurred, including an invalid show ID.
+//
+// Example:
+//
+//	latest, err := api.LatestEpisode("telediario-2")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("%s (published %s)\n", latest.Metadata.LongTitle, latest.Metadata.PublicationDate)
+func LatestEpisode(showID string) (*VideoResult, error) {
+	return LatestEpisodeContext(context.Background(), showID)
+}
+
+// LatestEpisodeContext works like LatestEpisode, aborting once ctx is done.
+func LatestEpisodeContext(ctx context.Context, showID string) (*VideoResult, error) {
+	if !slices.Contains(rtve.ListShows(), showID) {
+		return nil, fmt.Errorf("invalid show ID: %s (use rtve.ListShows() to see available shows)", showID)
+	}
+
+	return latestEpisode(ctx, rtve.NewScrapper(showID))
+}
+
+func latestEpisode(ctx context.Context, src Source) (*VideoResult, error) {
+	videos, err := src.ScrapePageContext(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error scraping page 0: %w", err)
+	}
+
+	newest := newestVideo(videos)
+	if newest == nil {
+		return nil, fmt.Errorf("no episodes found")
+	}
+
+	metadata, err := src.DownloadVideoMetaContext(ctx, newest.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching metadata for video %s: %w", newest.ID, err)
+	}
+
+	return &VideoResult{Metadata: metadata}, nil
+}
+
+// newestVideo returns the video with the highest numeric ID, or nil if
+// none of the IDs are numeric.
+func newestVideo(videos []*rtve.VideoInfo) *rtve.VideoInfo {
+	var newest *rtve.VideoInfo
+	newestID := -1
+	for _, v := range videos {
+		id, err := strconv.Atoi(v.ID)
+		if err != nil {
+			continue
+		}
+		if id > newestID {
+			newest = v
+			newestID = id
+		}
+	}
+	return newest
+}
+
 // AvailableShows returns a list of all available show IDs that can be used
 // with FetchShow and related functions.
 //
@@ -490,3 +1068,81 @@ func FetchShowLatest(showID string, maxVideos int, visitor VisitorFunc) (*FetchS
 func AvailableShows() []string {
 	return rtve.ListShows()
 }
+
+// SubtitleLanguages returns the language codes of the subtitle tracks available
+// for a video, without downloading the subtitle files themselves.
+//
+// Only the subtitle listing endpoint is queried, which makes this a cheap way to
+// decide whether an episode is worth fetching.
+//
+// Parameters:
+//   - videoID: The RTVE video ID (e.g., "16492499").
+//
+// Returns:
+//   - []string: The language codes (e.g., ["es", "en", "ca"]). The slice is empty
+//     if the video has no subtitles.
+//   - error: Any error that occurred while fetching the subtitle listing.
+//
+// Example:
+//
+//	langs, err := api.SubtitleLanguages("16492499")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("Available languages: %v\n", langs)
+func SubtitleLanguages(videoID string) ([]string, error) {
+	return SubtitleLanguagesContext(context.Background(), videoID)
+}
+
+// SubtitleLanguagesContext works like SubtitleLanguages, aborting once ctx
+// is done.
+func SubtitleLanguagesContext(ctx context.Context, videoID string) ([]string, error) {
+	if videoID == "" {
+		return nil, fmt.Errorf("empty video ID")
+	}
+
+	scraper := rtve.NewScrapper("")
+	subtitles, err := scraper.FetchSubtitlesContext(ctx, &rtve.VideoMetadata{ID: videoID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching subtitles for video %s: %w", videoID, err)
+	}
+
+	langs := make([]string, 0, len(subtitles.Subtitles))
+	for _, item := range subtitles.Subtitles {
+		langs = append(langs, item.Lang)
+	}
+
+	return langs, nil
+}
+
+// SearchPrograms finds the RTVE programs whose name matches query, TV and
+// radio, so any show can be fetched by name, not just the built-in ones.
+//
+// Parameters:
+//   - query: Search terms (e.g., "aquí la tierra").
+//
+// Returns:
+//   - []rtve.Program: The matching programs with their ID, title, media
+//     type and the show name to register them with. Programs without a
+//     page on RTVE Play are left out.
+//   - error: Any error that occurred while searching.
+//
+// Example:
+//
+//	programs, err := api.SearchPrograms("aquí la tierra")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, p := range programs {
+//		fmt.Printf("%s (ID: %s): %s\n", p.Name, p.ID, p.Title)
+//	}
+//	rtve.RegisterShow(programs[0].Name, programs[0].Show())
+func SearchPrograms(query string) ([]rtve.Program, error) {
+	return SearchProgramsContext(context.Background(), query)
+}
+
+// SearchProgramsContext works like SearchPrograms, aborting once ctx is
+// done.
+func SearchProgramsContext(ctx context.Context, query string) ([]rtve.Program, error) {
+	return rtve.NewScrapper("").SearchProgramsContext(ctx, query)
+}
@@ -1,8 +1,11 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	rtve "github.com/rubiojr/rtve-go"
 )
@@ -343,3 +346,857 @@ func TestFetchShowLatest_OffByOneBug(t *testing.T) {
 			buggyProcessed, fixedProcessed)
 	}
 }
+
+func TestSubtitleLanguagesEmptyID(t *testing.T) {
+	if _, err := SubtitleLanguages(""); err == nil {
+		t.Error("Expected error for empty video ID")
+	}
+}
+
+// fakeSource serves listing pages and metadata from memory and records
+// how many times each page and video was requested.
+type fakeSource struct {
+	// pages holds the video IDs for each listing page
+	pages [][]string
+	// dates maps a video ID to its publication date in RTVE format
+	dates map[string]string
+	// durations optionally maps a video ID to its duration in milliseconds
+	durations map[string]int64
+	// subtitleErrs optionally maps a video ID to the error fetching its
+	// subtitles fails with
+	subtitleErrs map[string]error
+	// noSubtitles optionally lists video IDs published without subtitles
+	noSubtitles map[string]bool
+
+	pageCalls map[int]int
+	metaCalls map[string]int
+}
+
+func newFakeSource(pages [][]string, dates map[string]string) *fakeSource {
+	return &fakeSource{
+		pages:     pages,
+		dates:     dates,
+		pageCalls: make(map[int]int),
+		metaCalls: make(map[string]int),
+	}
+}
+
+func (f *fakeSource) ScrapePageContext(ctx context.Context, page int) ([]*rtve.VideoInfo, error) {
+	f.pageCalls[page]++
+	if page >= len(f.pages) {
+		return nil, rtve.ErrPageNotFound
+	}
+	var videos []*rtve.VideoInfo
+	for _, id := range f.pages[page] {
+		videos = append(videos, &rtve.VideoInfo{ID: id})
+	}
+	return videos, nil
+}
+
+func (f *fakeSource) DownloadVideoMetaContext(ctx context.Context, videoID string) (*rtve.VideoMetadata, error) {
+	f.metaCalls[videoID]++
+	date, ok := f.dates[videoID]
+	if !ok {
+		return nil, fmt.Errorf("unknown video %s", videoID)
+	}
+	return &rtve.VideoMetadata{ID: videoID, PublicationDate: date, Duration: f.durations[videoID]}, nil
+}
+
+func (f *fakeSource) FetchSubtitlesContext(ctx context.Context, meta *rtve.VideoMetadata) (*rtve.Subtitles, error) {
+	if err := f.subtitleErrs[meta.ID]; err != nil {
+		return nil, err
+	}
+	if f.noSubtitles[meta.ID] {
+		return &rtve.Subtitles{VideoID: meta.ID}, nil
+	}
+	return &rtve.Subtitles{VideoID: meta.ID, Subtitles: []rtve.SubtitleItem{{Lang: "es"}}}, nil
+}
+
+func day(d int) time.Time {
+	return time.Date(2025, 10, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestFetchShowPagination(t *testing.T) {
+	// Three videos per page, one per day, newest first
+	dates := map[string]string{
+		"109": "09-10-2025 21:00:00",
+		"108": "08-10-2025 21:00:00",
+		"107": "07-10-2025 21:00:00",
+		"106": "06-10-2025 21:00:00",
+		"105": "05-10-2025 21:00:00",
+		"104": "04-10-2025 21:00:00",
+		"103": "03-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+		"101": "01-10-2025 21:00:00",
+	}
+	pages := [][]string{
+		{"109", "108", "107"},
+		{"106", "105", "104"},
+		{"103", "102", "101"},
+	}
+
+	tests := []struct {
+		name          string
+		start         time.Time
+		end           time.Time
+		expectedIDs   []string
+		expectedPages int
+	}{
+		{
+			name:          "range across page edge",
+			start:         day(5),
+			end:           day(7).Add(23 * time.Hour),
+			expectedIDs:   []string{"107", "106", "105"},
+			expectedPages: 3, // page 2 predates the range, page 3 is never requested
+		},
+		{
+			name:          "inclusive boundaries",
+			start:         time.Date(2025, 10, 4, 21, 0, 0, 0, time.UTC),
+			end:           time.Date(2025, 10, 6, 21, 0, 0, 0, time.UTC),
+			expectedIDs:   []string{"106", "105", "104"},
+			expectedPages: 3,
+		},
+		{
+			name:          "range ends on first video of a page",
+			start:         day(1),
+			end:           time.Date(2025, 10, 3, 21, 0, 0, 0, time.UTC),
+			expectedIDs:   []string{"103", "102", "101"},
+			expectedPages: 3,
+		},
+		{
+			name:          "range older than all videos",
+			start:         day(1).AddDate(0, -1, 0),
+			end:           day(1).AddDate(0, 0, -1),
+			expectedIDs:   nil,
+			expectedPages: 3,
+		},
+		{
+			name:          "range newer than all videos",
+			start:         day(20),
+			end:           day(21),
+			expectedIDs:   nil,
+			expectedPages: 2, // page 0 predates the range, page 1 is peeked
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := newFakeSource(pages, dates)
+
+			var visited []string
+			visitor := func(result *VideoResult) error {
+				visited = append(visited, result.Metadata.ID)
+				return nil
+			}
+
+			stats, err := fetchShow(context.Background(), src, tt.start, tt.end, visitor, &FetchOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if fmt.Sprint(visited) != fmt.Sprint(tt.expectedIDs) {
+				t.Errorf("Expected videos %v, got %v", tt.expectedIDs, visited)
+			}
+
+			if stats.VideosProcessed != len(tt.expectedIDs) {
+				t.Errorf("Expected VideosProcessed=%d, got %d", len(tt.expectedIDs), stats.VideosProcessed)
+			}
+
+			if stats.PagesScraped != tt.expectedPages {
+				t.Errorf("Expected PagesScraped=%d, got %d", tt.expectedPages, stats.PagesScraped)
+			}
+
+			for page, calls := range src.pageCalls {
+				if calls > 1 {
+					t.Errorf("Page %d was requested %d times", page, calls)
+				}
+			}
+
+			for id, calls := range src.metaCalls {
+				if calls > 1 {
+					t.Errorf("Metadata for %s was requested %d times", id, calls)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchShowOverlappingPages(t *testing.T) {
+	// Page 0 only has old videos, but page 1 has newer IDs: RTVE's ordering
+	// is not strict, so fetching must continue into page 1.
+	dates := map[string]string{
+		"101": "01-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+		"105": "05-10-2025 21:00:00",
+		"100": "30-09-2025 21:00:00",
+	}
+	pages := [][]string{
+		{"102", "101"},
+		{"105", "100"},
+	}
+
+	src := newFakeSource(pages, dates)
+
+	var visited []string
+	stats, err := fetchShow(context.Background(), src, day(4), day(6), func(result *VideoResult) error {
+		visited = append(visited, result.Metadata.ID)
+		return nil
+	}, &FetchOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(visited) != "[105]" {
+		t.Errorf("Expected videos [105], got %v", visited)
+	}
+
+	if src.pageCalls[1] != 1 {
+		t.Errorf("Expected peeked page to be requested once, got %d", src.pageCalls[1])
+	}
+
+	if stats.PagesScraped != 2 {
+		t.Errorf("Expected PagesScraped=2, got %d", stats.PagesScraped)
+	}
+}
+
+func TestFetchShowDuplicateAcrossPages(t *testing.T) {
+	// A new episode published mid-run shifts the listing, so "104" shows up
+	// at the end of page 0 and again at the start of page 1.
+	dates := map[string]string{
+		"106": "06-10-2025 21:00:00",
+		"105": "05-10-2025 21:00:00",
+		"104": "04-10-2025 21:00:00",
+		"103": "03-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+	}
+	pages := [][]string{
+		{"106", "105", "104"},
+		{"104", "103", "102"},
+	}
+
+	src := newFakeSource(pages, dates)
+
+	var visited []string
+	stats, err := fetchShow(context.Background(), src, day(3), day(7), func(result *VideoResult) error {
+		visited = append(visited, result.Metadata.ID)
+		return nil
+	}, &FetchOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(visited) != "[106 105 104 103]" {
+		t.Errorf("Expected videos [106 105 104 103], got %v", visited)
+	}
+
+	if stats.VideosProcessed != 4 {
+		t.Errorf("Expected VideosProcessed=4, got %d", stats.VideosProcessed)
+	}
+
+	if src.metaCalls["104"] != 1 {
+		t.Errorf("Expected metadata for 104 to be requested once, got %d", src.metaCalls["104"])
+	}
+}
+
+func TestLatestEpisode(t *testing.T) {
+	dates := map[string]string{
+		"101": "01-10-2025 21:00:00",
+		"103": "03-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+	}
+	src := newFakeSource([][]string{{"101", "103", "102"}}, dates)
+
+	result, err := latestEpisode(context.Background(), src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.Metadata.ID != "103" {
+		t.Errorf("Expected newest video 103, got %s", result.Metadata.ID)
+	}
+
+	if len(src.metaCalls) != 1 {
+		t.Errorf("Expected a single metadata request, got %d", len(src.metaCalls))
+	}
+
+	if src.pageCalls[0] != 1 || len(src.pageCalls) != 1 {
+		t.Errorf("Expected a single listing request, got %v", src.pageCalls)
+	}
+}
+
+func TestLatestEpisodeErrors(t *testing.T) {
+	if _, err := LatestEpisode("non-existent-show"); err == nil {
+		t.Error("Expected error for invalid show ID")
+	}
+
+	src := newFakeSource([][]string{{}}, nil)
+	if _, err := latestEpisode(context.Background(), src); err == nil {
+		t.Error("Expected error for empty listing")
+	}
+}
+
+func TestFetchShowDurationFilter(t *testing.T) {
+	dates := map[string]string{
+		"103": "03-10-2025 21:00:00",
+		"102": "03-10-2025 20:00:00",
+		"101": "03-10-2025 15:00:00",
+	}
+	src := newFakeSource([][]string{{"103", "102", "101"}}, dates)
+	src.durations = map[string]int64{
+		"103": 45 * 60 * 1000,
+		"102": 2 * 60 * 1000, // summary clip
+		// 101 has an unknown duration
+	}
+
+	var visited []string
+	opts := &FetchOptions{MinDuration: 5 * time.Minute}
+	stats, err := fetchShow(context.Background(), src, day(3), day(4), func(result *VideoResult) error {
+		visited = append(visited, result.Metadata.ID)
+		return nil
+	}, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(visited) != "[103 101]" {
+		t.Errorf("Expected videos [103 101], got %v", visited)
+	}
+
+	if stats.VideosFiltered != 1 {
+		t.Errorf("Expected VideosFiltered=1, got %d", stats.VideosFiltered)
+	}
+}
+
+func TestFetchShowStatsTicker(t *testing.T) {
+	dates := map[string]string{
+		"105": "05-10-2025 21:00:00",
+		"104": "04-10-2025 21:00:00",
+		"103": "03-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+		"101": "01-10-2025 21:00:00",
+	}
+	src := newFakeSource([][]string{{"105", "104", "103"}, {"102", "101"}}, dates)
+
+	var snapshots []FetchStats
+	opts := &FetchOptions{
+		StatsTicker: &StatsTicker{
+			EveryVideos: 2,
+			Func: func(stats FetchStats) {
+				snapshots = append(snapshots, stats)
+			},
+		},
+	}
+
+	// The same options can be reused, the ticker keeps no state between fetches
+	for run := 0; run < 2; run++ {
+		snapshots = nil
+		_, err := fetchShow(context.Background(), src, day(1), day(6), func(result *VideoResult) error {
+			return nil
+		}, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var processed []int
+		for _, snap := range snapshots {
+			processed = append(processed, snap.VideosProcessed)
+		}
+
+		if fmt.Sprint(processed) != "[2 4]" {
+			t.Errorf("Run %d: expected snapshots after 2 and 4 videos, got %v", run, processed)
+		}
+	}
+}
+
+func TestStatsTickerSnapshotIsCopy(t *testing.T) {
+	stats := &FetchStats{Errors: []error{fmt.Errorf("first")}}
+
+	var snap FetchStats
+	ticker := &tickState{ticker: &StatsTicker{EveryVideos: 1, Func: func(s FetchStats) { snap = s }}}
+
+	stats.VideosProcessed = 1
+	ticker.tick(stats)
+
+	stats.Errors[0] = fmt.Errorf("changed")
+	stats.Errors = append(stats.Errors, fmt.Errorf("second"))
+
+	if len(snap.Errors) != 1 || snap.Errors[0].Error() != "first" {
+		t.Errorf("Snapshot should not change with the running stats: %v", snap.Errors)
+	}
+}
+
+func TestFetchShowTerminationReason(t *testing.T) {
+	dates := map[string]string{
+		"102": "02-10-2025 21:00:00",
+		"101": "01-10-2025 21:00:00",
+	}
+	pages := [][]string{{"102"}, {"101"}}
+	noop := func(result *VideoResult) error { return nil }
+
+	tests := []struct {
+		name     string
+		visitor  VisitorFunc
+		opts     *FetchOptions
+		expected TerminationReason
+	}{
+		{
+			name:     "completed",
+			visitor:  noop,
+			opts:     &FetchOptions{},
+			expected: TerminationCompleted,
+		},
+		{
+			name: "visitor error",
+			visitor: func(result *VideoResult) error {
+				return fmt.Errorf("boom")
+			},
+			opts:     &FetchOptions{},
+			expected: TerminationVisitorError,
+		},
+		{
+			name:    "stopped by page visitor",
+			visitor: noop,
+			opts: &FetchOptions{PageVisitor: func(info *PageInfo) error {
+				return ErrStopPaging
+			}},
+			expected: TerminationStopped,
+		},
+		{
+			name:    "page visitor error",
+			visitor: noop,
+			opts: &FetchOptions{PageVisitor: func(info *PageInfo) error {
+				return fmt.Errorf("boom")
+			}},
+			expected: TerminationVisitorError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := newFakeSource(pages, dates)
+			stats, _ := fetchShow(context.Background(), src, day(1), day(3), tt.visitor, tt.opts)
+			if stats.TerminationReason != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, stats.TerminationReason)
+			}
+		})
+	}
+}
+
+func TestFetchShowPageRange(t *testing.T) {
+	dates := map[string]string{
+		"106": "06-10-2025 21:00:00",
+		"105": "05-10-2025 21:00:00",
+		"104": "04-10-2025 21:00:00",
+		"103": "03-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+		"101": "01-10-2025 21:00:00",
+	}
+	pages := [][]string{{"106", "105"}, {"104", "103"}, {"102", "101"}}
+
+	src := newFakeSource(pages, dates)
+
+	var visited []string
+	opts := &FetchOptions{PageRange: &PageRange{Start: 1, End: 1}}
+	stats, err := fetchShow(context.Background(), src, day(1), day(7), func(result *VideoResult) error {
+		visited = append(visited, result.Metadata.ID)
+		return nil
+	}, opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if fmt.Sprint(visited) != "[104 103]" {
+		t.Errorf("Expected videos [104 103], got %v", visited)
+	}
+
+	if src.pageCalls[0] != 0 || src.pageCalls[2] != 0 {
+		t.Errorf("Only page 1 should be requested, got %v", src.pageCalls)
+	}
+
+	if stats.PagesScraped != 1 {
+		t.Errorf("Expected PagesScraped=1, got %d", stats.PagesScraped)
+	}
+}
+
+func TestFetchShowPageRangeFirstPage(t *testing.T) {
+	dates := map[string]string{
+		"104": "04-10-2025 21:00:00",
+		"103": "03-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+		"101": "01-10-2025 21:00:00",
+	}
+	pages := [][]string{{"104", "103"}, {"102", "101"}}
+
+	for _, tc := range []struct {
+		pageRange PageRange
+		expected  string
+	}{
+		{PageRange{Start: 0, End: 0}, "[104 103]"},
+		{PageRange{Start: 1, End: rtve.NoPageLimit}, "[102 101]"},
+		// A zero End keeps meaning "from Start on"
+		{PageRange{Start: 1}, "[102 101]"},
+	} {
+		src := newFakeSource(pages, dates)
+		var visited []string
+		opts := &FetchOptions{PageRange: &tc.pageRange, Source: src}
+		_, err := FetchShowWithOptions("", day(1), day(7), func(result *VideoResult) error {
+			visited = append(visited, result.Metadata.ID)
+			return nil
+		}, opts)
+		if err != nil {
+			t.Fatalf("Unexpected error for %+v: %v", tc.pageRange, err)
+		}
+		if fmt.Sprint(visited) != tc.expected {
+			t.Errorf("Expected videos %s for %+v, got %v", tc.expected, tc.pageRange, visited)
+		}
+	}
+
+	// Page 0 predates the range, but the next page isn't peeked at
+	src := newFakeSource(pages, dates)
+	opts := &FetchOptions{PageRange: &PageRange{Start: 0, End: 0}, Source: src}
+	stats, err := FetchShowWithOptions("", day(8), day(9), func(*VideoResult) error { return nil }, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.pageCalls[1] != 0 || stats.PagesScraped != 1 {
+		t.Errorf("Only page 0 should be requested, got %v and PagesScraped=%d", src.pageCalls, stats.PagesScraped)
+	}
+
+	opts = &FetchOptions{PageRange: &PageRange{Start: 2, End: 1}, Source: newFakeSource(pages, dates)}
+	if _, err := FetchShowWithOptions("", day(1), day(7), func(*VideoResult) error { return nil }, opts); err == nil {
+		t.Error("Expected an error for a page range ending before it starts")
+	}
+}
+
+func TestFetchShowTimeBudget(t *testing.T) {
+	dates := map[string]string{
+		"102": "02-10-2025 21:00:00",
+		"101": "01-10-2025 21:00:00",
+	}
+	src := newFakeSource([][]string{{"102", "101"}}, dates)
+
+	visited := 0
+	opts := &FetchOptions{TimeBudget: time.Nanosecond}
+	stats, err := fetchShow(context.Background(), src, day(1), day(3), func(result *VideoResult) error {
+		visited++
+		return nil
+	}, opts)
+	if err != nil {
+		t.Fatalf("A spent time budget should not be an error: %v", err)
+	}
+
+	if visited != 0 {
+		t.Errorf("Expected no videos to be visited, got %d", visited)
+	}
+
+	if stats.TerminationReason != TerminationTimeBudget {
+		t.Errorf("Expected %q, got %q", TerminationTimeBudget, stats.TerminationReason)
+	}
+
+	// The page to resume from is reported
+	src = newFakeSource([][]string{{"104", "103"}, {"102", "101"}}, dates)
+	opts = &FetchOptions{TimeBudget: time.Nanosecond, PageRange: &PageRange{Start: 1}}
+	stats, err = fetchShow(context.Background(), src, day(1), day(3), func(*VideoResult) error { return nil }, opts)
+	if err != nil || stats.ResumePage != 1 {
+		t.Errorf("Expected to resume from page 1, got %d, %v", stats.ResumePage, err)
+	}
+}
+
+func TestFetchShowCanceled(t *testing.T) {
+	dates := map[string]string{
+		"103": "03-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+		"101": "01-10-2025 21:00:00",
+	}
+	src := newFakeSource([][]string{{"103", "102", "101"}}, dates)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	visited := 0
+	stats, err := fetchShow(ctx, src, day(1), day(4), func(result *VideoResult) error {
+		visited++
+		// Cancel from the visitor, as a signal handler would mid-run
+		cancel()
+		return nil
+	}, &FetchOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	if visited != 1 {
+		t.Errorf("Expected fetching to stop after 1 video, got %d", visited)
+	}
+
+	if stats.VideosProcessed != 1 {
+		t.Errorf("Expected VideosProcessed=1, got %d", stats.VideosProcessed)
+	}
+
+	if stats.TerminationReason != TerminationCanceled {
+		t.Errorf("Expected %q, got %q", TerminationCanceled, stats.TerminationReason)
+	}
+}
+
+// cancelingSource cancels its context while fetching subtitles, as a
+// signal handler would mid-request.
+type cancelingSource struct {
+	*fakeSource
+	cancel context.CancelFunc
+}
+
+func (c *cancelingSource) FetchSubtitlesContext(ctx context.Context, meta *rtve.VideoMetadata) (*rtve.Subtitles, error) {
+	c.cancel()
+	return nil, ctx.Err()
+}
+
+func TestFetchShowLatestCanceled(t *testing.T) {
+	dates := map[string]string{
+		"103": "03-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+		"101": "01-10-2025 21:00:00",
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := &cancelingSource{fakeSource: newFakeSource([][]string{{"103", "102", "101"}}, dates), cancel: cancel}
+
+	visited := 0
+	stats, err := fetchShowLatest(ctx, src, 3, func(result *VideoResult) error {
+		visited++
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if visited != 0 || len(src.metaCalls) != 1 {
+		t.Errorf("Expected fetching to stop at the first video, visited %d, metadata calls %v", visited, src.metaCalls)
+	}
+	if stats.ErrorCount != 0 || stats.TerminationReason != TerminationCanceled {
+		t.Errorf("Expected a canceled fetch without errors, got %d errors, %q", stats.ErrorCount, stats.TerminationReason)
+	}
+}
+
+func TestFetchShowRecoverPanics(t *testing.T) {
+	dates := map[string]string{
+		"103": "03-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+		"101": "01-10-2025 21:00:00",
+	}
+	src := newFakeSource([][]string{{"103", "102", "101"}}, dates)
+
+	visitor := func(result *VideoResult) error {
+		if result.Metadata.ID == "102" {
+			panic("bad callback")
+		}
+		return nil
+	}
+
+	stats, err := fetchShow(context.Background(), src, day(1), day(4), visitor, &FetchOptions{RecoverPanics: true})
+	if err != nil {
+		t.Fatalf("A recovered panic should not stop fetching: %v", err)
+	}
+
+	if stats.VideosProcessed != 2 {
+		t.Errorf("Expected VideosProcessed=2, got %d", stats.VideosProcessed)
+	}
+
+	if stats.ErrorCount != 1 || len(stats.Errors) != 1 {
+		t.Fatalf("Expected a single error, got %v", stats.Errors)
+	}
+
+	var panicErr *VisitorPanicError
+	if !errors.As(stats.Errors[0], &panicErr) {
+		t.Fatalf("Expected *VisitorPanicError, got %v", stats.Errors[0])
+	}
+	if panicErr.VideoID != "102" || panicErr.Value != "bad callback" || len(panicErr.Stack) == 0 {
+		t.Errorf("Unexpected panic error: %+v", panicErr)
+	}
+}
+
+func TestFetchShowPanicsByDefault(t *testing.T) {
+	src := newFakeSource([][]string{{"101"}}, map[string]string{"101": "01-10-2025 21:00:00"})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected the visitor panic to propagate")
+		}
+	}()
+
+	fetchShow(context.Background(), src, day(1), day(4), func(result *VideoResult) error {
+		panic("bad callback")
+	}, &FetchOptions{})
+}
+
+func TestFetchShowMaxErrors(t *testing.T) {
+	// Only the first video has metadata, the rest fail
+	src := newFakeSource([][]string{{"105", "104", "103", "102", "101"}}, map[string]string{"105": "05-10-2025 21:00:00"})
+
+	stats, err := fetchShow(context.Background(), src, day(1), day(6), func(result *VideoResult) error {
+		return nil
+	}, &FetchOptions{MaxErrors: 2})
+	if !errors.Is(err, ErrTooManyErrors) {
+		t.Fatalf("Expected ErrTooManyErrors, got %v", err)
+	}
+
+	if stats.ErrorCount != 3 {
+		t.Errorf("Expected the run to abort on the 3rd error, got %d errors", stats.ErrorCount)
+	}
+
+	if src.metaCalls["101"] != 0 {
+		t.Error("Expected no more videos to be fetched after aborting")
+	}
+
+	if stats.TerminationReason != TerminationTooManyErrors {
+		t.Errorf("Expected %q, got %q", TerminationTooManyErrors, stats.TerminationReason)
+	}
+}
+
+func TestFetchShowMaxErrorRate(t *testing.T) {
+	var page []string
+	dates := make(map[string]string)
+	for i := 0; i < 2*minErrorRateSample; i++ {
+		id := fmt.Sprintf("%d", 1000-i)
+		page = append(page, id)
+		// Every other video fails
+		if i%2 == 0 {
+			dates[id] = "02-10-2025 21:00:00"
+		}
+	}
+
+	tests := []struct {
+		name    string
+		rate    float64
+		wantErr bool
+	}{
+		{"below limit", 0.6, false},
+		{"above limit", 0.4, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := newFakeSource([][]string{page}, dates)
+			stats, err := fetchShow(context.Background(), src, day(1), day(3), func(result *VideoResult) error {
+				return nil
+			}, &FetchOptions{MaxErrorRate: tt.rate})
+
+			if errors.Is(err, ErrTooManyErrors) != tt.wantErr {
+				t.Fatalf("Unexpected error %v", err)
+			}
+
+			// The rate isn't enforced until enough videos were attempted
+			if tt.wantErr && stats.VideosProcessed+stats.ErrorCount < minErrorRateSample {
+				t.Errorf("Aborted after only %d videos", stats.VideosProcessed+stats.ErrorCount)
+			}
+		})
+	}
+}
+
+func TestFetchShowVideosWithoutSubtitles(t *testing.T) {
+	dates := map[string]string{
+		"104": "04-10-2025 21:00:00",
+		"103": "03-10-2025 21:00:00",
+		"102": "02-10-2025 21:00:00",
+		"101": "01-10-2025 21:00:00",
+	}
+	src := newFakeSource([][]string{{"104", "103", "102", "101"}}, dates)
+	src.noSubtitles = map[string]bool{"104": true}
+	src.subtitleErrs = map[string]error{
+		"103": fmt.Errorf("%w for video ID: 103", rtve.ErrNoSubtitles),
+		"102": errors.New("connection reset"),
+	}
+
+	results := make(map[string]*VideoResult)
+	stats, err := fetchShow(context.Background(), src, day(1), day(5), func(result *VideoResult) error {
+		results[result.Metadata.ID] = result
+		return nil
+	}, &FetchOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if stats.VideosWithoutSubtitles != 2 {
+		t.Errorf("Expected 2 videos without subtitles, got %d", stats.VideosWithoutSubtitles)
+	}
+	if stats.ErrorCount != 1 || len(stats.Errors) != 1 {
+		t.Errorf("Expected only the failed fetch to count as an error, got %d: %v", stats.ErrorCount, stats.Errors)
+	}
+
+	for _, id := range []string{"104", "103"} {
+		result := results[id]
+		if result.SubtitlesError != nil || result.Subtitles == nil || len(result.Subtitles.Subtitles) != 0 {
+			t.Errorf("Expected empty subtitles and no error for %s, got %+v", id, result)
+		}
+	}
+	if results["102"].SubtitlesError == nil {
+		t.Error("Expected SubtitlesError for the failed fetch")
+	}
+	if len(results["101"].Subtitles.Subtitles) != 1 {
+		t.Errorf("Expected subtitles for 101, got %+v", results["101"].Subtitles)
+	}
+}
+
+// failingPeekSource fails the scrape of a listing page with err, after
+// calling onFail.
+type failingPeekSource struct {
+	*fakeSource
+	page   int
+	err    error
+	onFail func()
+}
+
+func (f *failingPeekSource) ScrapePageContext(ctx context.Context, page int) ([]*rtve.VideoInfo, error) {
+	if page == f.page {
+		if f.onFail != nil {
+			f.onFail()
+		}
+		return nil, f.err
+	}
+	return f.fakeSource.ScrapePageContext(ctx, page)
+}
+
+func TestFetchShowMetadataOutage(t *testing.T) {
+	// No metadata can be downloaded
+	pages := [][]string{{"109", "108", "107"}, {"106", "105", "104"}, {"103", "102", "101"}}
+	src := newFakeSource(pages, map[string]string{})
+
+	visited := 0
+	stats, err := fetchShow(context.Background(), src, day(1), day(9), func(*VideoResult) error {
+		visited++
+		return nil
+	}, &FetchOptions{})
+	if !errors.Is(err, ErrTooManyErrors) || stats.TerminationReason != TerminationTooManyErrors {
+		t.Errorf("Expected the fetch to give up, got %v (%s)", err, stats.TerminationReason)
+	}
+	if visited != 0 || stats.ErrorCount != 3 {
+		t.Errorf("Expected 3 errors and no video visited, got %d errors and %d visited", stats.ErrorCount, visited)
+	}
+	if src.pageCalls[1] != 0 || src.metaCalls["106"] != 0 {
+		t.Errorf("Expected only the first page to be tried, got page calls %v", src.pageCalls)
+	}
+}
+
+func TestFetchShowPeekErrors(t *testing.T) {
+	// The first page predates the range, so the second one is peeked
+	dates := map[string]string{"102": "02-10-2025 21:00:00", "101": "01-10-2025 21:00:00"}
+	pages := [][]string{{"102", "101"}, {"100"}}
+	visitor := func(*VideoResult) error { return nil }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := &failingPeekSource{fakeSource: newFakeSource(pages, dates), page: 1, err: context.Canceled, onFail: cancel}
+	stats, err := fetchShow(ctx, src, day(5), day(9), visitor, &FetchOptions{})
+	if !errors.Is(err, context.Canceled) || stats.TerminationReason != TerminationCanceled {
+		t.Errorf("Expected a canceled fetch, got %v (%s)", err, stats.TerminationReason)
+	}
+
+	src = &failingPeekSource{fakeSource: newFakeSource(pages, dates), page: 1, err: errors.New("connection reset")}
+	stats, err = fetchShow(context.Background(), src, day(5), day(9), visitor, &FetchOptions{})
+	if err == nil || stats.TerminationReason != TerminationScrapeError {
+		t.Errorf("Expected a scrape error, got %v (%s)", err, stats.TerminationReason)
+	}
+
+	src = &failingPeekSource{fakeSource: newFakeSource(pages, dates), page: 1, err: rtve.ErrPageNotFound}
+	stats, err = fetchShow(context.Background(), src, day(5), day(9), visitor, &FetchOptions{})
+	if err != nil || stats.TerminationReason != TerminationCompleted {
+		t.Errorf("Expected the fetch to complete past the last page, got %v (%s)", err, stats.TerminationReason)
+	}
+}
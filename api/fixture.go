@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	rtve "github.com/rubiojr/rtve-go"
+)
+
+// defaultFixturePageSize is the number of videos per listing page of a
+// FixtureSource created without a page size.
+const defaultFixturePageSize = 20
+
+// FixtureVideo is a video served by a FixtureSource.
+type FixtureVideo struct {
+	// Metadata is the video's metadata. Its ID may be empty, see
+	// NewFixtureSource.
+	Metadata *rtve.VideoMetadata
+	// Subtitles are the video's subtitle tracks, none if empty
+	Subtitles []rtve.SubtitleItem
+}
+
+// FixtureSource is a Source serving a fixed set of videos from memory, to
+// run complete fetches, from the visitor to whatever archives or indexes
+// its results, without network access and with stable video IDs:
+//
+//	src := api.NewFixtureSource([]api.FixtureVideo{
+//		{Metadata: &rtve.VideoMetadata{LongTitle: "Telediario - 21 horas - 14/03/25", PublicationDate: "14-03-2025 21:00:00"}},
+//		{Metadata: &rtve.VideoMetadata{LongTitle: "Telediario - 21 horas - 13/03/25", PublicationDate: "13-03-2025 21:00:00"}},
+//	}, 0)
+//	stats, err := api.FetchShowWithOptions("telediario-2", start, end, visitor, &api.FetchOptions{Source: src})
+//
+// It's safe for concurrent use.
+type FixtureSource struct {
+	videos   []FixtureVideo
+	byID     map[string]int
+	pageSize int
+}
+
+// NewFixtureSource returns a FixtureSource listing videos, newest first as
+// RTVE does, pageSize per page (defaultFixturePageSize if zero or less).
+//
+// Videos without an ID are given one: numbers counting up from the highest
+// numeric ID among the others, oldest video first, so newer videos have
+// higher IDs as on RTVE and the IDs only change if the fixture does. The
+// videos are copied; videos is left untouched.
+func NewFixtureSource(videos []FixtureVideo, pageSize int) *FixtureSource {
+	if pageSize <= 0 {
+		pageSize = defaultFixturePageSize
+	}
+
+	f := &FixtureSource{
+		videos:   make([]FixtureVideo, len(videos)),
+		byID:     make(map[string]int, len(videos)),
+		pageSize: pageSize,
+	}
+
+	next := 0
+	for i, v := range videos {
+		meta := &rtve.VideoMetadata{}
+		if v.Metadata != nil {
+			*meta = *v.Metadata
+		}
+		if id, err := strconv.Atoi(meta.ID); err == nil {
+			next = max(next, id)
+		}
+		f.videos[i] = FixtureVideo{Metadata: meta, Subtitles: v.Subtitles}
+	}
+
+	for i := len(f.videos) - 1; i >= 0; i-- {
+		meta := f.videos[i].Metadata
+		if meta.ID == "" {
+			next++
+			meta.ID = strconv.Itoa(next)
+		}
+		f.byID[meta.ID] = i
+	}
+
+	return f
+}
+
+// ScrapePageContext returns the videos on a listing page, or an error
+// wrapping rtve.ErrPageNotFound past the last one.
+func (f *FixtureSource) ScrapePageContext(ctx context.Context, page int) ([]*rtve.VideoInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	start := page * f.pageSize
+	if page < 0 || start >= len(f.videos) {
+		return nil, fmt.Errorf("fixture page %d: %w", page, rtve.ErrPageNotFound)
+	}
+
+	end := min(start+f.pageSize, len(f.videos))
+	videos := make([]*rtve.VideoInfo, 0, end-start)
+	for _, v := range f.videos[start:end] {
+		videos = append(videos, &rtve.VideoInfo{URL: v.Metadata.HTMLUrl, ID: v.Metadata.ID})
+	}
+	return videos, nil
+}
+
+// DownloadVideoMetaContext returns a copy of the metadata of a video, or
+// an error wrapping rtve.ErrMetadataNotFound if it isn't in the fixture.
+func (f *FixtureSource) DownloadVideoMetaContext(ctx context.Context, videoID string) (*rtve.VideoMetadata, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	i, ok := f.byID[videoID]
+	if !ok {
+		return nil, fmt.Errorf("fixture video %s: %w", videoID, rtve.ErrMetadataNotFound)
+	}
+
+	meta := *f.videos[i].Metadata
+	return &meta, nil
+}
+
+// FetchSubtitlesContext returns the subtitle tracks of a video.
+func (f *FixtureSource) FetchSubtitlesContext(ctx context.Context, meta *rtve.VideoMetadata) (*rtve.Subtitles, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	i, ok := f.byID[meta.ID]
+	if !ok {
+		return nil, &rtve.SubtitlesError{VideoID: meta.ID, StatusCode: http.StatusNotFound, Err: rtve.ErrPageNotFound}
+	}
+
+	return &rtve.Subtitles{
+		VideoID:   meta.ID,
+		Subtitles: append([]rtve.SubtitleItem(nil), f.videos[i].Subtitles...),
+	}, nil
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	rtve "github.com/rubiojr/rtve-go"
+)
+
+func TestFetchShowFixtureSource(t *testing.T) {
+	videos := []FixtureVideo{
+		{Metadata: &rtve.VideoMetadata{LongTitle: "Episode 4", PublicationDate: "04-10-2025 21:00:00"}, Subtitles: []rtve.SubtitleItem{{Lang: "es"}}},
+		{Metadata: &rtve.VideoMetadata{ID: "100", LongTitle: "Episode 3", PublicationDate: "03-10-2025 21:00:00"}},
+		{Metadata: &rtve.VideoMetadata{LongTitle: "Episode 2", PublicationDate: "02-10-2025 21:00:00"}},
+		{Metadata: &rtve.VideoMetadata{LongTitle: "Episode 1", PublicationDate: "01-10-2025 21:00:00"}},
+	}
+	src := NewFixtureSource(videos, 2)
+
+	if videos[0].Metadata.ID != "" {
+		t.Errorf("Fixture videos were modified: %+v", videos[0].Metadata)
+	}
+
+	var ids []string
+	visitor := func(result *VideoResult) error {
+		ids = append(ids, result.Metadata.ID)
+		return nil
+	}
+	// Not a registered show, the source decides what's in it
+	stats, err := FetchShowWithOptions("fixture", day(2), day(5), visitor, &FetchOptions{Source: src})
+	if err != nil {
+		t.Fatalf("FetchShowWithOptions failed: %v", err)
+	}
+
+	// IDs count up from the highest given one, oldest first
+	if want := []string{"103", "100", "102"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Visited %v, want %v", ids, want)
+	}
+	if stats.VideosProcessed != 3 || stats.VideosWithoutSubtitles != 2 || stats.ErrorCount != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// The same fixture always gets the same IDs
+	again := NewFixtureSource(videos, 2)
+	for _, id := range []string{"100", "101", "102", "103"} {
+		a, err := src.DownloadVideoMetaContext(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := again.DownloadVideoMetaContext(context.Background(), id)
+		if err != nil || a.LongTitle != b.LongTitle {
+			t.Errorf("Video %s differs between fixture sources: %+v, %+v (%v)", id, a, b, err)
+		}
+	}
+}
+
+func TestFixtureSource(t *testing.T) {
+	ctx := context.Background()
+	src := NewFixtureSource([]FixtureVideo{
+		{Metadata: &rtve.VideoMetadata{ID: "7"}},
+	}, 0)
+
+	videos, err := src.ScrapePageContext(ctx, 0)
+	if err != nil || len(videos) != 1 || videos[0].ID != "7" {
+		t.Errorf("Unexpected first page: %v, %v", videos, err)
+	}
+	if _, err := src.ScrapePageContext(ctx, 1); !errors.Is(err, rtve.ErrPageNotFound) {
+		t.Errorf("Expected ErrPageNotFound past the last page, got %v", err)
+	}
+
+	meta, err := src.DownloadVideoMetaContext(ctx, "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta.LongTitle = "Changed by the caller"
+	if meta, _ := src.DownloadVideoMetaContext(ctx, "7"); meta.LongTitle != "" {
+		t.Errorf("Fixture metadata was modified through a returned copy: %q", meta.LongTitle)
+	}
+
+	if _, err := src.DownloadVideoMetaContext(ctx, "8"); !errors.Is(err, rtve.ErrMetadataNotFound) {
+		t.Errorf("Expected ErrMetadataNotFound for an unknown video, got %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := src.ScrapePageContext(canceled, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFetchShowInvalidShowWithoutSource(t *testing.T) {
+	_, err := FetchShowWithOptions("fixture", day(1), day(2), func(*VideoResult) error { return nil }, nil)
+	if err == nil {
+		t.Error("Expected an error for an unregistered show")
+	}
+}
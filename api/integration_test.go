@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	rtve "github.com/rubiojr/rtve-go"
 )
 
 // Integration tests that make real HTTP calls to RTVE's API.
@@ -133,8 +135,7 @@ func TestIntegrationDateRangeFiltering(t *testing.T) {
 
 	visitor := func(result *VideoResult) error {
 		// Parse the publication date
-		const rtveLayout = "02-01-2006 15:04:05"
-		pubDate, err := time.Parse(rtveLayout, result.Metadata.PublicationDate)
+		pubDate, err := rtve.ParseRTVEDate(result.Metadata.PublicationDate)
 		if err != nil {
 			return fmt.Errorf("failed to parse publication date: %w", err)
 		}
@@ -335,7 +336,6 @@ func TestIntegrationFetchLatestChronologicalOrder(t *testing.T) {
 	// Test that FetchShowLatest returns videos in chronological order (most recent first)
 	// This is a regression test for the bug where videos were returned in webpage order
 	// rather than chronological order
-	const rtveLayout = "02-01-2006 15:04:05"
 
 	tests := []struct {
 		name      string
@@ -365,7 +365,7 @@ func TestIntegrationFetchLatestChronologicalOrder(t *testing.T) {
 				}
 
 				// Parse publication date
-				pubDate, err := time.Parse(rtveLayout, result.Metadata.PublicationDate)
+				pubDate, err := rtve.ParseRTVEDate(result.Metadata.PublicationDate)
 				if err != nil {
 					t.Errorf("Failed to parse publication date %s: %v", result.Metadata.PublicationDate, err)
 					return nil
@@ -414,3 +414,81 @@ func TestIntegrationFetchLatestChronologicalOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestIntegrationSubtitleLanguages(t *testing.T) {
+	var videoID string
+	_, err := FetchShowLatest("telediario-1", 1, func(result *VideoResult) error {
+		videoID = result.Metadata.ID
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if videoID == "" {
+		t.Skip("No video found to check subtitle languages")
+	}
+
+	langs, err := SubtitleLanguages(videoID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, lang := range langs {
+		if lang == "" {
+			t.Errorf("Empty language code for video %s", videoID)
+		}
+	}
+
+	t.Logf("Video %s has subtitle languages: %v", videoID, langs)
+}
+
+func TestIntegrationPageVisitor(t *testing.T) {
+	now := time.Now()
+	start := now.AddDate(0, 0, -2)
+	end := now.AddDate(0, 0, -1)
+
+	var pages []PageInfo
+	opts := &FetchOptions{
+		PageVisitor: func(info *PageInfo) error {
+			pages = append(pages, *info)
+			// Stop after the first page, we only want to check the callback
+			return ErrStopPaging
+		},
+	}
+
+	stats, err := FetchShowWithOptions("telediario-1", start, end, func(result *VideoResult) error {
+		return nil
+	}, opts)
+	if err != nil {
+		t.Fatalf("ErrStopPaging should not be reported as an error: %v", err)
+	}
+
+	if len(pages) != 1 {
+		t.Fatalf("Expected page visitor to be called once, got %d", len(pages))
+	}
+
+	if pages[0].Page != 0 {
+		t.Errorf("Expected first page to be 0, got %d", pages[0].Page)
+	}
+
+	if pages[0].InRange != stats.VideosProcessed {
+		t.Errorf("Expected InRange=%d to match VideosProcessed=%d", pages[0].InRange, stats.VideosProcessed)
+	}
+
+	t.Logf("Page %d: %d videos found, %d in range", pages[0].Page, pages[0].ItemsFound, pages[0].InRange)
+}
+
+func TestIntegrationSearchPrograms(t *testing.T) {
+	programs, err := SearchPrograms("telediario")
+	if err != nil {
+		t.Fatalf("SearchPrograms failed: %v", err)
+	}
+	if len(programs) == 0 {
+		t.Fatal("Expected programs matching \"telediario\"")
+	}
+	for _, p := range programs {
+		if p.ID == "" || p.Name == "" {
+			t.Errorf("Incomplete program %+v", p)
+		}
+	}
+}
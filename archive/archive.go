@@ -0,0 +1,203 @@
+// Package archive reads the archives written by the rtve package and the
+// rtve-subs command, without touching the network.
+//
+// Archives are organized by publication date (see rtve.VideoFolder), with
+// the metadata of each episode in video_<id>.json, its subtitles in a subs
+// folder next to it and, optionally, its video file:
+//
+//	rtve-videos/2025/2025-03-14/video_16492499.json
+//	rtve-videos/2025/2025-03-14/video_16492499.mp4 (or audio_16492499.m4a)
+//	rtve-videos/2025/2025-03-14/subs/16492499_es.vtt
+//	rtve-videos/2025/2025-03-14/subs/16492499_es.imported.srt
+//
+// Example usage:
+//
+//	for episode, err := range archive.Episodes("rtve-videos") {
+//		if err != nil {
+//			log.Println(err)
+//			continue
+//		}
+//		fmt.Println(episode.ID, episode.Folder, len(episode.Subtitles))
+//	}
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	rtve "github.com/rubiojr/rtve-go"
+)
+
+// Episode is a video stored in an archive.
+type Episode struct {
+	// ID is the RTVE video ID
+	ID string
+	// Folder is the day folder holding the episode's files
+	Folder string
+	// MetadataPath is the path of video_<id>.json
+	MetadataPath string
+	// VideoPath is the path of the video file, empty if it wasn't downloaded
+	VideoPath string
+	// AudioPath is the path of the audio file saved instead of the video
+	// in audio-only mode, empty if there's none
+	AudioPath string
+	// Subtitles lists the subtitle files of the episode, downloaded and
+	// imported, sorted by path
+	Subtitles []Subtitle
+	// Version is the version number of an episode RTVE re-published (see
+	// rtve.VersionsFile), zero for episodes published once
+	Version int
+	// SupersededBy is the ID of the current version of a re-published
+	// episode, empty if this is the current version
+	SupersededBy string
+}
+
+// Subtitle is a subtitle or transcript file stored with an episode.
+type Subtitle struct {
+	// Lang is the language code in the file name
+	Lang string
+	// Path is the path of the file
+	Path string
+	// Imported is true for files added with rtve.ImportFile rather than
+	// downloaded from RTVE
+	Imported bool
+}
+
+// Metadata reads and decodes the stored metadata of the episode.
+func (e *Episode) Metadata() (*rtve.VideoMetadata, error) {
+	data, err := os.ReadFile(e.MetadataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &rtve.VideoMetadata{}
+	if err := json.Unmarshal(data, meta); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", e.MetadataPath, err)
+	}
+	return meta, nil
+}
+
+// Episodes iterates over the episodes stored under root, in folder order,
+// which is chronological for both archive layouts. Folders that can't be
+// read are reported as errors and skipped; iteration continues with the
+// next folder unless the loop stops.
+func Episodes(root string) iter.Seq2[*Episode, error] {
+	return func(yield func(*Episode, error) bool) {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if !yield(nil, err) {
+					return filepath.SkipAll
+				}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			id, ok := metadataID(d.Name())
+			if !ok {
+				return nil
+			}
+
+			episode, err := loadEpisode(filepath.Dir(path), id)
+			if !yield(episode, err) {
+				return filepath.SkipAll
+			}
+			return nil
+		})
+	}
+}
+
+// Find returns the episode with the given video ID stored under root, or
+// nil if it hasn't been archived.
+func Find(root, videoID string) (*Episode, error) {
+	folder := rtve.FindVideoFolder(root, videoID)
+	if folder == "" {
+		return nil, nil
+	}
+	return loadEpisode(folder, videoID)
+}
+
+// metadataID returns the video ID of a video_<id>.json file name.
+func metadataID(name string) (string, bool) {
+	id, ok := strings.CutPrefix(name, "video_")
+	if !ok {
+		return "", false
+	}
+	id, ok = strings.CutSuffix(id, ".json")
+	return id, ok && id != ""
+}
+
+func loadEpisode(folder, id string) (*Episode, error) {
+	episode := &Episode{
+		ID:           id,
+		Folder:       folder,
+		MetadataPath: filepath.Join(folder, fmt.Sprintf("video_%s.json", id)),
+	}
+
+	for _, ext := range []string{".mp4", ".ts"} {
+		path := filepath.Join(folder, rtve.VideoFile(id, ext))
+		if _, err := os.Stat(path); err == nil {
+			episode.VideoPath = path
+			break
+		}
+	}
+
+	for _, ext := range []string{".m4a", ".mp3"} {
+		path := filepath.Join(folder, rtve.AudioFile(id, ext))
+		if _, err := os.Stat(path); err == nil {
+			episode.AudioPath = path
+			break
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(folder, "subs", id+"_*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if sub, ok := parseSubtitle(id, path); ok {
+			episode.Subtitles = append(episode.Subtitles, sub)
+		}
+	}
+
+	versions, err := rtve.ReadVersions(folder)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if n := v.Version(id); n > 0 {
+			episode.Version = n
+			if v.Current != id {
+				episode.SupersededBy = v.Current
+			}
+			break
+		}
+	}
+
+	return episode, nil
+}
+
+// parseSubtitle parses the name of a <id>_<lang>.vtt file downloaded from
+// RTVE or a <id>_<lang>.imported<ext> one.
+func parseSubtitle(id, path string) (Subtitle, bool) {
+	name := strings.TrimPrefix(filepath.Base(path), id+"_")
+
+	if lang, _, ok := strings.Cut(name, ".imported"); ok {
+		return Subtitle{Lang: lang, Path: path, Imported: true}, lang != ""
+	}
+
+	lang, ok := strings.CutSuffix(name, ".vtt")
+	if !ok || lang == "" || strings.Contains(lang, ".") {
+		return Subtitle{}, false
+	}
+	return Subtitle{Lang: lang, Path: path}, true
+}
@@ -0,0 +1,164 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestEpisodes(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"2025/2025-03-14/video_16492499.json":                    `{"id":"16492499","longTitle":"Telediario - 21 horas - 14/03/25"}`,
+		"2025/2025-03-14/video_16492499.mp4":                     "",
+		"2025/2025-03-14/imports.json":                           "[]",
+		"2025/2025-03-14/subs/16492499_es.vtt":                   "WEBVTT\n",
+		"2025/2025-03-14/subs/16492499_en.vtt":                   "WEBVTT\n",
+		"2025/2025-03-14/subs/16492499_es.imported.srt":          "",
+		"2025/2025-03-14/.video_16492500.json.tmp123":            "",
+		"2024/12/2024-12-31/video_16400000.json":                 `{"id":"16400000"}`,
+		"2024/12/2024-12-31/audio_16400000.m4a":                  "",
+		"2024/12/2024-12-31/video_16400000.ts.segments/00000.ts": "",
+	})
+
+	var episodes []*Episode
+	for episode, err := range Episodes(root) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		episodes = append(episodes, episode)
+	}
+
+	if len(episodes) != 2 {
+		t.Fatalf("Expected 2 episodes, got %d", len(episodes))
+	}
+
+	old := episodes[0]
+	if old.ID != "16400000" || old.Folder != filepath.Join(root, "2024", "12", "2024-12-31") {
+		t.Errorf("Unexpected first episode: %+v", old)
+	}
+	if old.VideoPath != "" || len(old.Subtitles) != 0 {
+		t.Errorf("Expected no video or subtitles, got %+v", old)
+	}
+	if old.AudioPath != filepath.Join(old.Folder, "audio_16400000.m4a") {
+		t.Errorf("AudioPath = %q", old.AudioPath)
+	}
+
+	e := episodes[1]
+	folder := filepath.Join(root, "2025", "2025-03-14")
+	if e.ID != "16492499" || e.MetadataPath != filepath.Join(folder, "video_16492499.json") {
+		t.Errorf("Unexpected episode: %+v", e)
+	}
+	if e.VideoPath != filepath.Join(folder, "video_16492499.mp4") {
+		t.Errorf("VideoPath = %q", e.VideoPath)
+	}
+
+	want := []Subtitle{
+		{Lang: "en", Path: filepath.Join(folder, "subs", "16492499_en.vtt")},
+		{Lang: "es", Path: filepath.Join(folder, "subs", "16492499_es.imported.srt"), Imported: true},
+		{Lang: "es", Path: filepath.Join(folder, "subs", "16492499_es.vtt")},
+	}
+	if !reflect.DeepEqual(e.Subtitles, want) {
+		t.Errorf("Subtitles = %+v, want %+v", e.Subtitles, want)
+	}
+
+	meta, err := e.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.LongTitle != "Telediario - 21 horas - 14/03/25" {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+}
+
+func TestEpisodesStop(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"2025/2025-03-13/video_1.json": "{}",
+		"2025/2025-03-14/video_2.json": "{}",
+	})
+
+	n := 0
+	for range Episodes(root) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("Expected iteration to stop after 1 episode, got %d", n)
+	}
+}
+
+func TestEpisodesMissingRoot(t *testing.T) {
+	var errs int
+	for episode, err := range Episodes(filepath.Join(t.TempDir(), "missing")) {
+		if err == nil || episode != nil {
+			t.Errorf("Expected only an error, got %v, %v", episode, err)
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Errorf("Expected 1 error, got %d", errs)
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"2025/2025-03-14/video_16492499.json":  "{}",
+		"2025/2025-03-14/subs/16492499_es.vtt": "WEBVTT\n",
+	})
+
+	e, err := Find(root, "16492499")
+	if err != nil || e == nil {
+		t.Fatalf("Find: %v, %v", e, err)
+	}
+	if len(e.Subtitles) != 1 || e.Subtitles[0].Lang != "es" {
+		t.Errorf("Unexpected subtitles: %+v", e.Subtitles)
+	}
+
+	if e, err := Find(root, "1"); e != nil || err != nil {
+		t.Errorf("Expected no episode, got %v, %v", e, err)
+	}
+}
+
+func TestEpisodesVersions(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"2025/2025-03-14/video_16492499.json": `{"id":"16492499"}`,
+		"2025/2025-03-14/video_16492530.json": `{"id":"16492530"}`,
+		"2025/2025-03-14/video_16492510.json": `{"id":"16492510"}`,
+		"2025/2025-03-14/versions.json":       `[{"title":"Telediario","ids":["16492499","16492530"],"current":"16492530"}]`,
+	})
+
+	got := map[string]Episode{}
+	for episode, err := range Episodes(root) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[episode.ID] = *episode
+	}
+
+	if e := got["16492499"]; e.Version != 1 || e.SupersededBy != "16492530" {
+		t.Errorf("Unexpected first version: %+v", e)
+	}
+	if e := got["16492530"]; e.Version != 2 || e.SupersededBy != "" {
+		t.Errorf("Unexpected current version: %+v", e)
+	}
+	if e := got["16492510"]; e.Version != 0 || e.SupersededBy != "" {
+		t.Errorf("Unexpected episode published once: %+v", e)
+	}
+}
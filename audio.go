@@ -0,0 +1,171 @@
+package rtve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// MediaType is the kind of media an RTVE program publishes.
+type MediaType string
+
+const (
+	// MediaVideo is for TV programs, listed under RTVE Play's videos
+	MediaVideo MediaType = "video"
+	// MediaAudio is for radio programs, e.g. "No es un día cualquiera",
+	// listed under RTVE Play's audios
+	MediaAudio MediaType = "audio"
+)
+
+// ParseMediaType parses a media type name, "video" or "audio". An empty
+// name is MediaVideo.
+func ParseMediaType(name string) (MediaType, error) {
+	switch MediaType(name) {
+	case "", MediaVideo:
+		return MediaVideo, nil
+	case MediaAudio:
+		return MediaAudio, nil
+	}
+	return "", fmt.Errorf("invalid media type %q: must be video or audio", name)
+}
+
+// AudioApiURL is RTVE's metadata endpoint for audios, the counterpart of
+// ApiURL for radio programs.
+const AudioApiURL = "https://api2.rtve.es/api/audios/%s.json"
+
+// AudioMediaURL is RTVE's ztnr endpoint for audios, which publishes their
+// media URLs like MediaURL does for videos.
+const AudioMediaURL = "https://www.rtve.es/ztnr/movil/thumbnail/rtveplayw/audios/%s.png?q=v2"
+
+// WithMediaType sets the media type of the programs scraped, for Scrappers
+// without a show, e.g. to retry audios by ID. Scrappers with a show use
+// the show's media type by default.
+func WithMediaType(m MediaType) Option {
+	return func(s *Scrapper) {
+		s.media = m
+	}
+}
+
+// WithAudioDownload also downloads the audio files of new episodes of radio
+// programs, see DownloadAudio, next to their metadata. It's the counterpart
+// of WithVideoDownload, which only downloads the media of TV programs.
+func WithAudioDownload(enabled bool) Option {
+	return func(s *Scrapper) {
+		s.downloadAudios = enabled
+	}
+}
+
+// downloadsMedia reports whether the media files of new episodes are
+// downloaded, as set with WithVideoDownload or WithAudioDownload for the
+// media type scraped, or with WithAudioOnly for both.
+func (s *Scrapper) downloadsMedia() bool {
+	if s.audioOnly != nil {
+		return true
+	}
+	if s.mediaType() == MediaAudio {
+		return s.downloadAudios
+	}
+	return s.downloadVideos
+}
+
+// mediaType returns the media type of the programs the Scrapper scrapes.
+func (s *Scrapper) mediaType() MediaType {
+	if s.media != "" {
+		return s.media
+	}
+	if show := s.currentShow(); show != nil && show.Media != "" {
+		return show.Media
+	}
+	return MediaVideo
+}
+
+// MediaType returns the media type of the video as told by its RTVE Play
+// or API URL, e.g. MediaAudio for https://www.rtve.es/play/audios/...
+// URLs, or "" if neither URL tells.
+func (m *VideoMetadata) MediaType() MediaType {
+	switch {
+	case strings.Contains(m.HTMLUrl, "/play/audios/"), strings.Contains(m.URI, "/api/audios/"):
+		return MediaAudio
+	case strings.Contains(m.HTMLUrl, "/play/videos/"), strings.Contains(m.URI, "/api/videos/"):
+		return MediaVideo
+	}
+	return ""
+}
+
+// ParseAudio parses the metadata of an audio, as returned by AudioApiURL.
+// Audios share the envelope and most fields of videos, but some programs
+// only give them a title, used as LongTitle then.
+func (m *VideoMetadata) ParseAudio(body string) error {
+	if err := m.Parse(body); err != nil {
+		return err
+	}
+
+	if m.LongTitle == "" {
+		var fields struct {
+			Title string `json:"title"`
+		}
+		if err := json.Unmarshal(m.Raw, &fields); err == nil {
+			m.LongTitle = fields.Title
+		}
+	}
+
+	return nil
+}
+
+// ResolveAudio returns the media sources RTVE publishes for an audio.
+func (s *Scrapper) ResolveAudio(audioID string) ([]MediaSource, error) {
+	return s.ResolveAudioContext(context.Background(), audioID)
+}
+
+// ResolveAudioContext works like ResolveAudio, aborting the request when
+// ctx is done.
+func (s *Scrapper) ResolveAudioContext(ctx context.Context, audioID string) ([]MediaSource, error) {
+	body, err := s.get(ctx, fmt.Sprintf(AudioMediaURL, audioID))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving media for audio %s: %w", audioID, err)
+	}
+
+	sources, err := DecodeMediaPNG([]byte(body))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving media for audio %s: %w", audioID, err)
+	}
+
+	return sources, nil
+}
+
+// DownloadAudio downloads the best quality audio file of an audio and
+// saves it to outputDir as audio_<id> with the file's extension, usually
+// .mp3, returning its path.
+func (s *Scrapper) DownloadAudio(meta *VideoMetadata, outputDir string) (string, error) {
+	return s.DownloadAudioContext(context.Background(), meta, outputDir)
+}
+
+// DownloadAudioContext works like DownloadAudio, stopping once ctx is done.
+func (s *Scrapper) DownloadAudioContext(ctx context.Context, meta *VideoMetadata, outputDir string) (string, error) {
+	if err := s.checkWritable(outputDir); err != nil {
+		return "", err
+	}
+
+	sources, err := s.ResolveAudioContext(ctx, meta.ID)
+	if err != nil {
+		return "", geoBlockedError(meta, err)
+	}
+
+	source, ok := bestMedia(sources, MediaSource.Progressive)
+	if !ok {
+		return "", fmt.Errorf("%w for audio %s", ErrNoMedia, meta.ID)
+	}
+
+	ext := mediaExt(source.URL)
+	if ext != ".m4a" {
+		ext = ".mp3"
+	}
+	path := filepath.Join(outputDir, AudioFile(meta.ID, ext))
+	if err := s.downloadMedia(ctx, source.URL, path); err != nil {
+		return "", geoBlockedError(meta, fmt.Errorf("error downloading %s audio for %s: %w", source.Quality, meta.ID, err))
+	}
+
+	return path, nil
+}
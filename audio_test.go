@@ -0,0 +1,138 @@
+package rtve
+
+import (
+	"encoding/base64"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMediaType(t *testing.T) {
+	for name, want := range map[string]MediaType{"": MediaVideo, "video": MediaVideo, "audio": MediaAudio} {
+		if got, err := ParseMediaType(name); err != nil || got != want {
+			t.Errorf("ParseMediaType(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseMediaType("podcast"); err == nil {
+		t.Error("Expected error for an unknown media type")
+	}
+}
+
+func TestVideoMetadataMediaType(t *testing.T) {
+	tests := []struct {
+		meta     VideoMetadata
+		expected MediaType
+	}{
+		{VideoMetadata{HTMLUrl: "https://www.rtve.es/play/audios/no-es-un-dia-cualquiera/bloque-1/16500001/"}, MediaAudio},
+		{VideoMetadata{URI: "https://api2.rtve.es/api/audios/16500001"}, MediaAudio},
+		{VideoMetadata{HTMLUrl: "https://www.rtve.es/play/videos/telediario-2/14-03-25/16492499/"}, MediaVideo},
+		{VideoMetadata{URI: "https://www.rtve.es/api/videos/16492499"}, MediaVideo},
+		{VideoMetadata{ID: "1"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := tt.meta.MediaType(); got != tt.expected {
+			t.Errorf("MediaType() of %+v = %q, expected %q", tt.meta, got, tt.expected)
+		}
+	}
+}
+
+func TestRegisterAudioShow(t *testing.T) {
+	t.Cleanup(func() {
+		showsMu.Lock()
+		delete(urlMap, "no-es-un-dia-cualquiera")
+		showsMu.Unlock()
+	})
+
+	if err := RegisterShow("no-es-un-dia-cualquiera", &Show{ID: "1000", Media: MediaAudio}); err != nil {
+		t.Fatal(err)
+	}
+	show := ShowMap("no-es-un-dia-cualquiera")
+	if show.URL != "https://www.rtve.es/play/audios/modulos/capitulos/1000/?page=%d" {
+		t.Errorf("Unexpected listing URL %s", show.URL)
+	}
+	if show.Regex != `https://www\.rtve\.es/play/audios/no-es-un-dia-cualquiera/[^/]+/[0-9]+/` {
+		t.Errorf("Unexpected episode URL pattern %s", show.Regex)
+	}
+
+	if err := RegisterShow("bad-media", &Show{ID: "1", Media: "podcast"}); err == nil {
+		t.Error("Expected error registering a show with an unknown media type")
+	}
+}
+
+func TestScrapeAudioShow(t *testing.T) {
+	t.Cleanup(func() {
+		showsMu.Lock()
+		delete(urlMap, "no-es-un-dia-cualquiera")
+		showsMu.Unlock()
+	})
+	if err := RegisterShow("no-es-un-dia-cualquiera", &Show{ID: "1000", Media: MediaAudio}); err != nil {
+		t.Fatal(err)
+	}
+
+	png := mediaPNG(MediaSource{Quality: "Alta", URL: "https://mediavod-lvlt.rtve.es/resources/16500001.mp3"})
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.String() {
+		case "https://www.rtve.es/play/audios/modulos/capitulos/1000/?page=0":
+			return newResponse(http.StatusOK, `<a href="https://www.rtve.es/play/audios/no-es-un-dia-cualquiera/bloque-1/16500001/">`), nil
+		case "https://api2.rtve.es/api/audios/16500001.json":
+			return newResponse(http.StatusOK, `{"page":{"items":[{"id":"16500001","title":"No es un día cualquiera - Bloque 1","publicationDate":"15-03-2025 08:00:00","htmlUrl":"https://www.rtve.es/play/audios/no-es-un-dia-cualquiera/bloque-1/16500001/"}]}}`), nil
+		case "https://www.rtve.es/ztnr/movil/thumbnail/rtveplayw/audios/16500001.png?q=v2":
+			return newResponse(http.StatusOK, base64.StdEncoding.EncodeToString(png)), nil
+		case "https://mediavod-lvlt.rtve.es/resources/16500001.mp3":
+			return newResponse(http.StatusOK, "audio data"), nil
+		}
+		if strings.Contains(req.URL.Path, "/videos/") {
+			t.Errorf("Unexpected video request for an audio show: %s", req.URL)
+		}
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	dir := t.TempDir()
+	s := NewScrapper("no-es-un-dia-cualquiera", WithOutputPath(dir), WithTransport(transport), WithAudioDownload(true))
+	downloaded, errs := s.Scrape(1)
+	if len(errs) > 0 {
+		t.Fatalf("Scrape failed: %v", errs)
+	}
+	if downloaded != 1 {
+		t.Fatalf("Expected 1 audio downloaded, got %d", downloaded)
+	}
+
+	folder := filepath.Join(dir, "2025", "2025-03-15")
+	meta := &VideoMetadata{}
+	data, err := os.ReadFile(filepath.Join(folder, "video_16500001.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := meta.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if meta.LongTitle != "No es un día cualquiera - Bloque 1" {
+		t.Errorf("Expected the title as LongTitle, got %q", meta.LongTitle)
+	}
+
+	audio, err := os.ReadFile(filepath.Join(folder, "audio_16500001.mp3"))
+	if err != nil || string(audio) != "audio data" {
+		t.Errorf("Unexpected audio file: %q, %v", audio, err)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "subs")); !os.IsNotExist(err) {
+		t.Errorf("Expected no subtitles folder for audios: %v", err)
+	}
+
+	// Archived audios aren't revisited for subtitles
+	if downloaded, errs := s.Scrape(1); downloaded != 0 || len(errs) > 0 {
+		t.Errorf("Expected nothing to do on a second run, got %d, %v", downloaded, errs)
+	}
+
+	// Downloading videos doesn't download audios
+	os.Remove(filepath.Join(folder, "audio_16500001.mp3"))
+	videos := NewScrapper("no-es-un-dia-cualquiera", WithOutputPath(dir), WithTransport(transport), WithVideoDownload(true))
+	if _, errs := videos.Scrape(1); len(errs) > 0 {
+		t.Fatalf("Scrape failed: %v", errs)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "audio_16500001.mp3")); !os.IsNotExist(err) {
+		t.Errorf("Expected no audio file without WithAudioDownload: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package rtve
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// httpCache stores responses to API and listing requests on disk, keyed by
+// URL, so later runs can revalidate them with conditional requests instead
+// of downloading them again.
+type httpCache struct {
+	dir string
+}
+
+// cacheEntry is a cached response, saved as a JSON file in the cache
+// directory.
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Body         string `json:"body"`
+}
+
+func (c *httpCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load returns the cached response for url, or nil if there's none. A nil
+// cache never has entries.
+func (c *httpCache) load(url string) *cacheEntry {
+	if c == nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return nil
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != url {
+		return nil
+	}
+
+	return &entry
+}
+
+// store saves body as the cached response for url. Responses without
+// validators can't be revalidated and aren't stored.
+func (c *httpCache) store(url string, header http.Header, body string) error {
+	if c == nil {
+		return nil
+	}
+
+	entry := cacheEntry{
+		URL:          url,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		Body:         body,
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	// Concurrent workers may store the same URL, the rename keeps readers
+	// from seeing partial entries
+	f, err := os.CreateTemp(c.dir, ".entry.tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, c.path(url)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+// setValidators adds the conditional request headers for entry to req.
+func (e *cacheEntry) setValidators(req *http.Request) {
+	if e == nil {
+		return
+	}
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
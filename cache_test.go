@@ -0,0 +1,70 @@
+package rtve
+
+import (
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestWithCacheDir(t *testing.T) {
+	dir := t.TempDir()
+	page := `<a href="https://www.rtve.es/play/videos/telediario-2/14-03-25/16492499/">`
+
+	var requests []*http.Request
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req)
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			return newResponse(http.StatusNotModified, ""), nil
+		}
+		resp := newResponse(http.StatusOK, page)
+		resp.Header = http.Header{"Etag": {`"v1"`}, "Last-Modified": {"Fri, 14 Mar 2025 21:00:00 GMT"}}
+		return resp, nil
+	})
+
+	// Each scraper stands for a separate run sharing the cache
+	for run := 0; run < 2; run++ {
+		s := NewScrapper("telediario-2", WithCacheDir(dir), WithTransport(transport))
+		links, err := s.ScrapePage(1)
+		if err != nil {
+			t.Fatalf("ScrapePage failed on run %d: %v", run, err)
+		}
+		if len(links) != 1 || links[0].ID != "16492499" {
+			t.Errorf("Unexpected links on run %d: %v", run, links)
+		}
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(requests))
+	}
+	if got := requests[0].Header.Get("If-None-Match"); got != "" {
+		t.Errorf("First request should not be conditional, got If-None-Match %q", got)
+	}
+	if got := requests[1].Header.Get("If-Modified-Since"); got != "Fri, 14 Mar 2025 21:00:00 GMT" {
+		t.Errorf("Expected If-Modified-Since to be sent, got %q", got)
+	}
+}
+
+func TestWithCacheDirWithoutValidators(t *testing.T) {
+	dir := t.TempDir()
+	s := NewScrapper("telediario-2", WithCacheDir(dir))
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+			t.Errorf("Unexpected conditional request")
+		}
+		return newResponse(http.StatusOK, ""), nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.ScrapePage(1); err != nil {
+			t.Fatalf("ScrapePage failed: %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Responses without validators should not be cached, found %d entries", len(entries))
+	}
+}
@@ -0,0 +1,72 @@
+package rtve
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// CanonicalURL returns the canonical RTVE Play URL of a video, the page to
+// link to when sharing it, e.g. in feeds or digests. The URL comes from the
+// video's metadata rather than being built from the video ID, since RTVE
+// may change its URL patterns.
+func CanonicalURL(videoID string) (string, error) {
+	return CanonicalURLContext(context.Background(), videoID)
+}
+
+// CanonicalURLContext works like CanonicalURL, aborting the request when
+// ctx is done.
+func CanonicalURLContext(ctx context.Context, videoID string) (string, error) {
+	return NewScrapper("").CanonicalURLContext(ctx, videoID)
+}
+
+// CanonicalURL returns the canonical RTVE Play URL of a video, see the
+// CanonicalURL function. Metadata already downloaded by the Scrapper is
+// reused.
+func (s *Scrapper) CanonicalURL(videoID string) (string, error) {
+	return s.CanonicalURLContext(context.Background(), videoID)
+}
+
+// CanonicalURLContext works like CanonicalURL, aborting the request when
+// ctx is done.
+func (s *Scrapper) CanonicalURLContext(ctx context.Context, videoID string) (string, error) {
+	if videoID == "" {
+		return "", fmt.Errorf("empty video ID")
+	}
+
+	meta, err := s.DownloadVideoMetaContext(ctx, videoID)
+	if err != nil {
+		return "", err
+	}
+	return meta.CanonicalURL()
+}
+
+// CanonicalURL returns the canonical form of the video's RTVE Play URL
+// (HTMLUrl): an https://www.rtve.es URL without query or fragment, ending
+// in a slash. It works offline, e.g. on archived metadata.
+func (m *VideoMetadata) CanonicalURL() (string, error) {
+	if m.HTMLUrl == "" {
+		return "", fmt.Errorf("video %s has no RTVE Play URL", m.ID)
+	}
+
+	u, err := url.Parse(m.HTMLUrl)
+	if err != nil {
+		return "", fmt.Errorf("invalid RTVE Play URL for video %s: %w", m.ID, err)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host != "rtve.es" && !strings.HasSuffix(host, ".rtve.es") {
+		return "", fmt.Errorf("invalid RTVE Play URL for video %s: %s", m.ID, m.HTMLUrl)
+	}
+	if host == "rtve.es" {
+		host = "www.rtve.es"
+	}
+
+	path := u.EscapedPath()
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+
+	return "https://" + host + path, nil
+}
@@ -0,0 +1,54 @@
+package rtve
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	s := NewScrapper("telediario-2", WithTransport(fixtureTransport(t)))
+
+	got, err := s.CanonicalURL("16492499")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://www.rtve.es/play/videos/telediario-2/14-03-25/16492499/"; got != want {
+		t.Errorf("CanonicalURL = %q, want %q", got, want)
+	}
+
+	if _, err := s.CanonicalURL("404"); !errors.Is(err, ErrMetadataNotFound) {
+		t.Errorf("Expected ErrMetadataNotFound for a missing video, got %v", err)
+	}
+	if _, err := s.CanonicalURL(""); err == nil {
+		t.Error("Expected an error for an empty video ID")
+	}
+}
+
+func TestVideoMetadataCanonicalURL(t *testing.T) {
+	tests := []struct {
+		htmlURL string
+		want    string
+		wantErr bool
+	}{
+		{htmlURL: "https://www.rtve.es/play/videos/telediario-2/14-03-25/16492499/", want: "https://www.rtve.es/play/videos/telediario-2/14-03-25/16492499/"},
+		{htmlURL: "http://rtve.es/play/videos/telediario-2/14-03-25/16492499?utm_source=x#t=10", want: "https://www.rtve.es/play/videos/telediario-2/14-03-25/16492499/"},
+		{htmlURL: "https://WWW.RTVE.ES/play/videos/la-noche-en-24h/a%C3%B1o/16492500/", want: "https://www.rtve.es/play/videos/la-noche-en-24h/a%C3%B1o/16492500/"},
+		{htmlURL: "", wantErr: true},
+		{htmlURL: "https://example.com/play/videos/telediario-2/16492499/", wantErr: true},
+		{htmlURL: "https://notrtve.es/16492499/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		m := &VideoMetadata{ID: "16492499", HTMLUrl: tt.htmlURL}
+		got, err := m.CanonicalURL()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("CanonicalURL(%q) = %q, expected an error", tt.htmlURL, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("CanonicalURL(%q) = %q, %v, want %q", tt.htmlURL, got, err, tt.want)
+		}
+	}
+}
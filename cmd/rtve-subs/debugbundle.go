@@ -0,0 +1,222 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/rubiojr/rtve-go"
+	"github.com/rubiojr/rtve-go/archive"
+	"github.com/urfave/cli/v2"
+)
+
+// debugBundleCommand gathers what's useful to diagnose a bug report into a
+// tarball. Everything is generated locally and nothing is sent anywhere.
+var debugBundleCommand = &cli.Command{
+	Name:   "debug-bundle",
+	Usage:  "Write build, environment and archive statistics to a tarball to attach to bug reports",
+	Action: writeDebugBundle,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Value:   "rtve-videos",
+			Usage:   "Archive directory to gather statistics about",
+		},
+		&cli.StringFlag{
+			Name:  "file",
+			Usage: "Tarball to write (default rtve-subs-debug-<timestamp>.tar.gz)",
+		},
+		&cli.StringFlag{
+			Name:  "corrections",
+			Usage: "Metadata corrections file to include",
+		},
+	},
+}
+
+// proxyVariables are the environment variables Go's HTTP client reads
+// proxies from. They may carry credentials, which are redacted.
+var proxyVariables = []string{"HTTP_PROXY", "HTTPS_PROXY", "ALL_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "all_proxy", "no_proxy"}
+
+type debugEnvironment struct {
+	OS      string            `json:"os"`
+	Arch    string            `json:"arch"`
+	CPUs    int               `json:"cpus"`
+	Proxies map[string]string `json:"proxies,omitempty"`
+	FFmpeg  bool              `json:"ffmpeg"`
+}
+
+type archiveStats struct {
+	Root                 string         `json:"root"`
+	Episodes             int            `json:"episodes"`
+	WithVideo            int            `json:"withVideo"`
+	WithAudio            int            `json:"withAudio"`
+	WithoutSubtitles     int            `json:"withoutSubtitles"`
+	InvalidMetadata      int            `json:"invalidMetadata"`
+	SubtitlesByLanguage  map[string]int `json:"subtitlesByLanguage"`
+	ImportedSubtitles    int            `json:"importedSubtitles"`
+	FirstFolder          string         `json:"firstFolder,omitempty"`
+	LastFolder           string         `json:"lastFolder,omitempty"`
+	LockFilePresent      bool           `json:"lockFilePresent"`
+	Errors               []string       `json:"errors,omitempty"`
+	ErrorsTruncatedAfter int            `json:"errorsTruncatedAfter,omitempty"`
+}
+
+// maxBundleErrors bounds the errors listed in the archive statistics.
+const maxBundleErrors = 50
+
+func writeDebugBundle(c *cli.Context) error {
+	path := c.String("file")
+	if path == "" {
+		path = fmt.Sprintf("rtve-subs-debug-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+
+	files := map[string]any{
+		"build.json":       rtve.ReadBuildInfo(),
+		"environment.json": collectEnvironment(),
+		"archive.json":     collectArchiveStats(c.String("output")),
+	}
+	if corrections := c.String("corrections"); corrections != "" {
+		data, err := rtve.LoadCorrections(corrections)
+		if err != nil {
+			return err
+		}
+		files["corrections.json"] = data
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		data, err := json.MarshalIndent(files[name], "", "  ")
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %s, review it before attaching it to a bug report\n", path)
+	return nil
+}
+
+func collectEnvironment() debugEnvironment {
+	env := debugEnvironment{
+		OS:     runtime.GOOS,
+		Arch:   runtime.GOARCH,
+		CPUs:   runtime.NumCPU(),
+		FFmpeg: (&rtve.FFmpeg{}).Available(),
+	}
+
+	for _, name := range proxyVariables {
+		if value := os.Getenv(name); value != "" {
+			if env.Proxies == nil {
+				env.Proxies = make(map[string]string)
+			}
+			env.Proxies[name] = redactURL(value)
+		}
+	}
+
+	return env
+}
+
+// redactURL hides the password of a URL. Values that don't parse as URLs
+// are hidden entirely, they could be anything.
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil {
+		return "REDACTED"
+	}
+	return u.Redacted()
+}
+
+func collectArchiveStats(root string) archiveStats {
+	stats := archiveStats{Root: root, SubtitlesByLanguage: make(map[string]int)}
+
+	if _, err := os.Stat(filepath.Join(root, rtve.ArchiveLockFile)); err == nil {
+		stats.LockFilePresent = true
+	}
+
+	addError := func(err error) {
+		if len(stats.Errors) < maxBundleErrors {
+			stats.Errors = append(stats.Errors, err.Error())
+		} else {
+			stats.ErrorsTruncatedAfter = maxBundleErrors
+		}
+	}
+
+	for episode, err := range archive.Episodes(root) {
+		if err != nil {
+			addError(err)
+			continue
+		}
+
+		stats.Episodes++
+		if stats.FirstFolder == "" {
+			stats.FirstFolder = episode.Folder
+		}
+		stats.LastFolder = episode.Folder
+
+		if episode.VideoPath != "" {
+			stats.WithVideo++
+		}
+		if episode.AudioPath != "" {
+			stats.WithAudio++
+		}
+		if len(episode.Subtitles) == 0 {
+			stats.WithoutSubtitles++
+		}
+		for _, sub := range episode.Subtitles {
+			if sub.Imported {
+				stats.ImportedSubtitles++
+			} else {
+				stats.SubtitlesByLanguage[sub.Lang]++
+			}
+		}
+
+		meta, err := episode.Metadata()
+		if err == nil {
+			err = meta.Validate()
+		}
+		if err != nil {
+			stats.InvalidMetadata++
+			addError(err)
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/rubiojr/rtve-go"
+	"github.com/urfave/cli/v2"
+)
+
+// fixturesCommand refreshes the files in fixtures/ from live RTVE responses.
+// It's meant for maintainers, so it's hidden from the help output.
+var fixturesCommand = &cli.Command{
+	Name:   "fixtures",
+	Usage:  "Maintain the offline test fixtures",
+	Hidden: true,
+	Subcommands: []*cli.Command{
+		{
+			Name:   "record",
+			Usage:  "Refresh show.html, video.json and subtitulos.json from live RTVE",
+			Action: recordFixtures,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "dir",
+					Value: "fixtures",
+					Usage: "Fixtures directory",
+				},
+				&cli.StringFlag{
+					Name:  "show",
+					Value: "telediario-2",
+					Usage: "Show whose first listing page is recorded",
+				},
+				&cli.StringFlag{
+					Name:  "video",
+					Value: "16492499",
+					Usage: "Video whose metadata and subtitle listing are recorded (the unit tests expect the default)",
+				},
+			},
+		},
+	},
+}
+
+var (
+	// Scripts, styles and comments aren't used by the scraper and can
+	// carry session tokens or tracking IDs.
+	htmlNoise = regexp.MustCompile(`(?is)<script\b.*?</script>|<style\b.*?</style>|<!--.*?-->`)
+
+	// Visit counters change on every request and only add noise to diffs.
+	volatileCounters = regexp.MustCompile(`"(popularity|popHistoric|numVisits)":"[^"]*"`)
+)
+
+func recordFixtures(c *cli.Context) error {
+	dir := c.String("dir")
+	show := rtve.ShowMap(c.String("show"))
+	if show == nil {
+		return fmt.Errorf("unsupported show: %s", c.String("show"))
+	}
+	videoID := c.String("video")
+
+	fixtures := []struct {
+		file     string
+		url      string
+		sanitize func([]byte) []byte
+	}{
+		{"show.html", fmt.Sprintf(show.URL, 0), sanitizeHTML},
+		{"video.json", fmt.Sprintf(rtve.ApiURL, videoID), sanitizeJSON},
+		{"subtitulos.json", fmt.Sprintf(rtve.SubsURL, videoID), sanitizeJSON},
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	for _, f := range fixtures {
+		body, err := fetchFixture(c, client, f.url)
+		if err != nil {
+			return fmt.Errorf("recording %s: %w", f.file, err)
+		}
+
+		path := filepath.Join(dir, f.file)
+		if err := os.WriteFile(path, f.sanitize(body), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		fmt.Printf("Recorded %s from %s\n", path, f.url)
+	}
+
+	fmt.Println("Run go test ./... and update tests that assert on recorded data")
+
+	return nil
+}
+
+func fetchFixture(c *cli.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(c.Context, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", rtve.DefaultUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func sanitizeHTML(body []byte) []byte {
+	return htmlNoise.ReplaceAll(body, nil)
+}
+
+func sanitizeJSON(body []byte) []byte {
+	return volatileCounters.ReplaceAllFunc(body, func(m []byte) []byte {
+		name := volatileCounters.FindSubmatch(m)[1]
+		if string(name) == "numVisits" {
+			return []byte(`"numVisits":"0"`)
+		}
+		return []byte(fmt.Sprintf(`"%s":"0.0"`, name))
+	})
+}
@@ -1,70 +1,121 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"slices"
 
 	"github.com/rubiojr/rtve-go"
 	"github.com/rubiojr/rtve-go/api"
+	"github.com/rubiojr/rtve-go/archive"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
+	// -v is --verbose in every command
+	cli.VersionFlag = &cli.BoolFlag{Name: "version", Usage: "Print the version, commit and build date"}
+
 	app := &cli.App{
-		Name:  "rtve-scraper",
-		Usage: "Download videos and subtitles from RTVE",
+		Name:    "rtve-scraper",
+		Usage:   "Download videos and subtitles from RTVE",
+		Version: rtve.ReadBuildInfo().String(),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "shows",
+				Usage: "JSON file with additional show definitions (default: rtve-go/shows.json in the user config directory, if it exists)",
+			},
+			// For resilience testing only, see rtve.ParseFaults
+			&cli.StringFlag{
+				Name:   "inject-faults",
+				Usage:  "Inject request faults, e.g. fail=0.1,status=503,slow=0.2,delay=2s,seed=42",
+				Hidden: true,
+			},
+		},
+		Before: loadShows,
 		Commands: []*cli.Command{
 			{
 				Name:   "fetch",
 				Usage:  "Download videos from RTVE",
 				Action: runScraper,
-				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:    "output",
-						Aliases: []string{"o"},
-						Value:   "rtve-videos",
-						Usage:   "Output directory for downloaded content",
-					},
-					&cli.StringFlag{
-						Name:     "show",
-						Aliases:  []string{"p"},
-						Required: true,
-						Usage:    "Show to scrape",
-					},
-					&cli.IntFlag{
-						Name:    "max-pages",
-						Aliases: []string{"m"},
-						Value:   0,
-						Usage:   "Maximum number of pages to scrape (0 = unlimited)",
-					},
-					&cli.BoolFlag{
-						Name:    "verbose",
-						Aliases: []string{"v"},
-						Value:   false,
-						Usage:   "Enable verbose output",
+				Flags: flags(
+					[]cli.Flag{
+						outputFlag,
+						&cli.StringFlag{
+							Name:    "show",
+							Aliases: []string{"p"},
+							Usage:   "Show to scrape",
+						},
+						&cli.StringFlag{
+							Name:  "program-id",
+							Usage: "Numeric RTVE program ID to scrape instead of a show, e.g. 135930",
+						},
+						&cli.IntFlag{
+							Name:    "max-pages",
+							Aliases: []string{"m"},
+							Value:   0,
+							Usage:   "Maximum number of pages to scrape (0 = unlimited)",
+						},
+						&cli.IntFlag{
+							Name:  "page-start",
+							Value: 0,
+							Usage: "First listing page to scrape",
+						},
+						&cli.IntFlag{
+							Name:  "page-end",
+							Value: rtve.NoPageLimit,
+							Usage: "Last listing page to scrape (-1 = use --max-pages)",
+						},
+						&cli.IntFlag{
+							Name:  "page-size",
+							Usage: "Videos to request per listing page, where RTVE supports it (0 = RTVE's default)",
+						},
+						&cli.DurationFlag{
+							Name:  "min-duration",
+							Usage: "Skip videos shorter than this (e.g. 5m, 0 = no limit)",
+						},
+						&cli.DurationFlag{
+							Name:  "max-duration",
+							Usage: "Skip videos longer than this (e.g. 2h, 0 = no limit)",
+						},
+						&cli.DurationFlag{
+							Name:  "time-budget",
+							Usage: "Stop cleanly after this much time (e.g. 2h, 0 = no limit)",
+						},
+						&cli.BoolFlag{
+							Name:  "resume",
+							Usage: "Start from the page an earlier run ran out of --time-budget on, and save the page to resume from when this one does",
+						},
+						&cli.StringFlag{
+							Name:  "corrections",
+							Usage: "JSON file with metadata corrections (video ID -> overridden fields)",
+						},
+						&cli.StringFlag{
+							Name:  "fsync",
+							Value: "off",
+							Usage: "Sync written files to disk: off, critical (metadata) or all",
+						},
 					},
-				},
+					downloadFlags,
+					networkFlags,
+					[]cli.Flag{verboseFlag},
+				),
 			},
 			{
 				Name:   "fetch-latest",
 				Usage:  "Fetch the latest available video(s) from RTVE",
 				Action: fetchLatest,
 				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:    "output",
-						Aliases: []string{"o"},
-						Value:   "rtve-videos",
-						Usage:   "Output directory for downloaded content",
-					},
+					outputFlag,
 					&cli.StringFlag{
 						Name:    "show",
 						Aliases: []string{"s"},
@@ -77,11 +128,12 @@ func main() {
 						Usage:   "Number of latest videos to fetch per show",
 					},
 					&cli.BoolFlag{
-						Name:    "verbose",
-						Aliases: []string{"v"},
-						Value:   false,
-						Usage:   "Enable verbose output",
+						Name:  "month-shards",
+						Usage: "Save videos to YYYY/MM/YYYY-MM-DD folders",
 					},
+					layoutFlag,
+					imagesFlag,
+					verboseFlag,
 				},
 			},
 			{
@@ -89,10 +141,136 @@ func main() {
 				Usage:  "List available shows that can be downloaded",
 				Action: listShows,
 			},
+			{
+				Name:      "search",
+				Usage:     "Search RTVE programs by name, to add them to the shows file",
+				ArgsUsage: "<query...>",
+				Action:    searchPrograms,
+			},
+			{
+				Name:      "retry",
+				Usage:     "Re-attempt downloading metadata and subtitles for specific videos",
+				ArgsUsage: "<id...>",
+				Action:    retryVideos,
+				Flags: flags(
+					[]cli.Flag{
+						outputFlag,
+						&cli.StringFlag{
+							Name:  "media",
+							Value: "video",
+							Usage: "Media type of the IDs: video or audio (radio programs)",
+						},
+					},
+					downloadFlags,
+					networkFlags,
+					[]cli.Flag{verboseFlag},
+				),
+			},
+			{
+				Name:      "import",
+				Usage:     "Attach an external subtitle or transcript file to a downloaded video",
+				ArgsUsage: "<id> <file>",
+				Action:    importFile,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Value:   "rtve-videos",
+						Usage:   "Output directory the video was downloaded to",
+					},
+					&cli.StringFlag{
+						Name:    "lang",
+						Aliases: []string{"l"},
+						Usage:   "Language code of the imported file, e.g. es or es-ES",
+					},
+					&cli.StringFlag{
+						Name:  "source",
+						Usage: "Where the file comes from (e.g. \"manual correction\")",
+					},
+				},
+			},
+			fixturesCommand,
+			debugBundleCommand,
+			wordFrequencyCommand,
+			{
+				Name:   "migrate-layout",
+				Usage:  "Move day folders of an archive to or from the month sharded layout",
+				Action: migrateLayout,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Value:   "rtve-videos",
+						Usage:   "Archive directory to migrate",
+					},
+					&cli.BoolFlag{
+						Name:  "month-shards",
+						Value: true,
+						Usage: "Migrate to YYYY/MM/YYYY-MM-DD folders (--month-shards=false migrates back)",
+					},
+				},
+			},
+			{
+				Name:   "refresh-metadata",
+				Usage:  "Download the metadata of archived episodes again, logging what RTVE changed",
+				Action: refreshMetadata,
+				Flags: flags(
+					[]cli.Flag{
+						&cli.StringFlag{
+							Name:    "output",
+							Aliases: []string{"o"},
+							Value:   "rtve-videos",
+							Usage:   "Archive directory to refresh",
+						},
+						&cli.DurationFlag{
+							Name:  "older-than",
+							Usage: "Only refresh episodes whose metadata was fetched or refreshed longer ago than this, e.g. 720h",
+						},
+						&cli.BoolFlag{
+							Name:  "missing-fields",
+							Usage: "Only refresh episodes with missing or malformed metadata fields",
+						},
+						&cli.StringFlag{
+							Name:  "corrections",
+							Usage: "JSON file with metadata corrections to apply",
+						},
+					},
+					networkFlags,
+					[]cli.Flag{verboseFlag},
+				),
+			},
+			{
+				Name:      "transcript",
+				Usage:     "Export subtitle files as plain text transcripts, without timestamps or markup",
+				ArgsUsage: "<file.vtt...>",
+				Action:    exportTranscript,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "save",
+						Usage: "Save each transcript next to its subtitle file, as .txt, instead of printing it",
+					},
+				},
+			},
+			{
+				Name:      "sentences",
+				Usage:     "Export a subtitle file as full sentences with start/end timestamps",
+				ArgsUsage: "<file.vtt>",
+				Action:    exportSentences,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Output JSON instead of plain text",
+					},
+				},
+			},
 		},
 	}
 
-	err := app.Run(os.Args)
+	// Stop cleanly on Ctrl-C, keeping whatever was downloaded so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := app.RunContext(ctx, os.Args)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -101,38 +279,86 @@ func main() {
 func runScraper(c *cli.Context) error {
 	outputPath := c.String("output")
 	show := c.String("show")
+	programID := c.String("program-id")
 	maxPages := c.Int("max-pages")
+	pageStart := c.Int("page-start")
+	pageEnd := c.Int("page-end")
 	verbose := c.Bool("verbose")
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(outputPath, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %v", err)
+	if (show == "") == (programID == "") {
+		return fmt.Errorf("either --show or --program-id is required")
+	}
+	if programID != "" {
+		if _, err := rtve.ProgramShow(programID); err != nil {
+			return err
+		}
+	}
+
+	if pageEnd == rtve.NoPageLimit && maxPages > 0 {
+		pageEnd = maxPages
+	}
+	if pageEnd != rtve.NoPageLimit && pageEnd < pageStart {
+		return fmt.Errorf("--page-end (%d) is before --page-start (%d)", pageEnd, pageStart)
+	}
+
+	// Read-only runs can't create the output directory or its lock file
+	readOnly := c.Bool("read-only")
+	if !readOnly {
+		// Create output directory if it doesn't exist
+		if err := os.MkdirAll(outputPath, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %v", err)
+		}
+
+		lock, err := rtve.LockArchive(outputPath, false)
+		if err != nil {
+			return err
+		}
+		defer lock.Unlock()
 	}
 
 	fmt.Printf("Starting RTVE scraper\n")
+	fmt.Printf("Version: %s\n", rtve.ReadBuildInfo())
 	fmt.Printf("Output directory: %s\n", outputPath)
-	fmt.Printf("Show: %s\n", show)
-	if maxPages == 0 {
-		fmt.Printf("Max pages: unlimited\n")
+	if programID != "" {
+		fmt.Printf("Program ID: %s\n", programID)
+	} else {
+		fmt.Printf("Show: %s\n", show)
+	}
+	if pageEnd == rtve.NoPageLimit {
+		fmt.Printf("Pages: %d-last\n", pageStart)
 	} else {
-		fmt.Printf("Max pages: %d\n", maxPages)
+		fmt.Printf("Pages: %d-%d\n", pageStart, pageEnd)
 	}
 
-	shows := rtve.ListShows()
-	if !slices.Contains(shows, show) {
+	if show != "" && !slices.Contains(rtve.ListShows(), show) {
 		return fmt.Errorf("unsupported show: %s", show)
 	}
 
-	// Create the scraper with the provided options
-	scrapper := rtve.NewScrapper(
-		show,
-		rtve.WithOutputPath(outputPath),
-		rtve.WithVerbose(verbose),
-	)
+	options, err := scraperOptions(c)
+	if err != nil {
+		return err
+	}
+	scrapper := rtve.NewScrapper(show, options...)
+	if programID != "" {
+		// The ID was validated above
+		scrapper, _ = rtve.NewScrapperByID(programID, options...)
+	}
+
+	resume := c.Bool("resume")
+	if resume && !c.IsSet("page-start") {
+		state, err := rtve.ReadResumeState(outputPath, scrapper.Program)
+		if err != nil {
+			return err
+		}
+		if state != nil && (pageEnd == rtve.NoPageLimit || state.Page <= pageEnd) {
+			pageStart = state.Page
+			fmt.Printf("Resuming from page %d, where the run of %s stopped\n", pageStart, state.SavedAt.Local().Format(time.DateTime))
+		}
+	}
 
 	// Start scraping
 	startTime := time.Now()
-	videosDownloaded, errs := scrapper.Scrape(maxPages)
+	videosDownloaded, errs := scrapper.ScrapeRangeContext(c.Context, pageStart, pageEnd)
 
 	if verbose {
 		for _, err := range errs {
@@ -140,6 +366,26 @@ func runScraper(c *cli.Context) error {
 		}
 	}
 
+	var budgetErr *rtve.TimeBudgetError
+	for _, err := range errs {
+		if errors.As(err, &budgetErr) {
+			break
+		}
+	}
+	switch {
+	case budgetErr != nil && resume && !readOnly:
+		if err := scrapper.SaveResumePage(budgetErr.Page); err != nil {
+			return err
+		}
+		fmt.Printf("\nTime budget exhausted, the next run with --resume starts from page %d\n", budgetErr.Page)
+	case budgetErr != nil:
+		fmt.Printf("\nTime budget exhausted, resume with --page-start %d\n", budgetErr.Page)
+	case resume && !readOnly && c.Context.Err() == nil:
+		if err := scrapper.ClearResumePage(); err != nil {
+			return err
+		}
+	}
+
 	duration := time.Since(startTime)
 	fmt.Printf("\nScraping completed in %s\n", duration)
 	fmt.Printf("Downloaded %d videos\n", videosDownloaded)
@@ -158,6 +404,12 @@ func fetchLatest(c *cli.Context) error {
 		return fmt.Errorf("failed to create output directory: %v", err)
 	}
 
+	lock, err := rtve.LockArchive(outputPath, false)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
 	fmt.Printf("Fetching latest videos from RTVE\n")
 	fmt.Printf("Output directory: %s\n", outputPath)
 
@@ -177,6 +429,9 @@ func fetchLatest(c *cli.Context) error {
 	}
 	fmt.Printf("Count per show: %d\n\n", count)
 
+	// Only used to save subtitles, fetching is done by the api package
+	scrapper := rtve.NewScrapper("", rtve.WithOutputPath(outputPath), rtve.WithVerbose(verbose))
+
 	totalVideos := 0
 	totalErrors := 0
 
@@ -191,7 +446,7 @@ func fetchLatest(c *cli.Context) error {
 			showVideos++
 
 			// Create folder structure based on publication date
-			folder, err := createFolderForVideo(result.Metadata, outputPath)
+			folder, err := createFolderForVideo(result.Metadata, outputPath, c.Bool("month-shards"), c.String("layout"))
 			if err != nil {
 				if verbose {
 					fmt.Printf("Error creating folder for %s: %v\n", result.Metadata.ID, err)
@@ -208,9 +463,10 @@ func fetchLatest(c *cli.Context) error {
 				return nil // Continue processing
 			}
 
-			// Save subtitles if available
+			// Save subtitles if available, converted to UTF-8 WebVTT like
+			// every other command does
 			if result.Subtitles != nil {
-				if err := saveSubtitles(result.Subtitles, folder); err != nil {
+				if _, err := scrapper.SaveSubtitlesContext(c.Context, result.Metadata, result.Subtitles, folder); err != nil {
 					if verbose {
 						fmt.Printf("Error saving subtitles for %s: %v\n", result.Metadata.ID, err)
 					}
@@ -218,6 +474,15 @@ func fetchLatest(c *cli.Context) error {
 				}
 			}
 
+			if c.Bool("images") {
+				if _, err := scrapper.DownloadImagesContext(c.Context, result.Metadata, folder); err != nil {
+					if verbose {
+						fmt.Printf("Error downloading images for %s: %v\n", result.Metadata.ID, err)
+					}
+					totalErrors++
+				}
+			}
+
 			// Set folder modification time
 			if err := updateFolderTime(result.Metadata, folder); err != nil {
 				if verbose {
@@ -233,7 +498,11 @@ func fetchLatest(c *cli.Context) error {
 			return nil
 		}
 
-		stats, err := api.FetchShowLatest(showID, count, visitor)
+		stats, err := api.FetchShowLatestContext(c.Context, showID, count, visitor)
+		if errors.Is(err, context.Canceled) {
+			fmt.Println("Interrupted")
+			break
+		}
 		if err != nil {
 			fmt.Printf("Error fetching %s: %v\n", showID, err)
 			totalErrors++
@@ -260,14 +529,20 @@ func fetchLatest(c *cli.Context) error {
 	return nil
 }
 
-func createFolderForVideo(meta *rtve.VideoMetadata, basePath string) (string, error) {
-	layout := "02-01-2006 15:04:05"
-	pubDate, err := time.Parse(layout, meta.PublicationDate)
+func createFolderForVideo(meta *rtve.VideoMetadata, basePath string, monthShards bool, layout string) (string, error) {
+	pubDate, err := rtve.ParseRTVEDate(meta.PublicationDate)
 	if err != nil {
 		return "", fmt.Errorf("parsing publication date: %w", err)
 	}
 
-	folder := filepath.Join(basePath, pubDate.Format("2006"), pubDate.Format("2006-01-02"))
+	folder := rtve.VideoFolder(basePath, pubDate, monthShards)
+	if layout != "" {
+		rel, err := rtve.FormatLayout(layout, meta)
+		if err != nil {
+			return "", err
+		}
+		folder = filepath.Join(basePath, rel)
+	}
 	if err := os.MkdirAll(folder, 0755); err != nil {
 		return "", fmt.Errorf("creating folder: %w", err)
 	}
@@ -289,48 +564,281 @@ func saveVideoMetadata(meta *rtve.VideoMetadata, folder string) error {
 	return nil
 }
 
-func saveSubtitles(subs *rtve.Subtitles, folder string) error {
-	subsDir := filepath.Join(folder, "subs")
-	if err := os.MkdirAll(subsDir, 0755); err != nil {
-		return fmt.Errorf("creating subs directory: %w", err)
+func updateFolderTime(meta *rtve.VideoMetadata, folder string) error {
+	pubDate, err := rtve.ParseRTVEDate(meta.PublicationDate)
+	if err != nil {
+		return fmt.Errorf("parsing publication date: %w", err)
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	return os.Chtimes(folder, pubDate, pubDate)
+}
 
-	for _, sub := range subs.Subtitles {
-		// Download subtitle content
-		resp, err := client.Get(sub.Src)
+// ffmpegPostProcessor returns the ffmpeg post-processing requested with
+// command line flags, or nil if there's none.
+func ffmpegPostProcessor(c *cli.Context) (*rtve.FFmpeg, error) {
+	ffmpeg := &rtve.FFmpeg{
+		Remux:          c.Bool("remux"),
+		VideoCodec:     c.String("video-codec"),
+		EmbedSubtitles: c.Bool("embed-subs"),
+		SRT:            c.Bool("srt"),
+		SubtitleOffset: c.Duration("subtitle-offset"),
+		DetectOffset:   c.Bool("detect-offset"),
+	}
+	if c.Bool("audio-only") {
+		ffmpeg.Audio = c.String("audio-format")
+		if ffmpeg.Audio != "m4a" && ffmpeg.Audio != "mp3" {
+			return nil, fmt.Errorf("unsupported audio format %q, use m4a or mp3", ffmpeg.Audio)
+		}
+	}
+	needsFFmpeg := ffmpeg.Remux || ffmpeg.VideoCodec != "" || ffmpeg.EmbedSubtitles || ffmpeg.Audio != "" || ffmpeg.DetectOffset
+	if !needsFFmpeg && !ffmpeg.SRT {
+		return nil, nil
+	}
+
+	if needsFFmpeg && !ffmpeg.Available() {
+		return nil, fmt.Errorf("ffmpeg not found, it's needed by --remux, --video-codec, --embed-subs, --audio-only and --detect-offset")
+	}
+	return ffmpeg, nil
+}
+
+// loadShows registers the shows defined in the --shows file, or in the
+// default shows file if there's one.
+func loadShows(c *cli.Context) error {
+	path := c.String("shows")
+	if path == "" {
+		var err error
+		if path, err = rtve.DefaultShowsFile(); err != nil {
+			return nil
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil
+		}
+	}
+
+	_, err := rtve.LoadShows(path)
+	return err
+}
+
+func retryVideos(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return fmt.Errorf("usage: %s retry <id...>", c.App.Name)
+	}
+
+	options, err := scraperOptions(c)
+	if err != nil {
+		return err
+	}
+
+	outputPath := c.String("output")
+	readOnly := c.Bool("read-only")
+	if !readOnly {
+		if err := os.MkdirAll(outputPath, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %v", err)
+		}
+
+		lock, err := rtve.LockArchive(outputPath, false)
 		if err != nil {
-			return fmt.Errorf("downloading subtitle %s: %w", sub.Lang, err)
+			return err
 		}
-		defer resp.Body.Close()
+		defer lock.Unlock()
+	}
+
+	scrapper := rtve.NewScrapper("", options...)
+
+	videosDownloaded, errs := scrapper.ScrapeVideosContext(c.Context, c.Args().Slice())
+	for _, err := range errs {
+		fmt.Printf("Error: %v\n", err)
+	}
+
+	fmt.Printf("Downloaded %d videos\n", videosDownloaded)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d error(s) while retrying", len(errs))
+	}
+
+	return nil
+}
+
+func importFile(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("usage: %s import <id> <file>", c.App.Name)
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("subtitle download for %s returned status %d", sub.Lang, resp.StatusCode)
+	lock, err := rtve.LockArchive(c.String("output"), false)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	record, err := rtve.ImportFile(c.String("output"), c.Args().Get(0), c.Args().Get(1), c.String("lang"), c.String("source"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %s as %s\n", record.OriginalName, record.File)
+
+	return nil
+}
+
+func migrateLayout(c *cli.Context) error {
+	// Moving folders under a running fetch would lose videos
+	lock, err := rtve.LockArchive(c.String("output"), true)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	moved, err := rtve.MigrateLayout(c.String("output"), c.Bool("month-shards"))
+	fmt.Printf("Moved %d day folder(s)\n", moved)
+	return err
+}
+
+func refreshMetadata(c *cli.Context) error {
+	outputPath := c.String("output")
+	lock, err := rtve.LockArchive(outputPath, false)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	options, err := scraperOptions(c)
+	if err != nil {
+		return err
+	}
+	scrapper := rtve.NewScrapper("", options...)
+
+	olderThan := c.Duration("older-than")
+	refreshed, changed := 0, 0
+	var errs []error
+	for episode, err := range archive.Episodes(outputPath) {
+		if c.Context.Err() != nil {
+			break
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
 		}
 
-		content, err := io.ReadAll(resp.Body)
+		if olderThan > 0 {
+			info, err := os.Stat(episode.MetadataPath)
+			if err == nil && time.Since(info.ModTime()) < olderThan {
+				continue
+			}
+		}
+		if c.Bool("missing-fields") {
+			meta, err := episode.Metadata()
+			if err == nil && meta.Validate() == nil {
+				continue
+			}
+		}
+
+		changes, err := scrapper.RefreshMetadataContext(c.Context, episode.Folder, episode.ID)
 		if err != nil {
-			return fmt.Errorf("reading subtitle %s: %w", sub.Lang, err)
+			errs = append(errs, err)
+			continue
 		}
+		refreshed++
 
-		filename := filepath.Join(subsDir, fmt.Sprintf("%s_%s.vtt", subs.VideoID, sub.Lang))
-		if err := os.WriteFile(filename, content, 0644); err != nil {
-			return fmt.Errorf("writing subtitle file: %w", err)
+		if len(changes) > 0 {
+			changed++
+			fields := make([]string, len(changes))
+			for i, change := range changes {
+				fields[i] = change.Field
+			}
+			fmt.Printf("Updated %s: %s\n", episode.ID, strings.Join(fields, ", "))
+		} else if c.Bool("verbose") {
+			fmt.Printf("Unchanged %s\n", episode.ID)
 		}
 	}
 
+	for _, err := range errs {
+		fmt.Printf("Error: %v\n", err)
+	}
+
+	fmt.Printf("Refreshed %d episode(s), %d changed\n", refreshed, changed)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%d error(s) while refreshing metadata", len(errs))
+	}
+
 	return nil
 }
 
-func updateFolderTime(meta *rtve.VideoMetadata, folder string) error {
-	layout := "02-01-2006 15:04:05"
-	pubDate, err := time.Parse(layout, meta.PublicationDate)
+func exportTranscript(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return fmt.Errorf("usage: %s transcript <file.vtt...>", c.App.Name)
+	}
+
+	for _, path := range c.Args().Slice() {
+		if c.Bool("save") {
+			saved, err := rtve.SaveTranscript(path)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Saved %s\n", saved)
+			continue
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		cues, err := rtve.ParseVTT(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		fmt.Print(rtve.Transcript(cues))
+	}
+
+	return nil
+}
+
+func exportSentences(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("usage: %s sentences <file.vtt>", c.App.Name)
+	}
+
+	f, err := os.Open(c.Args().First())
 	if err != nil {
-		return fmt.Errorf("parsing publication date: %w", err)
+		return err
 	}
+	defer f.Close()
 
-	return os.Chtimes(folder, pubDate, pubDate)
+	cues, err := rtve.ParseVTT(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", c.Args().First(), err)
+	}
+
+	sentences := rtve.Sentences(cues)
+
+	if c.Bool("json") {
+		type sentence struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+			Text  string `json:"text"`
+		}
+
+		out := make([]sentence, 0, len(sentences))
+		for _, s := range sentences {
+			out = append(out, sentence{
+				Start: rtve.FormatVTTTimestamp(s.Start),
+				End:   rtve.FormatVTTTimestamp(s.End),
+				Text:  s.Text,
+			})
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	for _, s := range sentences {
+		fmt.Printf("%s --> %s %s\n", rtve.FormatVTTTimestamp(s.Start), rtve.FormatVTTTimestamp(s.End), s.Text)
+	}
+
+	return nil
 }
 
 func listShows(c *cli.Context) error {
@@ -341,7 +849,12 @@ func listShows(c *cli.Context) error {
 
 	// Print each show with its details
 	for _, show := range shows {
-		fmt.Printf("- %s (ID: %s)\n", show, rtve.ShowMap(show).ID)
+		details := rtve.ShowMap(show)
+		if details.Media == rtve.MediaAudio {
+			fmt.Printf("- %s (ID: %s, radio)\n", show, details.ID)
+			continue
+		}
+		fmt.Printf("- %s (ID: %s)\n", show, details.ID)
 	}
 
 	fmt.Println("\nUse the show name with the fetch command:")
@@ -349,3 +862,39 @@ func listShows(c *cli.Context) error {
 
 	return nil
 }
+
+func searchPrograms(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return fmt.Errorf("usage: %s search <query...>", c.App.Name)
+	}
+
+	programs, err := api.SearchProgramsContext(c.Context, strings.Join(c.Args().Slice(), " "))
+	if err != nil {
+		return err
+	}
+	if len(programs) == 0 {
+		fmt.Println("No programs found")
+		return nil
+	}
+
+	for _, p := range programs {
+		if p.Media == rtve.MediaAudio {
+			fmt.Printf("- %s (ID: %s, radio): %s\n", p.Name, p.ID, p.Title)
+			continue
+		}
+		fmt.Printf("- %s (ID: %s): %s\n", p.Name, p.ID, p.Title)
+	}
+
+	path, err := rtve.DefaultShowsFile()
+	if err != nil {
+		path = "shows.json"
+	}
+	fmt.Printf("\nAdd a program to %s to fetch it, e.g.:\n", path)
+	if programs[0].Media == rtve.MediaAudio {
+		fmt.Printf("  {\"%s\": {\"id\": \"%s\", \"media\": \"audio\"}}\n", programs[0].Name, programs[0].ID)
+	} else {
+		fmt.Printf("  {\"%s\": {\"id\": \"%s\"}}\n", programs[0].Name, programs[0].ID)
+	}
+
+	return nil
+}
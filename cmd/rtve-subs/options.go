@@ -0,0 +1,200 @@
+package main
+
+import (
+	"github.com/rubiojr/rtve-go"
+	"github.com/urfave/cli/v2"
+)
+
+var outputFlag = &cli.StringFlag{
+	Name:    "output",
+	Aliases: []string{"o"},
+	Value:   "rtve-videos",
+	Usage:   "Output directory for downloaded content",
+}
+
+var verboseFlag = &cli.BoolFlag{
+	Name:    "verbose",
+	Aliases: []string{"v"},
+	Value:   false,
+	Usage:   "Enable verbose output",
+}
+
+var layoutFlag = &cli.StringFlag{
+	Name:  "layout",
+	Usage: "Save new videos to folders named with this template instead of by date, e.g. {slug}/{season} (placeholders: {year}, {month}, {date}, {id}, {slug}, {unique-slug}, {season}, {episode})",
+}
+
+var imagesFlag = &cli.BoolFlag{
+	Name:  "images",
+	Usage: "Also download episode thumbnails and program posters, including those missing from episodes already archived",
+}
+
+// networkFlags configure how requests are sent to RTVE.
+var networkFlags = []cli.Flag{
+	&cli.Float64Flag{
+		Name:  "rate-limit",
+		Usage: "Maximum requests per second sent to RTVE (0 = no limit)",
+	},
+	&cli.StringFlag{
+		Name:  "proxy",
+		Usage: "Send requests through an HTTP or SOCKS5 proxy (e.g. socks5://127.0.0.1:1080)",
+	},
+}
+
+// downloadFlags configure how videos are downloaded and saved, shared by
+// the commands downloading them: fetch and retry.
+var downloadFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "prioritize",
+		Value: "listing",
+		Usage: "Order to fetch videos in: listing (newest first) or expiring (closest to their availability deadline first)",
+	},
+	&cli.IntFlag{
+		Name:  "concurrency",
+		Value: 1,
+		Usage: "Number of videos to download at the same time",
+	},
+	&cli.StringFlag{
+		Name:  "cache-dir",
+		Usage: "Cache listing pages and metadata in this directory, revalidating them on later runs",
+	},
+	&cli.BoolFlag{
+		Name:  "strict",
+		Usage: "Fail on videos with missing or malformed metadata fields",
+	},
+	&cli.BoolFlag{
+		Name:  "read-only",
+		Usage: "Never write to the output directory, failing for videos that would need downloading",
+	},
+	&cli.BoolFlag{
+		Name:  "month-shards",
+		Usage: "Save videos to YYYY/MM/YYYY-MM-DD folders",
+	},
+	layoutFlag,
+	imagesFlag,
+	&cli.BoolFlag{
+		Name:  "video",
+		Usage: "Also download the video files of new episodes",
+	},
+	&cli.BoolFlag{
+		Name:  "audio",
+		Usage: "Also download the audio files of new episodes of radio programs",
+	},
+	&cli.BoolFlag{
+		Name:  "remux",
+		Usage: "Remux videos downloaded from HLS streams to MP4 with ffmpeg (requires --video)",
+	},
+	&cli.StringFlag{
+		Name:  "video-codec",
+		Usage: "Transcode downloaded videos with this ffmpeg encoder, e.g. libx265 (requires --video)",
+	},
+	&cli.BoolFlag{
+		Name:  "embed-subs",
+		Usage: "Embed the downloaded subtitles in the video files with ffmpeg (requires --video)",
+	},
+	&cli.BoolFlag{
+		Name:  "srt",
+		Usage: "Save the downloaded subtitles as SRT files next to the video files (requires --video)",
+	},
+	&cli.DurationFlag{
+		Name:  "subtitle-offset",
+		Usage: "Delay embedded and SRT subtitles by this much, e.g. 2.5s, for streams with a pre-roll",
+	},
+	&cli.BoolFlag{
+		Name:  "detect-offset",
+		Usage: "Detect the subtitle offset of each video from the silence before speech with ffmpeg, falling back to --subtitle-offset",
+	},
+	&cli.BoolFlag{
+		Name:  "audio-only",
+		Usage: "Download just the audio of new episodes: the audio rendition of their HLS stream, or their lowest quality video, extracted with ffmpeg",
+	},
+	&cli.StringFlag{
+		Name:  "audio-format",
+		Value: "m4a",
+		Usage: "Audio format for --audio-only: m4a or mp3",
+	},
+}
+
+// flags joins flag lists into the flags of a command.
+func flags(lists ...[]cli.Flag) []cli.Flag {
+	var all []cli.Flag
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+	return all
+}
+
+// scraperOptions returns the Scrapper options set with the command line
+// flags. Flags a command doesn't define read as their zero value, which
+// leaves the Scrapper's default.
+func scraperOptions(c *cli.Context) ([]rtve.Option, error) {
+	durability, err := rtve.ParseDurability(c.String("fsync"))
+	if err != nil {
+		return nil, err
+	}
+
+	priority, err := rtve.ParsePriority(c.String("prioritize"))
+	if err != nil {
+		return nil, err
+	}
+
+	var corrections rtve.Corrections
+	if path := c.String("corrections"); path != "" {
+		corrections, err = rtve.LoadCorrections(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	options := []rtve.Option{
+		rtve.WithOutputPath(c.String("output")),
+		rtve.WithVerbose(c.Bool("verbose")),
+		rtve.WithMinDuration(c.Duration("min-duration")),
+		rtve.WithMaxDuration(c.Duration("max-duration")),
+		rtve.WithCorrections(corrections),
+		rtve.WithTimeBudget(c.Duration("time-budget")),
+		rtve.WithDurability(durability),
+		rtve.WithPriority(priority),
+		rtve.WithMonthShards(c.Bool("month-shards")),
+		rtve.WithLayout(c.String("layout")),
+		rtve.WithConcurrency(c.Int("concurrency")),
+		rtve.WithRateLimit(c.Float64("rate-limit")),
+		rtve.WithCacheDir(c.String("cache-dir")),
+		rtve.WithStrictParsing(c.Bool("strict")),
+		rtve.WithPageSize(c.Int("page-size")),
+		rtve.WithImages(c.Bool("images")),
+		rtve.WithVideoDownload(c.Bool("video")),
+		rtve.WithAudioDownload(c.Bool("audio")),
+		rtve.WithReadOnly(c.Bool("read-only")),
+	}
+	// Scrappers with a show default to the show's media type
+	if name := c.String("media"); name != "" {
+		media, err := rtve.ParseMediaType(name)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, rtve.WithMediaType(media))
+	}
+	if proxy := c.String("proxy"); proxy != "" {
+		options = append(options, rtve.WithProxy(proxy))
+	}
+	if spec := c.String("inject-faults"); spec != "" {
+		faults, err := rtve.ParseFaults(spec)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, rtve.WithFaults(faults))
+	}
+	ffmpeg, err := ffmpegPostProcessor(c)
+	if err != nil {
+		return nil, err
+	}
+	if ffmpeg != nil {
+		options = append(options, rtve.WithPostProcessor(ffmpeg.PostProcess))
+	}
+	if c.Bool("audio-only") {
+		options = append(options, rtve.WithAudioOnly(ffmpeg))
+	}
+
+	return options, nil
+}
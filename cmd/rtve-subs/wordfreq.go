@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rubiojr/rtve-go/analysis"
+	"github.com/urfave/cli/v2"
+)
+
+// wordFrequencyCommand reports word statistics of the archived subtitles,
+// optionally exporting them as CSV.
+var wordFrequencyCommand = &cli.Command{
+	Name:   "word-frequency",
+	Usage:  "Count words and n-grams in the archived subtitles, with per-episode type/token ratios",
+	Action: wordFrequency,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Value:   "rtve-videos",
+			Usage:   "Archive directory to analyze",
+		},
+		&cli.StringFlag{
+			Name:  "lang",
+			Value: "es",
+			Usage: "Language of the subtitles to analyze",
+		},
+		&cli.IntFlag{
+			Name:  "ngram",
+			Value: 2,
+			Usage: "Size of the n-grams to count (less than 2 = none)",
+		},
+		&cli.IntFlag{
+			Name:  "top",
+			Value: 20,
+			Usage: "Number of most frequent words and n-grams to print",
+		},
+		&cli.StringFlag{
+			Name:  "tokens-csv",
+			Usage: "Write the frequency of every word to this CSV file",
+		},
+		&cli.StringFlag{
+			Name:  "ngrams-csv",
+			Usage: "Write the frequency of every n-gram to this CSV file",
+		},
+		&cli.StringFlag{
+			Name:  "episodes-csv",
+			Usage: "Write the word statistics of each episode to this CSV file",
+		},
+	},
+}
+
+func wordFrequency(c *cli.Context) error {
+	corpus, err := analysis.Analyze(c.String("output"), c.String("lang"), c.Int("ngram"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: some episodes were skipped: %v\n", err)
+	}
+
+	tokens := 0
+	for _, episode := range corpus.Episodes {
+		tokens += episode.Tokens
+	}
+	fmt.Printf("%d episodes, %d words, %d distinct\n", len(corpus.Episodes), tokens, len(corpus.Tokens))
+
+	fmt.Println("\nMost frequent words:")
+	for _, count := range corpus.Tokens.Top(c.Int("top")) {
+		fmt.Printf("  %7d  %s\n", count.Count, count.Term)
+	}
+	if len(corpus.NGrams) > 0 {
+		fmt.Printf("\nMost frequent %d-grams:\n", corpus.N)
+		for _, count := range corpus.NGrams.Top(c.Int("top")) {
+			fmt.Printf("  %7d  %s\n", count.Count, count.Term)
+		}
+	}
+
+	if path := c.String("tokens-csv"); path != "" {
+		if err := writeCSV(path, func(f *os.File) error {
+			return analysis.WriteCountsCSV(f, corpus.Tokens.Top(0))
+		}); err != nil {
+			return err
+		}
+	}
+	if path := c.String("ngrams-csv"); path != "" {
+		if err := writeCSV(path, func(f *os.File) error {
+			return analysis.WriteCountsCSV(f, corpus.NGrams.Top(0))
+		}); err != nil {
+			return err
+		}
+	}
+	if path := c.String("episodes-csv"); path != "" {
+		if err := writeCSV(path, func(f *os.File) error {
+			return analysis.WriteEpisodesCSV(f, corpus.Episodes)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeCSV(path string, write func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,121 @@
+//go:build contract
+
+package rtve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// Contract tests assert that the live RTVE API still returns the data the
+// library depends on, so breakage shows up as a clear contract failure
+// instead of deep in parsing code. They hit the network and only build
+// with the contract tag:
+//
+//	go test -tags contract -run TestContract .
+
+// contractVideoID returns the ID of a current video from the first listing
+// page of telediario-2.
+func contractVideoID(t *testing.T, s *Scrapper) string {
+	t.Helper()
+
+	videos, err := s.ScrapePage(0)
+	if err != nil {
+		t.Fatalf("contract: listing page can't be fetched: %v", err)
+	}
+	if len(videos) == 0 {
+		t.Fatalf("contract: listing page has no links matching %s", urlMap[s.Program].Regex)
+	}
+	return videos[0].ID
+}
+
+func TestContractListingPages(t *testing.T) {
+	for _, show := range ListShows() {
+		t.Run(show, func(t *testing.T) {
+			videos, err := NewScrapper(show).ScrapePage(0)
+			if err != nil {
+				t.Fatalf("contract: listing page can't be fetched: %v", err)
+			}
+			if len(videos) == 0 {
+				t.Fatalf("contract: listing page has no links matching %s", urlMap[show].Regex)
+			}
+		})
+	}
+}
+
+func TestContractVideoMetadata(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	id := contractVideoID(t, s)
+
+	body, err := s.get(context.Background(), fmt.Sprintf(ApiURL, id))
+	if err != nil {
+		t.Fatalf("contract: metadata for %s can't be fetched: %v", id, err)
+	}
+
+	var resp struct {
+		Page struct {
+			Items []map[string]any `json:"items"`
+		} `json:"page"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("contract: metadata is not a page.items JSON object: %v", err)
+	}
+	if len(resp.Page.Items) == 0 {
+		t.Fatalf("contract: metadata page.items is empty")
+	}
+	item := resp.Page.Items[0]
+
+	for _, field := range []string{"id", "longTitle", "publicationDate"} {
+		if _, ok := item[field].(string); !ok {
+			t.Errorf("contract: metadata field %q is missing or not a string: %v", field, item[field])
+		}
+	}
+
+	if _, ok := item["duration"].(float64); !ok {
+		t.Errorf("contract: metadata field \"duration\" is missing or not a number: %v", item["duration"])
+	}
+
+	if date, ok := item["publicationDate"].(string); ok {
+		if _, err := ParseRTVEDate(date); err != nil {
+			t.Errorf("contract: publicationDate no longer uses the %q layout: %v", DateLayout, err)
+		}
+	}
+}
+
+func TestContractSubtitles(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	id := contractVideoID(t, s)
+
+	body, err := s.get(context.Background(), fmt.Sprintf(SubsURL, id))
+	if err != nil {
+		t.Fatalf("contract: subtitle listing for %s can't be fetched: %v", id, err)
+	}
+
+	var resp struct {
+		Page struct {
+			Items []map[string]any `json:"items"`
+		} `json:"page"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("contract: subtitle listing is not a page.items JSON object: %v", err)
+	}
+	if len(resp.Page.Items) == 0 {
+		t.Skipf("video %s has no subtitles yet", id)
+	}
+
+	for _, item := range resp.Page.Items {
+		if lang, ok := item["lang"].(string); !ok || lang == "" {
+			t.Errorf("contract: subtitle item has no lang: %v", item)
+		}
+
+		src, _ := item["src"].(string)
+		u, err := url.Parse(src)
+		if err != nil || u.Scheme != "https" || !strings.HasSuffix(u.Path, ".vtt") {
+			t.Errorf("contract: subtitle src is not an https URL to a .vtt file: %q", src)
+		}
+	}
+}
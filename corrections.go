@@ -0,0 +1,74 @@
+package rtve
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Corrections overrides fields of RTVE video metadata that are known to be
+// wrong. It maps a video ID to the fields to override, keyed by their JSON
+// name in VideoMetadata:
+//
+//	{
+//	  "16492499": {
+//	    "longTitle": "Telediario - 21 horas - 14/03/25",
+//	    "publicationDate": "14-03-2025 21:00:00"
+//	  }
+//	}
+//
+// Fields that VideoMetadata doesn't model are corrected in its Raw JSON.
+// Corrections are applied every time metadata is downloaded, so local fixes
+// survive re-fetches without editing the saved JSON files by hand.
+type Corrections map[string]map[string]json.RawMessage
+
+// LoadCorrections reads a corrections file in the format described in Corrections.
+func LoadCorrections(path string) (Corrections, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading corrections file: %w", err)
+	}
+
+	var c Corrections
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing corrections file %s: %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Apply overrides the fields of m that have a correction for m.ID.
+func (c Corrections) Apply(m *VideoMetadata) error {
+	fields, ok := c[m.ID]
+	if !ok || len(fields) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("encoding corrections for %s: %w", m.ID, err)
+	}
+
+	id, raw := m.ID, m.Raw
+	if err := json.Unmarshal(data, m); err != nil {
+		return fmt.Errorf("applying corrections for %s: %w", id, err)
+	}
+	// The ID identifies the correction and can't be overridden
+	m.ID = id
+	m.Raw = raw
+
+	// Corrections can also fix fields that aren't modeled
+	if len(raw) > 0 {
+		overrides := make(map[string]json.RawMessage, len(fields))
+		for name, value := range fields {
+			if name != "id" {
+				overrides[name] = value
+			}
+		}
+		if m.Raw, err = mergeJSONObject(raw, overrides); err != nil {
+			return fmt.Errorf("applying corrections for %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
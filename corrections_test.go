@@ -0,0 +1,105 @@
+package rtve
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCorrectionsApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corrections.json")
+	data := `{
+		"16492499": {
+			"longTitle": "Telediario - 21 horas - 15/03/25",
+			"publicationDate": "15-03-2025 21:00:00",
+			"duration": 1000,
+			"id": "ignored"
+		}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := LoadCorrections(path)
+	if err != nil {
+		t.Fatalf("Failed to load corrections: %v", err)
+	}
+
+	m := &VideoMetadata{
+		ID:              "16492499",
+		HTMLUrl:         "https://www.rtve.es/play/videos/telediario-2/14-03-25/16492499/",
+		LongTitle:       "Telediario - 21 horas - 14/03/25",
+		PublicationDate: "14-03-2025 21:00:00",
+	}
+	if err := c.Apply(m); err != nil {
+		t.Fatalf("Failed to apply corrections: %v", err)
+	}
+
+	if m.LongTitle != "Telediario - 21 horas - 15/03/25" {
+		t.Errorf("LongTitle not corrected: %s", m.LongTitle)
+	}
+	if m.PublicationDate != "15-03-2025 21:00:00" {
+		t.Errorf("PublicationDate not corrected: %s", m.PublicationDate)
+	}
+	if m.Duration != 1000 {
+		t.Errorf("Duration not corrected: %d", m.Duration)
+	}
+	if m.ID != "16492499" {
+		t.Errorf("ID should not be overridden, got %s", m.ID)
+	}
+	if m.HTMLUrl != "https://www.rtve.es/play/videos/telediario-2/14-03-25/16492499/" {
+		t.Errorf("Fields without corrections should be kept, got HTMLUrl %s", m.HTMLUrl)
+	}
+
+	other := &VideoMetadata{ID: "1", LongTitle: "Untouched"}
+	if err := c.Apply(other); err != nil || other.LongTitle != "Untouched" {
+		t.Errorf("Video without corrections should be untouched: %v %+v", err, other)
+	}
+}
+
+func TestCorrectionsInvalidValue(t *testing.T) {
+	c := Corrections{"1": {"duration": []byte(`"not a number"`)}}
+	if err := c.Apply(&VideoMetadata{ID: "1"}); err == nil {
+		t.Error("Expected error applying a correction with the wrong type")
+	}
+}
+
+func TestLoadCorrectionsMalformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corrections.json")
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCorrections(path); err == nil {
+		t.Error("Expected error loading malformed corrections")
+	}
+}
+
+func TestCorrectionsApplyUnmodeledField(t *testing.T) {
+	m := &VideoMetadata{}
+	if err := json.Unmarshal([]byte(`{"id":"123","longTitle":"Wrong","description":"Wrong too"}`), m); err != nil {
+		t.Fatal(err)
+	}
+
+	c := Corrections{"123": {
+		"id":          json.RawMessage(`"999"`),
+		"longTitle":   json.RawMessage(`"Right"`),
+		"description": json.RawMessage(`"Right too"`),
+	}}
+	if err := c.Apply(m); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var saved map[string]any
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved["id"] != "123" || saved["longTitle"] != "Right" || saved["description"] != "Right too" {
+		t.Errorf("Unexpected corrected metadata: %s", data)
+	}
+}
@@ -0,0 +1,32 @@
+package rtve
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is the layout RTVE uses for dates in its API responses,
+// e.g. "14-03-2025 21:00:00".
+const DateLayout = "02-01-2006 15:04:05"
+
+// Layouts used to name the per-year, per-month and per-day folders videos
+// are saved to. Month folders are only used with month sharding.
+const (
+	YearFolderLayout  = "2006"
+	MonthFolderLayout = "01"
+	DayFolderLayout   = "2006-01-02"
+)
+
+// ParseRTVEDate parses a date in RTVE's DateLayout format.
+func ParseRTVEDate(value string) (time.Time, error) {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing RTVE date %q: %w", value, err)
+	}
+	return t, nil
+}
+
+// FormatRTVEDate formats t using RTVE's DateLayout format.
+func FormatRTVEDate(t time.Time) string {
+	return t.Format(DateLayout)
+}
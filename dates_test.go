@@ -0,0 +1,30 @@
+package rtve
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRTVEDate(t *testing.T) {
+	got, err := ParseRTVEDate("14-03-2025 21:00:00")
+	if err != nil {
+		t.Fatalf("Failed to parse date: %v", err)
+	}
+
+	expected := time.Date(2025, 3, 14, 21, 0, 0, 0, time.UTC)
+	if !got.Equal(expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	if FormatRTVEDate(got) != "14-03-2025 21:00:00" {
+		t.Errorf("Expected round trip to return the same value, got %s", FormatRTVEDate(got))
+	}
+}
+
+func TestParseRTVEDateInvalid(t *testing.T) {
+	for _, value := range []string{"", "2025-03-14 21:00:00", "14/03/2025"} {
+		if _, err := ParseRTVEDate(value); err == nil {
+			t.Errorf("Expected error parsing %q", value)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package rtve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Durability controls whether files written to the archive are flushed to
+// disk before a video is considered done.
+type Durability int
+
+const (
+	// DurabilityOff leaves flushing to the operating system. It's the
+	// fastest option and the default.
+	DurabilityOff Durability = iota
+
+	// DurabilityCritical syncs video metadata files and their folders.
+	// The metadata file marks a video as archived, so after a power loss
+	// a video is either fully recorded or downloaded again.
+	DurabilityCritical
+
+	// DurabilityAll also syncs subtitle files.
+	DurabilityAll
+)
+
+// ParseDurability parses a durability policy name: "off", "critical" or "all".
+func ParseDurability(name string) (Durability, error) {
+	switch name {
+	case "", "off":
+		return DurabilityOff, nil
+	case "critical":
+		return DurabilityCritical, nil
+	case "all":
+		return DurabilityAll, nil
+	}
+	return DurabilityOff, fmt.Errorf("invalid durability policy %q (valid: off, critical, all)", name)
+}
+
+func (d Durability) String() string {
+	switch d {
+	case DurabilityCritical:
+		return "critical"
+	case DurabilityAll:
+		return "all"
+	}
+	return "off"
+}
+
+// writeFile writes data to path, syncing the file and its parent directory
+// when the scraper's durability policy requires it. critical marks files
+// that DurabilityCritical applies to.
+func (s *Scrapper) writeFile(path string, data []byte, critical bool) error {
+	if err := s.checkWritable(path); err != nil {
+		return err
+	}
+
+	if s.durability == DurabilityOff || (!critical && s.durability != DurabilityAll) {
+		return os.WriteFile(path, data, 0644)
+	}
+
+	return writeFileSync(path, data)
+}
+
+// writeFileSync writes data to a temporary file next to path, syncs it and
+// renames it into place, so readers never see a partially written file,
+// then syncs the parent directory to persist the rename.
+func writeFileSync(path string, data []byte) error {
+	dir := filepath.Dir(path)
+
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return syncDir(dir)
+}
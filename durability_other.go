@@ -0,0 +1,16 @@
+//go:build !windows
+
+package rtve
+
+import "os"
+
+// syncDir syncs a directory, persisting the files renamed into it.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -0,0 +1,58 @@
+package rtve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDurability(t *testing.T) {
+	tests := map[string]Durability{
+		"":         DurabilityOff,
+		"off":      DurabilityOff,
+		"critical": DurabilityCritical,
+		"all":      DurabilityAll,
+	}
+
+	for name, expected := range tests {
+		d, err := ParseDurability(name)
+		if err != nil {
+			t.Errorf("ParseDurability(%q) failed: %v", name, err)
+		}
+		if d != expected {
+			t.Errorf("ParseDurability(%q) = %v, expected %v", name, d, expected)
+		}
+	}
+
+	if _, err := ParseDurability("sometimes"); err == nil {
+		t.Error("Expected error for invalid policy")
+	}
+}
+
+func TestWriteFileDurability(t *testing.T) {
+	for _, d := range []Durability{DurabilityOff, DurabilityCritical, DurabilityAll} {
+		t.Run(d.String(), func(t *testing.T) {
+			dir := t.TempDir()
+			s := NewScrapper("telediario-2", WithDurability(d))
+
+			path := filepath.Join(dir, "video_1.json")
+			if err := s.writeFile(path, []byte("{}"), true); err != nil {
+				t.Fatalf("writeFile failed: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil || string(data) != "{}" {
+				t.Errorf("Unexpected content %q, err %v", data, err)
+			}
+
+			// No temporary files are left behind
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("Expected a single file in %s, got %d", dir, len(entries))
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package rtve
+
+// syncDir does nothing on Windows, which can't sync directories: renames
+// are persisted with the file system's metadata.
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,66 @@
+package rtve
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// windows1252 maps the 0x80-0x9F range of Windows-1252, which is what files
+// labelled as ISO-8859-1 usually contain, to Unicode. Unassigned bytes are
+// mapped to the replacement character.
+var windows1252 = [32]rune{
+	'€', '�', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '�', 'Ž', '�',
+	'�', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '�', 'ž', 'Ÿ',
+}
+
+// ToUTF8 converts subtitle content to clean UTF-8: byte order marks are
+// removed, UTF-16 is decoded, and content that isn't valid UTF-8 is decoded
+// as ISO-8859-1 (Windows-1252), the encoding RTVE occasionally serves.
+func ToUTF8(content []byte) []byte {
+	switch {
+	case bytes.HasPrefix(content, bomUTF8):
+		// A BOM doesn't guarantee the rest is valid UTF-8, keep checking
+		content = content[len(bomUTF8):]
+	case bytes.HasPrefix(content, bomUTF16LE):
+		return decodeUTF16(content[len(bomUTF16LE):], binary.LittleEndian)
+	case bytes.HasPrefix(content, bomUTF16BE):
+		return decodeUTF16(content[len(bomUTF16BE):], binary.BigEndian)
+	}
+
+	if utf8.Valid(content) {
+		return content
+	}
+
+	var b strings.Builder
+	b.Grow(len(content) + len(content)/4)
+	for _, c := range content {
+		switch {
+		case c < 0x80:
+			b.WriteByte(c)
+		case c < 0xA0:
+			b.WriteRune(windows1252[c-0x80])
+		default:
+			b.WriteRune(rune(c))
+		}
+	}
+
+	return []byte(b.String())
+}
+
+func decodeUTF16(content []byte, order binary.ByteOrder) []byte {
+	units := make([]uint16, 0, len(content)/2)
+	for i := 0; i+1 < len(content); i += 2 {
+		units = append(units, order.Uint16(content[i:]))
+	}
+
+	return []byte(string(utf16.Decode(units)))
+}
@@ -0,0 +1,53 @@
+package rtve
+
+import (
+	"os"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestToUTF8(t *testing.T) {
+	expected, err := os.ReadFile("fixtures/subs_utf8.vtt")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+
+	fixtures := []string{
+		"fixtures/subs_utf8.vtt",
+		"fixtures/subs_utf8_bom.vtt",
+		"fixtures/subs_cp1252.vtt",
+		"fixtures/subs_utf16le.vtt",
+	}
+
+	for _, fixture := range fixtures {
+		t.Run(fixture, func(t *testing.T) {
+			data, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatalf("Failed to read test fixture: %v", err)
+			}
+
+			got := ToUTF8(data)
+			if !utf8.Valid(got) {
+				t.Fatalf("Result is not valid UTF-8")
+			}
+			if string(got) != string(expected) {
+				t.Errorf("Expected %q, got %q", expected, got)
+			}
+		})
+	}
+}
+
+func TestToUTF8Latin1(t *testing.T) {
+	// "año" in ISO-8859-1
+	got := ToUTF8([]byte{'a', 0xF1, 'o'})
+	if string(got) != "año" {
+		t.Errorf("Expected año, got %q", got)
+	}
+}
+
+func TestToUTF8UTF16BE(t *testing.T) {
+	got := ToUTF8([]byte{0xFE, 0xFF, 0x00, 'W', 0x00, 0xF1})
+	if string(got) != "Wñ" {
+		t.Errorf("Expected Wñ, got %q", got)
+	}
+}
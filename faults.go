@@ -0,0 +1,158 @@
+package rtve
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is returned for requests failed by fault injection, see
+// WithFaults.
+var ErrInjectedFault = errors.New("injected fault")
+
+// Faults describes the failures injected into a Scrapper's requests with
+// WithFaults, to check how code built on it copes with an unreliable
+// network or an overloaded RTVE. It's meant for tests and demos, never for
+// real archiving.
+type Faults struct {
+	// FailureRate is the probability, from 0 to 1, that a request fails
+	FailureRate float64
+	// StatusCode is the HTTP status of failed requests, e.g. 503 or 429.
+	// Zero fails them with a connection error wrapping ErrInjectedFault.
+	StatusCode int
+	// SlowRate is the probability, from 0 to 1, that a request is delayed
+	SlowRate float64
+	// Delay is how long slow requests are delayed
+	Delay time.Duration
+	// Seed makes the injected faults reproducible across runs. Zero picks
+	// a random seed.
+	Seed int64
+}
+
+// ParseFaults parses a fault injection spec, comma separated key=value
+// pairs: fail (FailureRate), status (StatusCode), slow (SlowRate), delay
+// (Delay, e.g. 2s) and seed (Seed), e.g. "fail=0.1,status=503,slow=0.2,delay=2s".
+func ParseFaults(spec string) (Faults, error) {
+	var f Faults
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return Faults{}, fmt.Errorf("invalid fault %q: expected key=value", field)
+		}
+
+		var err error
+		switch key {
+		case "fail":
+			f.FailureRate, err = parseProbability(value)
+		case "status":
+			f.StatusCode, err = strconv.Atoi(value)
+			if err == nil && (f.StatusCode < 100 || f.StatusCode > 599) {
+				err = fmt.Errorf("not an HTTP status code")
+			}
+		case "slow":
+			f.SlowRate, err = parseProbability(value)
+		case "delay":
+			f.Delay, err = time.ParseDuration(value)
+		case "seed":
+			f.Seed, err = strconv.ParseInt(value, 10, 64)
+		default:
+			return Faults{}, fmt.Errorf("unknown fault %q: must be fail, status, slow, delay or seed", key)
+		}
+		if err != nil {
+			return Faults{}, fmt.Errorf("invalid fault %q: %w", field, err)
+		}
+	}
+
+	return f, nil
+}
+
+func parseProbability(value string) (float64, error) {
+	p, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if p < 0 || p > 1 {
+		return 0, fmt.Errorf("probability must be between 0 and 1")
+	}
+	return p, nil
+}
+
+// WithFaults injects faults into every request the Scrapper sends: some
+// fail and some are slow, at random. The retry logic sees injected
+// failures as it would real ones, so failures with a 5xx or 429 status are
+// retried. It applies whatever the client is configured with, e.g. by
+// WithTransport or WithProxy.
+func WithFaults(f Faults) Option {
+	return func(s *Scrapper) {
+		s.faults = &f
+	}
+}
+
+// faultTransport is the http.RoundTripper injecting Faults.
+type faultTransport struct {
+	base   http.RoundTripper
+	faults Faults
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func newFaultTransport(base http.RoundTripper, f Faults) *faultTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	seed := f.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &faultTransport{base: base, faults: f, rnd: rand.New(rand.NewSource(seed))}
+}
+
+// roll reports whether an event with probability p happens.
+func (t *faultTransport) roll(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rnd.Float64() < p
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.roll(t.faults.SlowRate) {
+		if err := sleepContext(req.Context(), t.faults.Delay); err != nil {
+			return nil, err
+		}
+	}
+
+	if !t.roll(t.faults.FailureRate) {
+		return t.base.RoundTrip(req)
+	}
+
+	if t.faults.StatusCode == 0 {
+		return nil, fmt.Errorf("%w: connection to %s failed", ErrInjectedFault, req.URL.Host)
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", t.faults.StatusCode, http.StatusText(t.faults.StatusCode)),
+		StatusCode: t.faults.StatusCode,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, nil
+}
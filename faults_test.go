@@ -0,0 +1,95 @@
+package rtve
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseFaults(t *testing.T) {
+	f, err := ParseFaults("fail=0.1, status=503,slow=0.25,delay=2s,seed=42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Faults{FailureRate: 0.1, StatusCode: 503, SlowRate: 0.25, Delay: 2 * time.Second, Seed: 42}
+	if f != want {
+		t.Errorf("ParseFaults = %+v, want %+v", f, want)
+	}
+
+	if f, err := ParseFaults(""); err != nil || f != (Faults{}) {
+		t.Errorf("Expected no faults for an empty spec, got %+v, %v", f, err)
+	}
+
+	for _, spec := range []string{"fail", "fail=2", "slow=-0.1", "status=42", "delay=soon", "flaky=0.5"} {
+		if _, err := ParseFaults(spec); err == nil {
+			t.Errorf("Expected error parsing %q", spec)
+		}
+	}
+}
+
+func TestWithFaults(t *testing.T) {
+	var requests atomic.Int32
+	fixtures := fixtureTransport(t)
+	transport := WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		return fixtures(req)
+	}))
+
+	// Injected statuses go through the usual error handling
+	s := NewScrapper("telediario-2", WithFaults(Faults{FailureRate: 1, StatusCode: http.StatusNotFound}), transport)
+	if _, err := s.DownloadVideoMeta("16492499"); !errors.Is(err, ErrMetadataNotFound) {
+		t.Errorf("Expected ErrMetadataNotFound, got %v", err)
+	}
+
+	s = NewScrapper("telediario-2", transport, WithFaults(Faults{FailureRate: 1}))
+	if _, err := s.DownloadVideoMeta("16492499"); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("Expected ErrInjectedFault, got %v", err)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Errorf("Expected failed requests not to be sent, got %d", got)
+	}
+
+	s = NewScrapper("telediario-2", transport, WithFaults(Faults{SlowRate: 1, Delay: 50 * time.Millisecond}))
+	start := time.Now()
+	if _, err := s.DownloadVideoMeta("16492499"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected a slow response, took %s", elapsed)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected 1 request, got %d", got)
+	}
+}
+
+func TestFaultsSeed(t *testing.T) {
+	ok := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, ""), nil
+	})
+	outcomes := func() []bool {
+		transport := newFaultTransport(ok, Faults{FailureRate: 0.5, Seed: 7})
+		var failed []bool
+		for i := 0; i < 32; i++ {
+			req, _ := http.NewRequest("GET", "https://www.rtve.es/", nil)
+			_, err := transport.RoundTrip(req)
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	first, second := outcomes(), outcomes()
+	failures := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Faults differ between runs with the same seed at request %d", i)
+		}
+		if first[i] {
+			failures++
+		}
+	}
+	if failures == 0 || failures == len(first) {
+		t.Errorf("Expected some requests to fail, %d of %d did", failures, len(first))
+	}
+}
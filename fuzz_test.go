@@ -0,0 +1,120 @@
+package rtve
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// Fuzz targets for the parsers that handle RTVE responses, so malformed or
+// truncated responses can't panic long-running scrapes. Run one with e.g.
+//
+//	go test -run '^$' -fuzz FuzzParseVTT .
+
+func addFixtureSeed(f *testing.F, path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		f.Fatalf("Failed to read test fixture: %v", err)
+	}
+	f.Add(string(data))
+	// Truncated responses are a common failure mode
+	f.Add(string(data[:len(data)/2]))
+}
+
+func FuzzScrape(f *testing.F) {
+	addFixtureSeed(f, "fixtures/show.html")
+	f.Add(`<a href="https://www.rtve.es/play/videos/telediario-2/x/">`)
+
+	f.Fuzz(func(t *testing.T, content string) {
+		for _, show := range ListShows() {
+			videos, err := NewScrapper(show).scrape(content)
+			if err != nil {
+				continue
+			}
+			for _, v := range videos {
+				if v.ID == "" || strings.Contains(v.ID, "/") {
+					t.Errorf("Invalid video ID %q from link %q", v.ID, v.URL)
+				}
+			}
+		}
+	})
+}
+
+func FuzzVideoMetadataParse(f *testing.F) {
+	addFixtureSeed(f, "fixtures/video.json")
+	f.Add(`{"page":{"items":[]}}`)
+	f.Add(`{"page":{"items":[{"duration":"x"}]}}`)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		m := &VideoMetadata{}
+		if err := m.Parse(body); err != nil {
+			return
+		}
+		m.Length()
+		m.Slug()
+		ParseRTVEDate(m.PublicationDate)
+	})
+}
+
+func FuzzSubtitleResponse(f *testing.F) {
+	addFixtureSeed(f, "fixtures/subtitulos.json")
+	f.Add(`{"page":{"items":null}}`)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		var resp SubtitleResponse
+		json.Unmarshal([]byte(body), &resp)
+		for _, item := range resp.Page.Items {
+			GetLanguageName(item.Lang)
+		}
+	})
+}
+
+func FuzzParseVTT(f *testing.F) {
+	addFixtureSeed(f, "fixtures/subs_utf8.vtt")
+	addFixtureSeed(f, "fixtures/subs_cp1252.vtt")
+	f.Add(testVTT)
+	f.Add("WEBVTT\n\n-->\n")
+	f.Add("WEBVTT\n\n99:99 --> :\n")
+
+	f.Fuzz(func(t *testing.T, content string) {
+		cues, err := ParseVTT(strings.NewReader(string(ToUTF8([]byte(content)))))
+		if err != nil {
+			return
+		}
+		for _, s := range Sentences(cues) {
+			FormatVTTTimestamp(s.Start)
+			FormatVTTTimestamp(s.End)
+		}
+	})
+}
+
+func FuzzParseTTML(f *testing.F) {
+	f.Add(testTTML)
+	f.Add(`<tt><body><p begin="1s" end="2s">a<br/>b</p></body></tt>`)
+	f.Add(`<tt><p begin="00:00:01:99" dur="1t">x</p></tt>`)
+
+	f.Fuzz(func(t *testing.T, content string) {
+		vtt, err := ttmlToVTT(ToUTF8([]byte(content)))
+		if err != nil {
+			return
+		}
+		if _, err := ParseVTT(strings.NewReader(string(vtt))); err != nil {
+			t.Errorf("TTML converted to invalid WebVTT: %v\n%s", err, vtt)
+		}
+	})
+}
+
+func FuzzToUTF8(f *testing.F) {
+	for _, path := range []string{"fixtures/subs_utf8_bom.vtt", "fixtures/subs_cp1252.vtt", "fixtures/subs_utf16le.vtt"} {
+		addFixtureSeed(f, path)
+	}
+	f.Add("\xff\xfe\x00")
+
+	f.Fuzz(func(t *testing.T, content string) {
+		if out := ToUTF8([]byte(content)); !utf8.Valid(out) {
+			t.Errorf("ToUTF8(%q) returned invalid UTF-8 %q", content, out)
+		}
+	})
+}
@@ -0,0 +1,301 @@
+package rtve
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// hlsSegmentWorkers bounds the number of segments of a stream downloaded at
+// the same time.
+const hlsSegmentWorkers = 4
+
+// hlsPlaylist is a parsed HLS playlist: either a master playlist listing
+// variant streams, and the audio renditions some of them play, or a media
+// playlist listing segments.
+type hlsPlaylist struct {
+	Variants []hlsVariant
+	Audio    []hlsRendition
+	Segments []string
+}
+
+type hlsVariant struct {
+	URL       string
+	Bandwidth int
+}
+
+// hlsRendition is an audio rendition published as its own media playlist,
+// separate from the video of the variants.
+type hlsRendition struct {
+	URL     string
+	Default bool
+}
+
+// parseM3U8 parses an HLS playlist, resolving URIs against base. Encrypted
+// streams aren't supported.
+func parseM3U8(data []byte, base *url.URL) (*hlsPlaylist, error) {
+	playlist := &hlsPlaylist{}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+
+	first := true
+	var variant *hlsVariant
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if first {
+			if line != "#EXTM3U" {
+				return nil, errors.New("invalid HLS playlist: missing #EXTM3U header")
+			}
+			first = false
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-STREAM-INF:"):
+			bandwidth, _ := strconv.Atoi(m3u8Attribute(line, "BANDWIDTH"))
+			variant = &hlsVariant{Bandwidth: bandwidth}
+		case strings.HasPrefix(line, "#EXT-X-MEDIA:"):
+			// Renditions without a URI are muxed in the variants
+			uri := m3u8Attribute(line, "URI")
+			if m3u8Attribute(line, "TYPE") != "AUDIO" || uri == "" {
+				continue
+			}
+			ref, err := base.Parse(uri)
+			if err != nil {
+				return nil, fmt.Errorf("invalid HLS playlist URI %q: %w", uri, err)
+			}
+			playlist.Audio = append(playlist.Audio, hlsRendition{
+				URL:     ref.String(),
+				Default: m3u8Attribute(line, "DEFAULT") == "YES",
+			})
+		case strings.HasPrefix(line, "#EXT-X-KEY:"):
+			if method := m3u8Attribute(line, "METHOD"); method != "NONE" {
+				return nil, fmt.Errorf("encrypted HLS streams aren't supported (%s)", method)
+			}
+		case strings.HasPrefix(line, "#"):
+			// Other tags and comments
+		default:
+			ref, err := base.Parse(line)
+			if err != nil {
+				return nil, fmt.Errorf("invalid HLS playlist URI %q: %w", line, err)
+			}
+			if variant != nil {
+				variant.URL = ref.String()
+				playlist.Variants = append(playlist.Variants, *variant)
+				variant = nil
+			} else {
+				playlist.Segments = append(playlist.Segments, ref.String())
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if first {
+		return nil, errors.New("invalid HLS playlist: empty")
+	}
+
+	return playlist, nil
+}
+
+// m3u8Attribute returns the value of the attribute name of a playlist tag,
+// without quotes.
+func m3u8Attribute(line, name string) string {
+	_, attrs, _ := strings.Cut(line, ":")
+	for attrs != "" {
+		var attr string
+		// Quoted values may contain commas
+		if key, rest, ok := strings.Cut(attrs, "=\""); ok && !strings.Contains(key, ",") {
+			value, after, _ := strings.Cut(rest, "\"")
+			attr = key + "=" + value
+			attrs = strings.TrimPrefix(after, ",")
+		} else {
+			attr, attrs, _ = strings.Cut(attrs, ",")
+		}
+
+		if key, value, ok := strings.Cut(attr, "="); ok && key == name {
+			return value
+		}
+	}
+	return ""
+}
+
+// fetchPlaylist downloads and parses the HLS playlist at playlistURL.
+func (s *Scrapper) fetchPlaylist(ctx context.Context, playlistURL string) (*hlsPlaylist, error) {
+	base, err := url.Parse(playlistURL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.downloadWithRetry(ctx, playlistURL, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseM3U8(data, base)
+}
+
+// downloadHLS downloads the HLS stream at playlistURL, in its highest
+// bandwidth variant, and merges its segments into a single MPEG-TS file at
+// path. Segments are downloaded concurrently to a path.segments folder,
+// then concatenated in playlist order.
+func (s *Scrapper) downloadHLS(ctx context.Context, playlistURL, path string) error {
+	playlist, err := s.fetchPlaylist(ctx, playlistURL)
+	if err != nil {
+		return fmt.Errorf("error fetching HLS playlist: %w", err)
+	}
+
+	if len(playlist.Variants) > 0 {
+		best := playlist.Variants[0]
+		for _, variant := range playlist.Variants[1:] {
+			if variant.Bandwidth > best.Bandwidth {
+				best = variant
+			}
+		}
+
+		playlist, err = s.fetchPlaylist(ctx, best.URL)
+		if err != nil {
+			return fmt.Errorf("error fetching HLS playlist: %w", err)
+		}
+	}
+
+	return s.downloadSegments(ctx, playlist, path)
+}
+
+// downloadHLSAudio downloads the audio of the HLS stream at playlistURL to
+// path: the segments of its audio rendition if it publishes one, the
+// default one if there are several, or else the segments of its lowest
+// bandwidth variant, audio and video muxed together.
+func (s *Scrapper) downloadHLSAudio(ctx context.Context, playlistURL, path string) error {
+	playlist, err := s.fetchPlaylist(ctx, playlistURL)
+	if err != nil {
+		return fmt.Errorf("error fetching HLS playlist: %w", err)
+	}
+
+	var mediaURL string
+	if len(playlist.Audio) > 0 {
+		mediaURL = playlist.Audio[0].URL
+		for _, rendition := range playlist.Audio {
+			if rendition.Default {
+				mediaURL = rendition.URL
+				break
+			}
+		}
+	} else if len(playlist.Variants) > 0 {
+		lowest := playlist.Variants[0]
+		for _, variant := range playlist.Variants[1:] {
+			if variant.Bandwidth < lowest.Bandwidth {
+				lowest = variant
+			}
+		}
+		mediaURL = lowest.URL
+	}
+
+	if mediaURL != "" {
+		playlist, err = s.fetchPlaylist(ctx, mediaURL)
+		if err != nil {
+			return fmt.Errorf("error fetching HLS playlist: %w", err)
+		}
+	}
+
+	return s.downloadSegments(ctx, playlist, path)
+}
+
+// downloadSegments downloads the segments of a media playlist concurrently
+// to a path.segments folder, then concatenates them in playlist order into
+// a single file at path.
+func (s *Scrapper) downloadSegments(ctx context.Context, playlist *hlsPlaylist, path string) error {
+	if len(playlist.Segments) == 0 {
+		return errors.New("HLS playlist has no segments")
+	}
+
+	// Segments are kept until the file is merged, so an interrupted
+	// download resumes with the segments still missing
+	segmentsDir := path + ".segments"
+	if err := os.MkdirAll(segmentsDir, 0755); err != nil {
+		return err
+	}
+
+	segmentPath := func(i int) string {
+		return filepath.Join(segmentsDir, fmt.Sprintf("%06d.ts", i))
+	}
+
+	segmentErrs := make([]error, len(playlist.Segments))
+	sem := make(chan struct{}, hlsSegmentWorkers)
+	var wg sync.WaitGroup
+
+	for i, segment := range playlist.Segments {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := ctx.Err(); err != nil {
+				segmentErrs[i] = err
+				return
+			}
+
+			if _, err := os.Stat(segmentPath(i)); err == nil {
+				return
+			}
+
+			data, err := s.downloadWithRetry(ctx, segment, 3)
+			if err != nil {
+				segmentErrs[i] = fmt.Errorf("error downloading segment %d: %w", i, err)
+				return
+			}
+
+			// Renamed into place so a killed download never leaves a
+			// truncated segment that would be taken as complete
+			tmp := segmentPath(i) + ".tmp"
+			if err := os.WriteFile(tmp, data, 0644); err != nil {
+				segmentErrs[i] = err
+				return
+			}
+			segmentErrs[i] = os.Rename(tmp, segmentPath(i))
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(segmentErrs...); err != nil {
+		return err
+	}
+
+	err := s.writeMediaFile(path, func(w io.Writer) error {
+		for i := range playlist.Segments {
+			if err := appendFile(w, segmentPath(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(segmentsDir)
+}
+
+func appendFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
@@ -0,0 +1,156 @@
+package rtve
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseM3U8(t *testing.T) {
+	base, _ := url.Parse("https://example.com/video/master.m3u8?token=1")
+
+	master, err := parseM3U8([]byte(`#EXTM3U
+#EXT-X-STREAM-INF:BANDWIDTH=800000,RESOLUTION=640x360,CODECS="avc1.4d401e,mp4a.40.2"
+low/index.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=4000000,RESOLUTION=1920x1080
+https://cdn.example.com/high/index.m3u8
+`), base)
+	if err != nil {
+		t.Fatalf("parseM3U8 failed: %v", err)
+	}
+	expected := []hlsVariant{
+		{URL: "https://example.com/video/low/index.m3u8", Bandwidth: 800000},
+		{URL: "https://cdn.example.com/high/index.m3u8", Bandwidth: 4000000},
+	}
+	if len(master.Variants) != 2 || master.Variants[0] != expected[0] || master.Variants[1] != expected[1] {
+		t.Errorf("Unexpected variants: %+v", master.Variants)
+	}
+
+	master, err = parseM3U8([]byte(`#EXTM3U
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Original",DEFAULT=NO,URI="audio/vo.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="aac",NAME="Español",DEFAULT=YES,URI="audio/es.m3u8"
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subs",NAME="es",URI="subs/es.m3u8"
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="muxed",NAME="Muxed"
+#EXT-X-STREAM-INF:BANDWIDTH=800000,AUDIO="aac"
+low/index.m3u8
+`), base)
+	if err != nil {
+		t.Fatalf("parseM3U8 failed: %v", err)
+	}
+	expectedAudio := []hlsRendition{
+		{URL: "https://example.com/video/audio/vo.m3u8"},
+		{URL: "https://example.com/video/audio/es.m3u8", Default: true},
+	}
+	if len(master.Audio) != 2 || master.Audio[0] != expectedAudio[0] || master.Audio[1] != expectedAudio[1] {
+		t.Errorf("Unexpected audio renditions: %+v", master.Audio)
+	}
+	if len(master.Variants) != 1 {
+		t.Errorf("Unexpected variants: %+v", master.Variants)
+	}
+
+	media, err := parseM3U8([]byte(`#EXTM3U
+#EXT-X-TARGETDURATION:10
+#EXTINF:10.0,
+seg0.ts
+#EXTINF:10.0,
+seg1.ts
+#EXT-X-ENDLIST
+`), base)
+	if err != nil {
+		t.Fatalf("parseM3U8 failed: %v", err)
+	}
+	if len(media.Segments) != 2 || media.Segments[1] != "https://example.com/video/seg1.ts" {
+		t.Errorf("Unexpected segments: %v", media.Segments)
+	}
+
+	if _, err := parseM3U8([]byte("#EXTM3U\n#EXT-X-KEY:METHOD=AES-128,URI=\"key\"\nseg0.ts\n"), base); err == nil {
+		t.Error("Expected an error for an encrypted stream")
+	}
+	if _, err := parseM3U8([]byte("<html>"), base); err == nil {
+		t.Error("Expected an error for a non playlist")
+	}
+}
+
+func TestDownloadVideoHLS(t *testing.T) {
+	png := mediaPNG(MediaSource{Quality: "HD_FULL", URL: "https://hls.example.com/16492499/master.m3u8"})
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/ztnr/movil/thumbnail/rtveplayw/videos/16492499.png":
+			return newResponse(http.StatusOK, base64.StdEncoding.EncodeToString(png)), nil
+		case "/16492499/master.m3u8":
+			return newResponse(http.StatusOK, "#EXTM3U\n#EXT-X-STREAM-INF:BANDWIDTH=1\nlow.m3u8\n#EXT-X-STREAM-INF:BANDWIDTH=2\nhigh.m3u8\n"), nil
+		case "/16492499/high.m3u8":
+			var playlist strings.Builder
+			playlist.WriteString("#EXTM3U\n")
+			for _, seg := range []string{"a", "b", "c", "d", "e", "f"} {
+				playlist.WriteString("#EXTINF:10.0,\nseg-" + seg + ".ts\n")
+			}
+			playlist.WriteString("#EXT-X-ENDLIST\n")
+			return newResponse(http.StatusOK, playlist.String()), nil
+		}
+		if seg, ok := strings.CutPrefix(req.URL.Path, "/16492499/seg-"); ok {
+			return newResponse(http.StatusOK, strings.TrimSuffix(seg, ".ts")), nil
+		}
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	dir := t.TempDir()
+	path, err := s.DownloadVideo(&VideoMetadata{ID: "16492499"}, dir)
+	if err != nil {
+		t.Fatalf("DownloadVideo failed: %v", err)
+	}
+	if path != filepath.Join(dir, "video_16492499.ts") {
+		t.Errorf("Unexpected video path: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "abcdef" {
+		t.Errorf("Expected segments merged in order, got %q, %v", data, err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the video file to be left, found %d entries", len(entries))
+	}
+}
+
+func TestDownloadHLSResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "video_1.ts")
+
+	// Segments downloaded by an interrupted run
+	if err := os.MkdirAll(path+".segments", 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(path+".segments", "000000.ts"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(path+".segments", "000001.ts.tmp"), []byte("truncat"), 0644)
+
+	var requested []string
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/playlist.m3u8" {
+			return newResponse(http.StatusOK, "#EXTM3U\n#EXTINF:10,\nseg-a.ts\n#EXTINF:10,\nseg-b.ts\n"), nil
+		}
+		requested = append(requested, req.URL.Path)
+		return newResponse(http.StatusOK, strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/seg-"), ".ts")), nil
+	})
+
+	if err := s.downloadHLS(context.Background(), "https://example.com/playlist.m3u8", path); err != nil {
+		t.Fatalf("downloadHLS failed: %v", err)
+	}
+
+	if len(requested) != 1 || requested[0] != "/seg-b.ts" {
+		t.Errorf("Expected only the missing segment to be downloaded, got %v", requested)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "ab" {
+		t.Errorf("Expected merged segments, got %q", data)
+	}
+	if _, err := os.Stat(path + ".segments"); !os.IsNotExist(err) {
+		t.Errorf("Segments folder not removed after merging: %v", err)
+	}
+}
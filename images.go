@@ -0,0 +1,128 @@
+package rtve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// ThumbnailFile returns the name of the file the thumbnail of a video is
+// saved to by DownloadImages, given the extension of the image.
+func ThumbnailFile(videoID, ext string) string {
+	return fmt.Sprintf("thumbnail_%s%s", videoID, ext)
+}
+
+// PosterFile returns the name of the file the poster of a program is saved
+// to by DownloadImages, given the extension of the image.
+func PosterFile(programID, ext string) string {
+	return fmt.Sprintf("poster_%s%s", programID, ext)
+}
+
+// posterFields are the fields of RTVE's program API holding the program's
+// artwork, in order of preference.
+var posterFields = []string{"imgPoster", "imgPortada", "imageSEO", "thumbnail"}
+
+// DownloadImages saves the thumbnail of a video and the poster of its
+// program to outputDir, next to video_<id>.json, and returns the paths of
+// the saved files (see ThumbnailFile and PosterFile). Images RTVE doesn't
+// publish are skipped, and a poster already saved for the program isn't
+// downloaded again.
+func (s *Scrapper) DownloadImages(meta *VideoMetadata, outputDir string) ([]string, error) {
+	return s.DownloadImagesContext(context.Background(), meta, outputDir)
+}
+
+// DownloadImagesContext works like DownloadImages, aborting the requests
+// when ctx is done.
+func (s *Scrapper) DownloadImagesContext(ctx context.Context, meta *VideoMetadata, outputDir string) ([]string, error) {
+	if err := s.checkWritable(outputDir); err != nil {
+		return nil, err
+	}
+
+	full, err := meta.Full()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	var errs []error
+
+	thumbnail := full.Thumbnail
+	if thumbnail == "" {
+		thumbnail = full.ImageSEO
+	}
+	if thumbnail != "" {
+		path := filepath.Join(outputDir, ThumbnailFile(meta.ID, imageExt(thumbnail)))
+		if err := s.saveImage(ctx, thumbnail, path); err != nil {
+			errs = append(errs, fmt.Errorf("error downloading thumbnail for %s: %w", meta.ID, err))
+		} else {
+			paths = append(paths, path)
+		}
+	}
+
+	if full.ProgramInfo.ID != "" && full.ProgramRef != "" {
+		path, err := s.downloadPoster(ctx, full.ProgramInfo.ID, full.ProgramRef, outputDir)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error downloading poster for %s: %w", meta.ID, err))
+		} else if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, errors.Join(errs...)
+}
+
+// downloadPoster saves the poster of a program to outputDir, returning its
+// path, or an empty one if the program has no artwork.
+func (s *Scrapper) downloadPoster(ctx context.Context, programID, programRef, outputDir string) (string, error) {
+	// Episodes of the same day share the program's poster
+	matches, _ := filepath.Glob(filepath.Join(outputDir, PosterFile(programID, ".*")))
+	if len(matches) > 0 {
+		return matches[0], nil
+	}
+
+	body, err := s.get(ctx, programRef+".json")
+	if err != nil {
+		return "", fmt.Errorf("error fetching program: %w", err)
+	}
+
+	var response struct {
+		Page struct {
+			Items []map[string]any `json:"items"`
+		} `json:"page"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return "", fmt.Errorf("error decoding program: %w", err)
+	}
+	if len(response.Page.Items) == 0 {
+		return "", nil
+	}
+
+	for _, field := range posterFields {
+		url, _ := response.Page.Items[0][field].(string)
+		if url == "" {
+			continue
+		}
+		path := filepath.Join(outputDir, PosterFile(programID, imageExt(url)))
+		return path, s.saveImage(ctx, url, path)
+	}
+
+	return "", nil
+}
+
+func (s *Scrapper) saveImage(ctx context.Context, url, path string) error {
+	data, err := s.downloadWithRetry(ctx, url, 3)
+	if err != nil {
+		return err
+	}
+	return s.writeFile(path, data, false)
+}
+
+// imageExt returns the extension of an image URL, ".jpg" if it has none.
+func imageExt(url string) string {
+	if ext := mediaExt(url); ext != "" {
+		return ext
+	}
+	return ".jpg"
+}
@@ -0,0 +1,95 @@
+package rtve
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadImages(t *testing.T) {
+	fixtures := fixtureTransport(t)
+	programRequests := 0
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Path == "/api/programas/135930.json":
+			programRequests++
+			return newResponse(http.StatusOK, `{"page":{"items":[{"id":"135930","imgPoster":"https://img2.rtve.es/p/135930/poster.png"}]}}`), nil
+		case req.URL.Host == "img2.rtve.es":
+			return newResponse(http.StatusOK, "image "+req.URL.Path), nil
+		}
+		return fixtures(req)
+	})
+
+	s := NewScrapper("telediario-2", WithTransport(transport))
+	meta, err := s.DownloadVideoMeta("16492499")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	paths, err := s.DownloadImages(meta, dir)
+	if err != nil {
+		t.Fatalf("DownloadImages failed: %v", err)
+	}
+
+	thumbnail := filepath.Join(dir, "thumbnail_16492499.jpg")
+	poster := filepath.Join(dir, "poster_135930.png")
+	if len(paths) != 2 || paths[0] != thumbnail || paths[1] != poster {
+		t.Fatalf("Unexpected paths: %v", paths)
+	}
+	if data, _ := os.ReadFile(thumbnail); !strings.HasSuffix(string(data), "01741986896773.jpg") {
+		t.Errorf("Unexpected thumbnail content: %q", data)
+	}
+	if data, _ := os.ReadFile(poster); string(data) != "image /p/135930/poster.png" {
+		t.Errorf("Unexpected poster content: %q", data)
+	}
+
+	// The program's poster is only downloaded once per folder
+	if _, err := s.DownloadImages(meta, dir); err != nil {
+		t.Fatal(err)
+	}
+	if programRequests != 1 {
+		t.Errorf("Expected 1 program request, got %d", programRequests)
+	}
+
+	if _, err := s.DownloadImages(&VideoMetadata{ID: "1"}, dir); err == nil {
+		t.Error("Expected an error for metadata without raw fields")
+	}
+}
+
+func TestScrapeVideosImagesForArchivedVideos(t *testing.T) {
+	fixtures := fixtureTransport(t)
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Path == "/api/programas/135930.json":
+			return newResponse(http.StatusOK, `{"page":{"items":[{"id":"135930","imgPoster":"https://img2.rtve.es/p/135930/poster.png"}]}}`), nil
+		case req.URL.Host == "img2.rtve.es":
+			return newResponse(http.StatusOK, "image "+req.URL.Path), nil
+		}
+		return fixtures(req)
+	})
+
+	// Archived without images
+	dir := t.TempDir()
+	s := NewScrapper("telediario-2", WithOutputPath(dir), WithTransport(transport))
+	if _, errs := s.ScrapeVideos([]string{"16492499"}); len(errs) > 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	folder := filepath.Join(dir, "2025", "2025-03-14")
+	if _, err := os.Stat(filepath.Join(folder, "thumbnail_16492499.jpg")); !os.IsNotExist(err) {
+		t.Fatalf("Expected no thumbnail without images, got %v", err)
+	}
+
+	withImages := NewScrapper("telediario-2", WithOutputPath(dir), WithTransport(transport), WithImages(true))
+	downloaded, errs := withImages.ScrapeVideos([]string{"16492499"})
+	if downloaded != 0 || len(errs) > 0 {
+		t.Fatalf("Expected no new videos, got %d, %v", downloaded, errs)
+	}
+	for _, name := range []string{"thumbnail_16492499.jpg", "poster_135930.png"} {
+		if _, err := os.Stat(filepath.Join(folder, name)); err != nil {
+			t.Errorf("Expected %s for the archived video: %v", name, err)
+		}
+	}
+}
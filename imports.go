@@ -0,0 +1,150 @@
+package rtve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ImportsFile is the name of the file, stored next to video_<id>.json, that
+// records the provenance of every file imported into an episode folder.
+const ImportsFile = "imports.json"
+
+// ImportRecord describes an externally obtained subtitle or transcript file
+// attached to an archived episode.
+type ImportRecord struct {
+	// File is the path of the imported copy, relative to the episode folder
+	File string `json:"file"`
+	// Lang is the language code of the file, if known
+	Lang string `json:"lang,omitempty"`
+	// Source is a free-form description of where the file came from,
+	// e.g. "manual correction" or a URL
+	Source string `json:"source,omitempty"`
+	// OriginalName is the base name of the file that was imported
+	OriginalName string `json:"originalName"`
+	// ImportedAt is when the file was imported
+	ImportedAt time.Time `json:"importedAt"`
+}
+
+// languageCodePattern matches the language codes imported files are saved
+// with, e.g. "es", "und" or "es-ES".
+var languageCodePattern = regexp.MustCompile(`^[A-Za-z]{2,3}([-_][A-Za-z0-9]{2,8})*$`)
+
+// ImportFile copies an externally obtained subtitle or transcript file into
+// the folder of an episode already archived under root, and records its
+// provenance in the episode's imports.json. See Scrapper.ImportFile.
+func ImportFile(root, videoID, path, lang, source string) (*ImportRecord, error) {
+	return NewScrapper("", WithOutputPath(root)).ImportFile(videoID, path, lang, source)
+}
+
+// ImportFile copies an externally obtained subtitle or transcript file into
+// the folder of an episode already archived in the output path, and records
+// its provenance in the episode's imports.json. Files are written following
+// the Scrapper's durability policy, and not at all WithReadOnly.
+//
+// The copy is saved as subs/<id>_<lang>.imported<ext> so it never replaces
+// the subtitles downloaded from RTVE. lang must be a language code, e.g.
+// "es" or "es-ES"; an empty one is saved as "und". Updates to imports.json
+// are serialized with a lock file next to it, so concurrent imports are
+// safe.
+func (s *Scrapper) ImportFile(videoID, path, lang, source string) (*ImportRecord, error) {
+	folder := FindVideoFolder(s.outputPath, videoID)
+	if folder == "" {
+		return nil, fmt.Errorf("video %s not found in %s", videoID, s.outputPath)
+	}
+
+	if lang == "" {
+		lang = "und"
+	}
+	if !languageCodePattern.MatchString(lang) {
+		return nil, fmt.Errorf("invalid language code %q", lang)
+	}
+
+	subsDir := filepath.Join(folder, "subs")
+	name := fmt.Sprintf("%s_%s.imported%s", videoID, lang, strings.ToLower(filepath.Ext(path)))
+	dest := filepath.Join(subsDir, name)
+	if rel, err := filepath.Rel(subsDir, dest); err != nil || rel != name {
+		return nil, fmt.Errorf("invalid file name %q for imported file", name)
+	}
+
+	if err := s.checkWritable(folder); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file to import: %w", err)
+	}
+
+	if err := os.MkdirAll(subsDir, 0755); err != nil {
+		return nil, fmt.Errorf("creating subs directory: %w", err)
+	}
+
+	if err := s.writeFile(dest, data, false); err != nil {
+		return nil, fmt.Errorf("writing imported file: %w", err)
+	}
+
+	record := &ImportRecord{
+		File:         filepath.Join("subs", name),
+		Lang:         lang,
+		Source:       source,
+		OriginalName: filepath.Base(path),
+		ImportedAt:   time.Now().UTC(),
+	}
+
+	// Concurrent imports into the same episode must not lose records
+	lock, err := Lock(filepath.Join(folder, ImportsFile+".lock"), true)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	records, err := ReadImports(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	// Re-importing the same file replaces its previous record
+	kept := records[:0]
+	for _, r := range records {
+		if r.File != record.File {
+			kept = append(kept, r)
+		}
+	}
+	kept = append(kept, *record)
+
+	jsonData, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling import records: %w", err)
+	}
+
+	if err := s.writeFile(filepath.Join(folder, ImportsFile), jsonData, true); err != nil {
+		return nil, fmt.Errorf("writing import records: %w", err)
+	}
+
+	return record, nil
+}
+
+// ReadImports returns the import records of the episode stored in folder.
+// It returns an empty slice if nothing has been imported.
+func ReadImports(folder string) ([]ImportRecord, error) {
+	data, err := os.ReadFile(filepath.Join(folder, ImportsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return []ImportRecord{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading import records: %w", err)
+	}
+
+	var records []ImportRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("parsing import records: %w", err)
+	}
+
+	return records, nil
+}
@@ -0,0 +1,106 @@
+package rtve
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportFile(t *testing.T) {
+	root := t.TempDir()
+	folder := filepath.Join(root, "2025", "2025-03-14")
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "video_16492499.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(t.TempDir(), "corrected.VTT")
+	content := "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nHola\n"
+	if err := os.WriteFile(src, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := ImportFile(root, "16492499", src, "es", "manual correction")
+	if err != nil {
+		t.Fatalf("Failed to import file: %v", err)
+	}
+
+	if record.File != filepath.Join("subs", "16492499_es.imported.vtt") {
+		t.Errorf("Unexpected imported file path: %s", record.File)
+	}
+
+	data, err := os.ReadFile(filepath.Join(folder, record.File))
+	if err != nil {
+		t.Fatalf("Imported file not found: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("Imported file content mismatch: %q", data)
+	}
+
+	// Import again to check the record is replaced, not duplicated
+	if _, err := ImportFile(root, "16492499", src, "es", "second pass"); err != nil {
+		t.Fatalf("Failed to re-import file: %v", err)
+	}
+
+	records, err := ReadImports(folder)
+	if err != nil {
+		t.Fatalf("Failed to read import records: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("Expected 1 import record, got %d", len(records))
+	}
+	if records[0].Source != "second pass" || records[0].OriginalName != "corrected.VTT" {
+		t.Errorf("Unexpected import record: %+v", records[0])
+	}
+}
+
+func TestImportFileInvalidLanguage(t *testing.T) {
+	root := t.TempDir()
+	folder := filepath.Join(root, "2025", "2025-03-14")
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "video_16492499.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(t.TempDir(), "corrected.vtt")
+	if err := os.WriteFile(src, []byte("WEBVTT\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, lang := range []string{"../../x", "es/../..", `es\x`, "es.v1"} {
+		if _, err := ImportFile(root, "16492499", src, lang, ""); err == nil {
+			t.Errorf("Expected an error importing with language %q", lang)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "x.imported.vtt")); !os.IsNotExist(err) {
+		t.Error("Imported file written outside the subs folder")
+	}
+
+	s := NewScrapper("", WithOutputPath(root), WithReadOnly(true))
+	if _, err := s.ImportFile("16492499", src, "es", ""); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "subs")); !os.IsNotExist(err) {
+		t.Error("Subs folder written in read-only mode")
+	}
+}
+
+func TestImportFileUnknownVideo(t *testing.T) {
+	if _, err := ImportFile(t.TempDir(), "123", "missing.vtt", "es", ""); err == nil {
+		t.Error("Expected error importing into a video that isn't archived")
+	}
+}
+
+func TestReadImportsEmpty(t *testing.T) {
+	records, err := ReadImports(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 0 {
+		t.Errorf("Expected no records, got %d", len(records))
+	}
+}
@@ -0,0 +1,186 @@
+package rtve
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// VideoFolder returns the folder under root that videos published at
+// pubDate are saved to: root/YYYY/YYYY-MM-DD, or root/YYYY/MM/YYYY-MM-DD
+// when monthShards is set.
+func VideoFolder(root string, pubDate time.Time, monthShards bool) string {
+	year := filepath.Join(root, pubDate.Format(YearFolderLayout))
+	if monthShards {
+		year = filepath.Join(year, pubDate.Format(MonthFolderLayout))
+	}
+	return filepath.Join(year, pubDate.Format(DayFolderLayout))
+}
+
+// FormatLayout returns the folder, relative to the archive root, that
+// template places a video in. Templates are slash separated and may use
+// these placeholders:
+//
+//	{year}, {month}, {date}  publication date, e.g. 2025, 03, 2025-03-14
+//	{id}                     video ID
+//	{slug}                   title slug, see VideoMetadata.Slug
+//	{unique-slug}            title slug with the video ID, see UniqueSlug
+//	{season}                 two digit season number, e.g. 02
+//	{episode}                episode code, e.g. S02E05, see EpisodeCode
+//
+// The season and episode placeholders are empty for videos RTVE doesn't
+// number, e.g. "{slug}/{season}" saves those right in the slug folder.
+//
+// Files in the folder are named after the video ID, so videos placed in
+// the same folder, such as same-title episodes in "{slug}", don't clash.
+// Dashes, underscores, dots and spaces around each folder name are trimmed
+// and empty folder names dropped, so "{year}/{slug}" works for untitled
+// videos too and the result never escapes the archive root.
+func FormatLayout(template string, meta *VideoMetadata) (string, error) {
+	pubDate, err := ParseRTVEDate(meta.PublicationDate)
+	if err != nil {
+		return "", err
+	}
+
+	var season string
+	if meta.Season > 0 {
+		season = fmt.Sprintf("%02d", meta.Season)
+	}
+
+	replacer := strings.NewReplacer(
+		"{year}", pubDate.Format(YearFolderLayout),
+		"{month}", pubDate.Format(MonthFolderLayout),
+		"{date}", pubDate.Format(DayFolderLayout),
+		"{id}", meta.ID,
+		"{slug}", meta.Slug(),
+		"{unique-slug}", meta.UniqueSlug(),
+		"{season}", season,
+		"{episode}", meta.EpisodeCode(),
+	)
+
+	var folders []string
+	for _, name := range strings.Split(replacer.Replace(template), "/") {
+		if name = strings.Trim(name, "-_. "); name != "" {
+			folders = append(folders, name)
+		}
+	}
+	if len(folders) == 0 {
+		return "", fmt.Errorf("layout %q gives no folder for video %s", template, meta.ID)
+	}
+
+	return filepath.Join(folders...), nil
+}
+
+// MigrateLayout moves the day folders of an archive between the default
+// layout and the month sharded one (see VideoFolder), returning the number
+// of day folders moved. Folders already in the target layout are left
+// alone, so an interrupted migration can simply be run again. Archives
+// saved with a custom layout (see WithLayout) aren't migrated.
+func MigrateLayout(root string, monthShards bool) (int, error) {
+	years, err := os.ReadDir(root)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, year := range years {
+		if !year.IsDir() || !isLayoutFolder(year.Name(), YearFolderLayout) {
+			continue
+		}
+		yearDir := filepath.Join(root, year.Name())
+
+		var n int
+		if monthShards {
+			n, err = shardYear(yearDir)
+		} else {
+			n, err = unshardYear(yearDir)
+		}
+		moved += n
+		if err != nil {
+			return moved, err
+		}
+	}
+
+	return moved, nil
+}
+
+// shardYear moves yearDir/YYYY-MM-DD folders to yearDir/MM/YYYY-MM-DD.
+func shardYear(yearDir string) (int, error) {
+	entries, err := os.ReadDir(yearDir)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		day, err := time.Parse(DayFolderLayout, e.Name())
+		if err != nil {
+			continue
+		}
+
+		monthDir := filepath.Join(yearDir, day.Format(MonthFolderLayout))
+		if err := os.MkdirAll(monthDir, 0755); err != nil {
+			return moved, err
+		}
+		if err := moveFolder(filepath.Join(yearDir, e.Name()), filepath.Join(monthDir, e.Name())); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+
+	return moved, nil
+}
+
+// unshardYear moves yearDir/MM/YYYY-MM-DD folders to yearDir/YYYY-MM-DD and
+// removes the emptied month folders.
+func unshardYear(yearDir string) (int, error) {
+	months, err := os.ReadDir(yearDir)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, month := range months {
+		if !month.IsDir() || !isLayoutFolder(month.Name(), MonthFolderLayout) {
+			continue
+		}
+		monthDir := filepath.Join(yearDir, month.Name())
+
+		days, err := os.ReadDir(monthDir)
+		if err != nil {
+			return moved, err
+		}
+		for _, day := range days {
+			if !day.IsDir() || !isLayoutFolder(day.Name(), DayFolderLayout) {
+				continue
+			}
+			if err := moveFolder(filepath.Join(monthDir, day.Name()), filepath.Join(yearDir, day.Name())); err != nil {
+				return moved, err
+			}
+			moved++
+		}
+
+		// Only succeeds if nothing else was stored in the month folder
+		os.Remove(monthDir)
+	}
+
+	return moved, nil
+}
+
+func moveFolder(from, to string) error {
+	if _, err := os.Stat(to); err == nil {
+		return fmt.Errorf("cannot move %s: %s already exists", from, to)
+	}
+	return os.Rename(from, to)
+}
+
+// isLayoutFolder reports whether name is a folder name produced by layout.
+func isLayoutFolder(name, layout string) bool {
+	t, err := time.Parse(layout, name)
+	return err == nil && t.Format(layout) == name
+}
@@ -0,0 +1,132 @@
+package rtve
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVideoFolder(t *testing.T) {
+	pubDate := time.Date(2025, 3, 14, 21, 0, 0, 0, time.UTC)
+
+	if got := VideoFolder("root", pubDate, false); got != filepath.Join("root", "2025", "2025-03-14") {
+		t.Errorf("Unexpected folder %s", got)
+	}
+
+	if got := VideoFolder("root", pubDate, true); got != filepath.Join("root", "2025", "03", "2025-03-14") {
+		t.Errorf("Unexpected sharded folder %s", got)
+	}
+}
+
+func TestFormatLayout(t *testing.T) {
+	meta := &VideoMetadata{ID: "16492499", LongTitle: "Informe Semanal: ¿Qué pasó?", PublicationDate: "14-03-2025 21:00:00"}
+
+	tests := map[string]string{
+		"{year}/{month}/{date}": filepath.Join("2025", "03", "2025-03-14"),
+		"{slug}":                "informe-semanal-que-paso",
+		"{year}/{unique-slug}":  filepath.Join("2025", "informe-semanal-que-paso-16492499"),
+		"/../{slug}/./{id}":     filepath.Join("informe-semanal-que-paso", "16492499"),
+		"shows/{slug}-{date}/":  filepath.Join("shows", "informe-semanal-que-paso-2025-03-14"),
+	}
+	for template, expected := range tests {
+		got, err := FormatLayout(template, meta)
+		if err != nil || got != expected {
+			t.Errorf("FormatLayout(%q) = %q, %v, expected %q", template, got, err, expected)
+		}
+	}
+
+	numbered := *meta
+	numbered.Season, numbered.Episode = 2, 5
+	for template, expected := range map[string]string{
+		"{season}/{episode}-{slug}": filepath.Join("02", "S02E05-informe-semanal-que-paso"),
+		"{slug}/{season}":           "informe-semanal-que-paso",
+	} {
+		m := &numbered
+		if !strings.Contains(template, "episode") {
+			m = meta
+		}
+		got, err := FormatLayout(template, m)
+		if err != nil || got != expected {
+			t.Errorf("FormatLayout(%q) = %q, %v, expected %q", template, got, err, expected)
+		}
+	}
+
+	if _, err := FormatLayout("/..", meta); err == nil {
+		t.Error("Expected an error for a layout without folders")
+	}
+	if _, err := FormatLayout("{slug}", &VideoMetadata{ID: "1"}); err == nil {
+		t.Error("Expected an error for a video without publication date")
+	}
+
+	s := NewScrapper("informe-semanal", WithOutputPath("root"), WithLayout("{year}/{slug}"), WithMonthShards(true))
+	folder, err := s.folderForVideo(meta)
+	if err != nil || folder != filepath.Join("root", "2025", "informe-semanal-que-paso") {
+		t.Errorf("Unexpected folder with a layout: %q, %v", folder, err)
+	}
+}
+
+func TestMigrateLayout(t *testing.T) {
+	root := t.TempDir()
+	days := []string{"2024-12-31", "2025-03-14", "2025-03-15"}
+	for _, day := range days {
+		dir := filepath.Join(root, day[:4], day)
+		if err := os.MkdirAll(filepath.Join(dir, "subs"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "video_1.json"), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	moved, err := MigrateLayout(root, true)
+	if err != nil {
+		t.Fatalf("MigrateLayout failed: %v", err)
+	}
+	if moved != len(days) {
+		t.Errorf("Expected %d folders moved, got %d", len(days), moved)
+	}
+
+	for _, day := range days {
+		if _, err := os.Stat(filepath.Join(root, day[:4], day[5:7], day, "video_1.json")); err != nil {
+			t.Errorf("Day %s not sharded: %v", day, err)
+		}
+	}
+
+	// Running it again is a no-op
+	if moved, err := MigrateLayout(root, true); err != nil || moved != 0 {
+		t.Errorf("Expected no-op, got %d moved, err %v", moved, err)
+	}
+
+	moved, err = MigrateLayout(root, false)
+	if err != nil {
+		t.Fatalf("MigrateLayout failed: %v", err)
+	}
+	if moved != len(days) {
+		t.Errorf("Expected %d folders moved back, got %d", len(days), moved)
+	}
+
+	for _, day := range days {
+		if _, err := os.Stat(filepath.Join(root, day[:4], day, "video_1.json")); err != nil {
+			t.Errorf("Day %s not moved back: %v", day, err)
+		}
+		if _, err := os.Stat(filepath.Join(root, day[:4], day[5:7])); !os.IsNotExist(err) {
+			t.Errorf("Month folder for %s not removed", day)
+		}
+	}
+}
+
+func TestScrapeVideosMonthShards(t *testing.T) {
+	dir := t.TempDir()
+	s := NewScrapper("telediario-2", WithOutputPath(dir), WithMonthShards(true))
+	s.client.Transport = fixtureTransport(t)
+
+	if _, errs := s.ScrapeVideos([]string{"16492499"}); len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "2025", "03", "2025-03-14", "video_16492499.json")); err != nil {
+		t.Errorf("Metadata file not saved to the sharded folder: %v", err)
+	}
+}
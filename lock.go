@@ -0,0 +1,73 @@
+package rtve
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ArchiveLockFile is the name of the lock file kept at the root of an
+// archive, see LockArchive.
+const ArchiveLockFile = ".rtve-subs.lock"
+
+// ErrLocked is returned by TryLock when the lock is held by another
+// process, or by another FileLock in the same process.
+var ErrLocked = errors.New("locked")
+
+// FileLock is an advisory lock held on a lock file. It uses flock on
+// Linux and macOS and file sharing modes on Windows, so it's released
+// automatically if the process dies. Processes that don't take the lock
+// aren't prevented from accessing the files it protects.
+type FileLock struct {
+	f *os.File
+}
+
+// lockRetryInterval is how often Lock retries on platforms that can't
+// block waiting for a lock.
+const lockRetryInterval = 50 * time.Millisecond
+
+// Lock acquires the lock on path, creating the file if needed, and waits
+// until it's available. Shared locks can be held by many processes at
+// once; an exclusive lock can't be held together with any other lock.
+func Lock(path string, exclusive bool) (*FileLock, error) {
+	f, err := openLockFile(path, exclusive, true)
+	if err != nil {
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	return &FileLock{f: f}, nil
+}
+
+// TryLock works like Lock, but fails with ErrLocked instead of waiting if
+// the lock isn't available.
+func TryLock(path string, exclusive bool) (*FileLock, error) {
+	f, err := openLockFile(path, exclusive, false)
+	if err != nil {
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	return &FileLock{f: f}, nil
+}
+
+// Unlock releases the lock. The lock file is left in place, removing it
+// would race with processes waiting for it.
+func (l *FileLock) Unlock() error {
+	return l.f.Close()
+}
+
+// LockArchive locks the archive at root. Commands that add to the archive
+// take a shared lock, so several of them can run at the same time, while
+// commands that reorganize it, like MigrateLayout, need an exclusive one.
+// It fails with ErrLocked if the archive is locked in an incompatible
+// mode, rather than waiting.
+func LockArchive(root string, exclusive bool) (*FileLock, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+
+	lock, err := TryLock(filepath.Join(root, ArchiveLockFile), exclusive)
+	if errors.Is(err, ErrLocked) {
+		return nil, fmt.Errorf("archive %s is in use by another process: %w", root, ErrLocked)
+	}
+	return lock, err
+}
@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package rtve
+
+import "os"
+
+// openLockFile doesn't lock anything on platforms without file locking.
+func openLockFile(path string, exclusive, block bool) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+}
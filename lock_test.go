@@ -0,0 +1,70 @@
+package rtve
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockArchive(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "archive")
+
+	shared1, err := LockArchive(root, false)
+	if err != nil {
+		t.Fatalf("First shared lock failed: %v", err)
+	}
+	shared2, err := LockArchive(root, false)
+	if err != nil {
+		t.Fatalf("Shared locks should not exclude each other: %v", err)
+	}
+
+	if _, err := LockArchive(root, true); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked for an exclusive lock while shared ones are held, got %v", err)
+	}
+
+	shared1.Unlock()
+	shared2.Unlock()
+
+	exclusive, err := LockArchive(root, true)
+	if err != nil {
+		t.Fatalf("Exclusive lock failed once shared locks were released: %v", err)
+	}
+	if _, err := LockArchive(root, false); !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked for a shared lock while an exclusive one is held, got %v", err)
+	}
+	exclusive.Unlock()
+}
+
+func TestLockWaits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+
+	held, err := Lock(path, true)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	acquired := make(chan *FileLock)
+	go func() {
+		lock, err := Lock(path, true)
+		if err != nil {
+			t.Errorf("Waiting Lock failed: %v", err)
+		}
+		acquired <- lock
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Lock acquired while held elsewhere")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	held.Unlock()
+
+	select {
+	case lock := <-acquired:
+		lock.Unlock()
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lock not acquired after release")
+	}
+}
@@ -0,0 +1,42 @@
+//go:build windows
+
+package rtve
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+const errorSharingViolation syscall.Errno = 32
+
+// openLockFile locks path through its sharing mode: shared locks allow
+// other readers, exclusive locks allow no other handle at all.
+func openLockFile(path string, exclusive, block bool) (*os.File, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	access := uint32(syscall.GENERIC_READ)
+	share := uint32(syscall.FILE_SHARE_READ)
+	if exclusive {
+		access |= syscall.GENERIC_WRITE
+		share = 0
+	}
+
+	for {
+		h, err := syscall.CreateFile(name, access, share, nil, syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+		if err == nil {
+			return os.NewFile(uintptr(h), path), nil
+		}
+		if !errors.Is(err, errorSharingViolation) {
+			return nil, err
+		}
+		if !block {
+			return nil, ErrLocked
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
@@ -0,0 +1,488 @@
+package rtve
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// MediaSource is a URL a video can be downloaded from, as published by
+// RTVE's ztnr endpoint.
+type MediaSource struct {
+	// Quality is RTVE's name for the encoding, e.g. "HD_FULL" or "Alta"
+	Quality string
+	// URL is the media URL, a progressive MP4 or an HLS/DASH manifest
+	URL string
+}
+
+// HLS reports whether the source is an HLS playlist rather than a
+// progressive file.
+func (m MediaSource) HLS() bool {
+	return mediaExt(m.URL) == ".m3u8"
+}
+
+// Progressive reports whether the source is a single downloadable file,
+// rather than a streaming manifest.
+func (m MediaSource) Progressive() bool {
+	switch mediaExt(m.URL) {
+	case ".m3u8", ".mpd":
+		return false
+	}
+	return true
+}
+
+func mediaExt(mediaURL string) string {
+	if i := strings.IndexAny(mediaURL, "?#"); i >= 0 {
+		mediaURL = mediaURL[:i]
+	}
+	return strings.ToLower(filepath.Ext(mediaURL))
+}
+
+// mediaQualities ranks RTVE quality names from worst to best.
+var mediaQualities = []string{"Media", "Alta", "HQ", "HD_READY", "HD_FULL"}
+
+func qualityRank(quality string) int {
+	for i, q := range mediaQualities {
+		if strings.EqualFold(q, quality) {
+			return i
+		}
+	}
+	return -1
+}
+
+// ErrNoMedia is returned when RTVE publishes no downloadable media for a
+// video.
+var ErrNoMedia = errors.New("no downloadable media")
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// DecodeMediaPNG extracts the media sources hidden in a ztnr PNG. RTVE
+// doesn't publish media URLs in the video metadata; the ztnr endpoint
+// answers with an image, usually base64 encoded, whose tEXt chunks hold
+// an obfuscated alphabet and the URL as indexes into it.
+func DecodeMediaPNG(data []byte) ([]MediaSource, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid media PNG: %w", err)
+		}
+		data = decoded
+	}
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errors.New("invalid media PNG: missing PNG signature")
+	}
+
+	var sources []MediaSource
+	for rest := data[len(pngSignature):]; len(rest) > 0; {
+		if len(rest) < 12 {
+			return nil, errors.New("invalid media PNG: truncated chunk")
+		}
+		length := binary.BigEndian.Uint32(rest)
+		chunkType := string(rest[4:8])
+		if uint64(length)+12 > uint64(len(rest)) {
+			return nil, errors.New("invalid media PNG: truncated chunk")
+		}
+		chunk := rest[8 : 8+length]
+		rest = rest[12+length:]
+
+		if chunkType == "IEND" {
+			break
+		}
+		if chunkType != "tEXt" {
+			continue
+		}
+
+		source, err := decodeMediaText(chunk)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+// decodeMediaText decodes a tEXt chunk of the form
+// alphabet \0 quality %% digits.
+func decodeMediaText(chunk []byte) (MediaSource, error) {
+	alphabetData, text, ok := bytes.Cut(chunk, []byte{0})
+	if !ok {
+		return MediaSource{}, errors.New("invalid media PNG: malformed text chunk")
+	}
+	quality, urlData, ok := bytes.Cut(text, []byte("%%"))
+	if !ok {
+		return MediaSource{}, errors.New("invalid media PNG: malformed text chunk")
+	}
+
+	// Alphabet characters are separated by 1, 2, 3 and 0 padding bytes
+	var alphabet []byte
+	for i, skip, pad := 0, 0, 0; i < len(alphabetData); i++ {
+		if skip > 0 {
+			skip--
+			continue
+		}
+		alphabet = append(alphabet, alphabetData[i])
+		pad = (pad + 1) % 4
+		skip = pad
+	}
+
+	// Each URL character is a two digit alphabet index, with padding
+	// between the digits
+	var url strings.Builder
+	tens, pending := 0, false
+	skip, n := 3, 1
+	for _, c := range urlData {
+		if !pending {
+			if c < '0' || c > '9' {
+				return MediaSource{}, errors.New("invalid media PNG: malformed URL data")
+			}
+			tens = int(c-'0') * 10
+			pending = true
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if c < '0' || c > '9' {
+			return MediaSource{}, errors.New("invalid media PNG: malformed URL data")
+		}
+		index := tens + int(c-'0')
+		if index >= len(alphabet) {
+			return MediaSource{}, errors.New("invalid media PNG: URL index out of range")
+		}
+		url.WriteByte(alphabet[index])
+		skip = (n + 3) % 4
+		pending = false
+		n++
+	}
+
+	return MediaSource{Quality: string(quality), URL: url.String()}, nil
+}
+
+// ResolveMedia returns the media sources RTVE publishes for a video.
+func (s *Scrapper) ResolveMedia(videoID string) ([]MediaSource, error) {
+	return s.ResolveMediaContext(context.Background(), videoID)
+}
+
+// ResolveMediaContext works like ResolveMedia, aborting the request when
+// ctx is done.
+func (s *Scrapper) ResolveMediaContext(ctx context.Context, videoID string) ([]MediaSource, error) {
+	body, err := s.get(ctx, fmt.Sprintf(MediaURL, videoID))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving media for video %s: %w", videoID, err)
+	}
+
+	sources, err := DecodeMediaPNG([]byte(body))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving media for video %s: %w", videoID, err)
+	}
+
+	return sources, nil
+}
+
+// bestMedia returns the highest quality source for which keep returns
+// true, or false if there's none.
+func bestMedia(sources []MediaSource, keep func(MediaSource) bool) (MediaSource, bool) {
+	var best MediaSource
+	found := false
+	for _, source := range sources {
+		if !keep(source) {
+			continue
+		}
+		if !found || qualityRank(source.Quality) > qualityRank(best.Quality) {
+			best, found = source, true
+		}
+	}
+	return best, found
+}
+
+// lowestMedia returns the lowest quality source for which keep returns
+// true, or false if there's none.
+func lowestMedia(sources []MediaSource, keep func(MediaSource) bool) (MediaSource, bool) {
+	var lowest MediaSource
+	found := false
+	for _, source := range sources {
+		if !keep(source) {
+			continue
+		}
+		if !found || qualityRank(source.Quality) < qualityRank(lowest.Quality) {
+			lowest, found = source, true
+		}
+	}
+	return lowest, found
+}
+
+// VideoFile returns the name of the file the media of a video is saved to,
+// given its extension: ".mp4" for progressive downloads and ".ts" for HLS
+// streams.
+func VideoFile(videoID, ext string) string {
+	return fmt.Sprintf("video_%s%s", videoID, ext)
+}
+
+// AudioFile returns the name of the file the audio track of a video is
+// saved to when only the audio is kept, given its extension, e.g. ".m4a".
+func AudioFile(videoID, ext string) string {
+	return fmt.Sprintf("audio_%s%s", videoID, ext)
+}
+
+// DownloadVideo downloads the media of a video and saves it to outputDir,
+// returning the path of the saved file. The best quality progressive MP4
+// is preferred; videos only published as HLS streams are saved as a
+// single MPEG-TS file merged from the stream segments.
+func (s *Scrapper) DownloadVideo(meta *VideoMetadata, outputDir string) (string, error) {
+	return s.DownloadVideoContext(context.Background(), meta, outputDir)
+}
+
+// DownloadVideoContext works like DownloadVideo, stopping once ctx is done.
+func (s *Scrapper) DownloadVideoContext(ctx context.Context, meta *VideoMetadata, outputDir string) (string, error) {
+	if err := s.checkWritable(outputDir); err != nil {
+		return "", err
+	}
+
+	sources, err := s.ResolveMediaContext(ctx, meta.ID)
+	if err != nil {
+		return "", geoBlockedError(meta, err)
+	}
+
+	var path string
+	if source, ok := bestMedia(sources, MediaSource.Progressive); ok {
+		path = filepath.Join(outputDir, VideoFile(meta.ID, ".mp4"))
+		err = s.downloadMedia(ctx, source.URL, path)
+		if err != nil {
+			err = fmt.Errorf("error downloading %s video for %s: %w", source.Quality, meta.ID, err)
+		}
+	} else if source, ok := bestMedia(sources, MediaSource.HLS); ok {
+		path = filepath.Join(outputDir, VideoFile(meta.ID, ".ts"))
+		err = s.downloadHLS(ctx, source.URL, path)
+		if err != nil {
+			err = fmt.Errorf("error downloading %s stream for %s: %w", source.Quality, meta.ID, err)
+		}
+	} else {
+		return "", fmt.Errorf("%w for video %s", ErrNoMedia, meta.ID)
+	}
+
+	if err != nil {
+		return "", geoBlockedError(meta, err)
+	}
+
+	return path, nil
+}
+
+// DownloadAudioTrack downloads just the audio track of a video and saves
+// it to outputDir as AudioFile in f.Audio format, M4A if empty, returning
+// its path. RTVE doesn't publish audio-only files for TV programs, so the
+// smallest download holding the audio is used: the audio rendition of the
+// video's HLS stream if it has one, or else its lowest quality variant or
+// progressive file. ffmpeg then extracts the audio locally and the
+// download is removed.
+func (s *Scrapper) DownloadAudioTrack(meta *VideoMetadata, outputDir string, f *FFmpeg) (string, error) {
+	return s.DownloadAudioTrackContext(context.Background(), meta, outputDir, f)
+}
+
+// DownloadAudioTrackContext works like DownloadAudioTrack, stopping once
+// ctx is done.
+func (s *Scrapper) DownloadAudioTrackContext(ctx context.Context, meta *VideoMetadata, outputDir string, f *FFmpeg) (string, error) {
+	if err := s.checkWritable(outputDir); err != nil {
+		return "", err
+	}
+
+	extractor := *f
+	if extractor.Audio == "" {
+		extractor.Audio = "m4a"
+	}
+	if _, ok := audioCodecs[extractor.Audio]; !ok {
+		return "", fmt.Errorf("unsupported audio format %q", extractor.Audio)
+	}
+
+	sources, err := s.ResolveMediaContext(ctx, meta.ID)
+	if err != nil {
+		return "", geoBlockedError(meta, err)
+	}
+
+	// The download is kept if ffmpeg fails, for the next attempt to reuse
+	var path string
+	if source, ok := bestMedia(sources, MediaSource.HLS); ok {
+		path = filepath.Join(outputDir, AudioFile(meta.ID, ".source.ts"))
+		if !fileExists(path) {
+			err = s.downloadHLSAudio(ctx, source.URL, path)
+		}
+		if err != nil {
+			err = fmt.Errorf("error downloading %s stream audio for %s: %w", source.Quality, meta.ID, err)
+		}
+	} else if source, ok := lowestMedia(sources, MediaSource.Progressive); ok {
+		path = filepath.Join(outputDir, AudioFile(meta.ID, ".source.mp4"))
+		if !fileExists(path) {
+			err = s.downloadMedia(ctx, source.URL, path)
+		}
+		if err != nil {
+			err = fmt.Errorf("error downloading %s video audio for %s: %w", source.Quality, meta.ID, err)
+		}
+	} else {
+		return "", fmt.Errorf("%w for video %s", ErrNoMedia, meta.ID)
+	}
+
+	if err != nil {
+		return "", geoBlockedError(meta, err)
+	}
+
+	return extractor.extractAudio(ctx, meta, path)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// downloadMedia streams url to path. The file is written to path.part and
+// renamed into place once complete. An interrupted download leaves the
+// .part file behind, and the next attempt resumes it with a Range request.
+func (s *Scrapper) downloadMedia(ctx context.Context, url, path string) error {
+	part := path + ".part"
+
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	if err := s.limiter.wait(ctx); err != nil {
+		return err
+	}
+
+	req, err := s.newRequest(ctx, url, "")
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	// Videos take far longer than the client timeout, rely on ctx instead
+	client := *s.client
+	client.Timeout = 0
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error executing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return fmt.Errorf("unexpected Content-Range %q resuming at byte %d", resp.Header.Get("Content-Range"), offset)
+		}
+		if s.verbose {
+			fmt.Printf("Resuming download of %s at byte %d\n", filepath.Base(path), offset)
+		}
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The previous attempt got the whole file but wasn't renamed, unless
+		// the .part doesn't match the file's size, e.g. RTVE replaced it
+		if total, ok := contentRangeTotal(resp.Header.Get("Content-Range")); ok && total == offset {
+			return os.Rename(part, path)
+		}
+		if s.verbose {
+			fmt.Printf("Restarting download of %s, the partial download doesn't match it\n", filepath.Base(path))
+		}
+		resp.Body.Close()
+		if err := os.Remove(part); err != nil {
+			return err
+		}
+		return s.downloadMedia(ctx, url, path)
+	case resp.StatusCode == http.StatusOK:
+		// No partial download, or the server ignored the range
+		flags |= os.O_TRUNC
+	case resp.StatusCode == http.StatusForbidden:
+		return &StatusError{URL: url, StatusCode: resp.StatusCode, Err: ErrForbidden}
+	default:
+		return &StatusError{URL: url, StatusCode: resp.StatusCode, Err: fmt.Errorf("unexpected status code: %d", resp.StatusCode)}
+	}
+
+	f, err := os.OpenFile(part, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	// Keep whatever arrived, even on errors, for the next attempt to resume
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return fmt.Errorf("error reading response body: %w", err)
+	}
+	if s.durability == DurabilityAll {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(part, path)
+}
+
+// contentRangeStart returns the first byte position of a Content-Range
+// header such as "bytes 100-199/200".
+func contentRangeStart(value string) (int64, bool) {
+	rest, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, false
+	}
+	start, _, ok := strings.Cut(rest, "-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(start, 10, 64)
+	return n, err == nil
+}
+
+// contentRangeTotal returns the complete length of a Content-Range header
+// such as "bytes */200", as sent with 416 responses.
+func contentRangeTotal(value string) (int64, bool) {
+	_, total, ok := strings.Cut(value, "/")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(total, 10, 64)
+	return n, err == nil
+}
+
+// writeMediaFile creates path with the data write produces, going through
+// a .part file that's renamed into place once write succeeds.
+func (s *Scrapper) writeMediaFile(path string, write func(w io.Writer) error) error {
+	part := path + ".part"
+	f, err := os.Create(part)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(part)
+		return err
+	}
+	if s.durability == DurabilityAll {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			os.Remove(part)
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(part)
+		return err
+	}
+
+	return os.Rename(part, path)
+}
@@ -0,0 +1,250 @@
+package rtve
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// encodeMediaText obfuscates url the way RTVE's ztnr endpoint does, the
+// inverse of decodeMediaText.
+func encodeMediaText(quality, url string) []byte {
+	var alphabet []byte
+	for i := 0; i < len(url); i++ {
+		if bytes.IndexByte(alphabet, url[i]) < 0 {
+			alphabet = append(alphabet, url[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	for i, pad := 0, 0; i < len(alphabet); i++ {
+		buf.WriteByte(alphabet[i])
+		pad = (pad + 1) % 4
+		buf.WriteString(strings.Repeat("x", pad))
+	}
+
+	buf.WriteByte(0)
+	buf.WriteString(quality + "%%")
+
+	skip := 3
+	for n := 1; n <= len(url); n++ {
+		index := bytes.IndexByte(alphabet, url[n-1])
+		buf.WriteByte(byte('0' + index/10))
+		buf.WriteString(strings.Repeat("7", skip))
+		buf.WriteByte(byte('0' + index%10))
+		skip = (n + 3) % 4
+	}
+
+	return buf.Bytes()
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(len(data)))
+	buf.WriteString(chunkType)
+	buf.Write(data)
+	binary.Write(&buf, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(chunkType), data...)))
+	return buf.Bytes()
+}
+
+func mediaPNG(sources ...MediaSource) []byte {
+	png := append([]byte(nil), pngSignature...)
+	png = append(png, pngChunk("IHDR", make([]byte, 13))...)
+	for _, source := range sources {
+		png = append(png, pngChunk("tEXt", encodeMediaText(source.Quality, source.URL))...)
+	}
+	return append(png, pngChunk("IEND", nil)...)
+}
+
+func TestDecodeMediaPNG(t *testing.T) {
+	expected := []MediaSource{
+		{Quality: "HD_READY", URL: "https://rtve-hlsvod.secure.footprint.net/resources/TE_NGVA/mp4/4/8/1741985861634.mp4/playlist.m3u8"},
+		{Quality: "Alta", URL: "https://mvod.lvlt.rtve.es/resources/TE_NGVA/mp4/4/8/1741985861634.mp4"},
+	}
+	png := mediaPNG(expected...)
+
+	for name, data := range map[string][]byte{
+		"raw":    png,
+		"base64": []byte(base64.StdEncoding.EncodeToString(png) + "\n"),
+	} {
+		sources, err := DecodeMediaPNG(data)
+		if err != nil {
+			t.Fatalf("%s: DecodeMediaPNG failed: %v", name, err)
+		}
+		if len(sources) != len(expected) {
+			t.Fatalf("%s: expected %d sources, got %v", name, len(expected), sources)
+		}
+		for i := range expected {
+			if sources[i] != expected[i] {
+				t.Errorf("%s: expected %+v, got %+v", name, expected[i], sources[i])
+			}
+		}
+	}
+
+	if !expected[0].HLS() || expected[0].Progressive() || !expected[1].Progressive() {
+		t.Error("Unexpected media source kinds")
+	}
+
+	for _, invalid := range []string{"", "not a png", string(pngSignature) + "\x00\x00\x01\x00tEXt"} {
+		if _, err := DecodeMediaPNG([]byte(invalid)); err == nil {
+			t.Errorf("Expected an error decoding %q", invalid)
+		}
+	}
+}
+
+func TestBestMedia(t *testing.T) {
+	best, ok := bestMedia([]MediaSource{
+		{Quality: "Alta", URL: "https://example.com/alta.mp4"},
+		{Quality: "HD_FULL", URL: "https://example.com/hd.m3u8"},
+		{Quality: "HQ", URL: "https://example.com/hq.mp4?token=1"},
+		{Quality: "Unknown", URL: "https://example.com/unknown.mp4"},
+	}, MediaSource.Progressive)
+	if !ok || best.Quality != "HQ" {
+		t.Errorf("Expected the HQ progressive source, got %+v", best)
+	}
+
+	if _, ok := bestMedia([]MediaSource{{Quality: "HD_FULL", URL: "https://example.com/hd.m3u8"}}, MediaSource.Progressive); ok {
+		t.Error("Expected no progressive source")
+	}
+}
+
+func TestDownloadVideo(t *testing.T) {
+	png := mediaPNG(MediaSource{Quality: "HQ", URL: "https://mvod.lvlt.rtve.es/resources/16492499.mp4"})
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.String() {
+		case "https://www.rtve.es/ztnr/movil/thumbnail/rtveplayw/videos/16492499.png?q=v2":
+			return newResponse(http.StatusOK, base64.StdEncoding.EncodeToString(png)), nil
+		case "https://mvod.lvlt.rtve.es/resources/16492499.mp4":
+			return newResponse(http.StatusOK, "video data"), nil
+		}
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	dir := t.TempDir()
+	path, err := s.DownloadVideo(&VideoMetadata{ID: "16492499"}, dir)
+	if err != nil {
+		t.Fatalf("DownloadVideo failed: %v", err)
+	}
+	if path != filepath.Join(dir, "video_16492499.mp4") {
+		t.Errorf("Unexpected video path: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "video data" {
+		t.Errorf("Unexpected video file: %q, %v", data, err)
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Errorf("Partial download left behind: %v", err)
+	}
+
+	// DASH manifests aren't supported
+	png = mediaPNG(MediaSource{Quality: "HQ", URL: "https://example.com/manifest.mpd"})
+	s = NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, string(png)), nil
+	})
+	if _, err := s.DownloadVideo(&VideoMetadata{ID: "1"}, dir); !errors.Is(err, ErrNoMedia) {
+		t.Errorf("Expected ErrNoMedia, got %v", err)
+	}
+}
+
+// failingReader returns data and then fails, like a dropped connection.
+type failingReader struct {
+	data []byte
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, errors.New("connection reset")
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func (r *failingReader) Close() error { return nil }
+
+func TestDownloadMediaResume(t *testing.T) {
+	const content = "0123456789"
+	path := filepath.Join(t.TempDir(), "video_1.mp4")
+	ignoreRange := false
+
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var start int
+		if r := req.Header.Get("Range"); r != "" && !ignoreRange {
+			if _, err := fmt.Sscanf(r, "bytes=%d-", &start); err != nil {
+				t.Fatalf("Unexpected Range header %q", r)
+			}
+			if start >= len(content) {
+				resp := newResponse(http.StatusRequestedRangeNotSatisfiable, "")
+				resp.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", len(content)))
+				return resp, nil
+			}
+			resp := newResponse(http.StatusPartialContent, content[start:])
+			resp.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+			return resp, nil
+		}
+		return newResponse(http.StatusOK, content), nil
+	})
+
+	// An interrupted download keeps what it got
+	interrupted := NewScrapper("telediario-2")
+	interrupted.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp := newResponse(http.StatusOK, "")
+		resp.Body = &failingReader{data: []byte(content[:4])}
+		return resp, nil
+	})
+	if err := interrupted.downloadMedia(context.Background(), "https://example.com/1.mp4", path); err == nil {
+		t.Fatal("Expected the interrupted download to fail")
+	}
+	if data, _ := os.ReadFile(path + ".part"); string(data) != "0123" {
+		t.Fatalf("Expected the partial download to be kept, got %q", data)
+	}
+
+	// The next attempt resumes it
+	if err := s.downloadMedia(context.Background(), "https://example.com/1.mp4", path); err != nil {
+		t.Fatalf("Resumed download failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("Expected %q after resuming, got %q", content, data)
+	}
+
+	// A complete .part that wasn't renamed is just renamed
+	os.WriteFile(path+".part", []byte(content), 0644)
+	if err := s.downloadMedia(context.Background(), "https://example.com/1.mp4", path); err != nil {
+		t.Fatalf("Download of a complete .part failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("Expected %q, got %q", content, data)
+	}
+
+	// A .part larger than the file, e.g. of a replaced video, is discarded
+	os.WriteFile(path+".part", []byte(content+"extra"), 0644)
+	if err := s.downloadMedia(context.Background(), "https://example.com/1.mp4", path); err != nil {
+		t.Fatalf("Download of a mismatched .part failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("Expected the download to restart, got %q", data)
+	}
+
+	// Servers ignoring the range restart the download
+	ignoreRange = true
+	os.WriteFile(path+".part", []byte("xx"), 0644)
+	if err := s.downloadMedia(context.Background(), "https://example.com/1.mp4", path); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != content {
+		t.Errorf("Expected the download to restart, got %q", data)
+	}
+}
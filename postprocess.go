@@ -0,0 +1,379 @@
+package rtve
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PostProcessor is called with the path of every video file downloaded
+// while scraping, once the video's subtitles have been saved. It returns
+// the path of the resulting file, which may be a new one replacing the
+// download.
+type PostProcessor func(ctx context.Context, meta *VideoMetadata, videoPath string) (string, error)
+
+// FFmpeg post-processes downloaded videos with the ffmpeg command.
+// The zero value does nothing; see Remux, VideoCodec, EmbedSubtitles and SRT.
+type FFmpeg struct {
+	// Path is the ffmpeg binary, looked up in PATH if empty
+	Path string
+
+	// Remux converts MPEG-TS files merged from HLS streams to MP4,
+	// without re-encoding.
+	Remux bool
+
+	// VideoCodec transcodes the video stream with this ffmpeg encoder,
+	// e.g. "libx265". Empty copies the stream as is.
+	VideoCodec string
+
+	// EmbedSubtitles adds the subtitle tracks downloaded for the video as
+	// soft subtitles, with their language set.
+	EmbedSubtitles bool
+
+	// Audio extracts just the audio track to a file in this format, "m4a"
+	// or "mp3", which replaces the video file (see AudioFile): the video is
+	// removed once the extraction succeeds. Audio files already in this
+	// format, as saved by Scrapper.DownloadAudioTrack, are kept as they
+	// are. The other options, except SRT and the subtitle offset, are
+	// ignored.
+	Audio string
+
+	// SRT saves the subtitle tracks downloaded for the video as SRT files
+	// next to the resulting media file (see SRTFile). It doesn't need
+	// ffmpeg, unless DetectOffset is set.
+	SRT bool
+
+	// SubtitleOffset delays the subtitles saved as SRT or embedded by this
+	// much, for streams whose pre-roll shifts the media relative to the
+	// subtitles.
+	SubtitleOffset time.Duration
+
+	// DetectOffset estimates the subtitle offset of each video instead of
+	// using SubtitleOffset, see DetectSubtitleOffset. SubtitleOffset is
+	// used when nothing is detected.
+	DetectOffset bool
+}
+
+// minDetectedOffset is the smallest offset DetectSubtitleOffset reports,
+// smaller differences are the usual lag of subtitles behind speech.
+const minDetectedOffset = 500 * time.Millisecond
+
+// offsetProbe is how much of the media DetectSubtitleOffset analyzes.
+const offsetProbe = 2 * time.Minute
+
+// audioCodecs maps the formats FFmpeg.Audio supports to the ffmpeg
+// arguments that encode them. RTVE publishes AAC audio, which M4A files
+// hold as is.
+var audioCodecs = map[string][]string{
+	"m4a": {"-c:a", "copy"},
+	"mp3": {"-c:a", "libmp3lame", "-q:a", "2"},
+}
+
+// Available reports whether the ffmpeg binary can be found.
+func (f *FFmpeg) Available() bool {
+	_, err := exec.LookPath(f.binary())
+	return err == nil
+}
+
+func (f *FFmpeg) binary() string {
+	if f.Path != "" {
+		return f.Path
+	}
+	return "ffmpeg"
+}
+
+// PostProcess runs ffmpeg on videoPath as configured, writing an MP4 file
+// (or an audio file, see Audio) next to it and removing the original once
+// ffmpeg succeeds, then saves the SRT files of the result if SRT is set.
+// It can be passed to WithPostProcessor.
+func (f *FFmpeg) PostProcess(ctx context.Context, meta *VideoMetadata, videoPath string) (string, error) {
+	if f.Audio != "" {
+		// Audio tracks saved by DownloadAudioTrack are already extracted
+		path := videoPath
+		if filepath.Base(videoPath) != AudioFile(meta.ID, "."+f.Audio) {
+			var err error
+			path, err = f.extractAudio(ctx, meta, videoPath)
+			if err != nil {
+				return "", err
+			}
+		}
+		if !f.SRT {
+			return path, nil
+		}
+		subtitles, err := downloadedSubtitles(filepath.Dir(path), meta.ID)
+		if err != nil {
+			return path, err
+		}
+		return path, writeSRTs(path, subtitles, f.subtitleOffset(ctx, path, subtitles))
+	}
+
+	ext := filepath.Ext(videoPath)
+	remux := f.Remux && ext != ".mp4"
+	process := remux || f.VideoCodec != "" || f.EmbedSubtitles
+	if !process && !f.SRT {
+		return videoPath, nil
+	}
+
+	var subtitles []subtitleTrack
+	var offset time.Duration
+	if f.EmbedSubtitles || f.SRT {
+		var err error
+		subtitles, err = downloadedSubtitles(filepath.Dir(videoPath), meta.ID)
+		if err != nil {
+			return "", err
+		}
+		offset = f.subtitleOffset(ctx, videoPath, subtitles)
+	}
+
+	path := videoPath
+	if process {
+		embedded := subtitles
+		if !f.EmbedSubtitles {
+			embedded = nil
+		}
+
+		target := strings.TrimSuffix(videoPath, ext) + ".mp4"
+		// ffmpeg can't write over its input
+		tmp := strings.TrimSuffix(videoPath, ext) + ".ffmpeg.mp4"
+
+		var err error
+		path, err = f.run(ctx, meta, videoPath, f.args(videoPath, embedded, offset, tmp), tmp, target)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if f.SRT {
+		if err := writeSRTs(path, subtitles, offset); err != nil {
+			return path, err
+		}
+	}
+
+	return path, nil
+}
+
+// extractAudio saves the audio track of videoPath to an audio file in
+// f.Audio format, removing the video.
+func (f *FFmpeg) extractAudio(ctx context.Context, meta *VideoMetadata, videoPath string) (string, error) {
+	codec, ok := audioCodecs[f.Audio]
+	if !ok {
+		return "", fmt.Errorf("unsupported audio format %q", f.Audio)
+	}
+
+	ext := "." + f.Audio
+	target := filepath.Join(filepath.Dir(videoPath), AudioFile(meta.ID, ext))
+	tmp := strings.TrimSuffix(target, ext) + ".ffmpeg" + ext
+
+	args := []string{"-hide_banner", "-loglevel", "error", "-y", "-i", videoPath, "-map", "0:a", "-vn"}
+	args = append(args, codec...)
+	args = append(args, tmp)
+
+	return f.run(ctx, meta, videoPath, args, tmp, target)
+}
+
+// run runs ffmpeg with args, which write to tmp, then renames tmp to
+// target and removes input if it's a different file.
+func (f *FFmpeg) run(ctx context.Context, meta *VideoMetadata, input string, args []string, tmp, target string) (string, error) {
+	cmd := exec.CommandContext(ctx, f.binary(), args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("ffmpeg failed for video %s: %w: %s", meta.ID, err, lastLine(stderr.String()))
+	}
+
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if target != input {
+		if err := os.Remove(input); err != nil {
+			return "", err
+		}
+	}
+
+	return target, nil
+}
+
+// args returns the ffmpeg arguments to process input into output.
+// Subtitles are delayed by offset.
+func (f *FFmpeg) args(input string, subtitles []subtitleTrack, offset time.Duration, output string) []string {
+	args := []string{"-hide_banner", "-loglevel", "error", "-y", "-i", input}
+	for _, sub := range subtitles {
+		if offset != 0 {
+			args = append(args, "-itsoffset", formatSeconds(offset))
+		}
+		args = append(args, "-i", sub.path)
+	}
+
+	args = append(args, "-map", "0:v?", "-map", "0:a?")
+	for i := range subtitles {
+		args = append(args, "-map", fmt.Sprintf("%d:s", i+1))
+	}
+
+	codec := "copy"
+	if f.VideoCodec != "" {
+		codec = f.VideoCodec
+	}
+	args = append(args, "-c:v", codec, "-c:a", "copy")
+
+	if len(subtitles) > 0 {
+		args = append(args, "-c:s", "mov_text")
+		for i, sub := range subtitles {
+			args = append(args, fmt.Sprintf("-metadata:s:s:%d", i), "language="+iso639_2(sub.lang))
+		}
+	}
+
+	return append(args, output)
+}
+
+// subtitleOffset returns the offset to apply to the subtitles of the media
+// file at path.
+func (f *FFmpeg) subtitleOffset(ctx context.Context, path string, subtitles []subtitleTrack) time.Duration {
+	if !f.DetectOffset || len(subtitles) == 0 {
+		return f.SubtitleOffset
+	}
+
+	offset, err := f.DetectSubtitleOffset(ctx, path, subtitles[0].path)
+	if err != nil || offset == 0 {
+		return f.SubtitleOffset
+	}
+	return offset
+}
+
+// DetectSubtitleOffset estimates how much the subtitles at vttPath must be
+// delayed to match the media file at mediaPath, e.g. because the stream
+// starts with a pre-roll. It uses ffmpeg's silencedetect filter on the
+// first minutes of the media: when the silence at the start lasts longer
+// than the time before the first cue, speech is assumed to start with the
+// first cue. It returns zero when no offset is detected.
+func (f *FFmpeg) DetectSubtitleOffset(ctx context.Context, mediaPath, vttPath string) (time.Duration, error) {
+	file, err := os.Open(vttPath)
+	if err != nil {
+		return 0, err
+	}
+	cues, err := ParseVTT(file)
+	file.Close()
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", vttPath, err)
+	}
+	if len(cues) == 0 {
+		return 0, fmt.Errorf("no cues in %s", vttPath)
+	}
+
+	args := []string{"-hide_banner", "-nostats", "-t", formatSeconds(offsetProbe), "-i", mediaPath,
+		"-vn", "-af", "silencedetect=noise=-35dB:d=0.3", "-f", "null", "-"}
+	cmd := exec.CommandContext(ctx, f.binary(), args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("ffmpeg failed for %s: %w: %s", mediaPath, err, lastLine(stderr.String()))
+	}
+
+	offset := leadingSilence(stderr.String()) - cues[0].Start
+	if offset < minDetectedOffset {
+		return 0, nil
+	}
+	return offset, nil
+}
+
+// leadingSilence returns how long the silence at the start of the media
+// lasts, given the output of ffmpeg's silencedetect filter.
+func leadingSilence(output string) time.Duration {
+	started := false
+	for _, line := range strings.Split(output, "\n") {
+		if _, value, ok := strings.Cut(line, "silence_start: "); ok {
+			start, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			// Only silence right at the start counts
+			if err != nil || start > 0.1 {
+				return 0
+			}
+			started = true
+			continue
+		}
+		if _, value, ok := strings.Cut(line, "silence_end: "); ok && started {
+			value, _, _ = strings.Cut(value, " ")
+			end, err := strconv.ParseFloat(value, 64)
+			if err != nil || end < 0 {
+				return 0
+			}
+			return time.Duration(end * float64(time.Second)).Round(time.Millisecond)
+		}
+	}
+	return 0
+}
+
+// writeSRTs saves subtitles as SRT files next to mediaPath, delayed by
+// offset.
+func writeSRTs(mediaPath string, subtitles []subtitleTrack, offset time.Duration) error {
+	for _, sub := range subtitles {
+		if err := saveSRT(sub.path, SRTFile(mediaPath, sub.lang), offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatSeconds formats d as seconds for ffmpeg, e.g. "1.500".
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+type subtitleTrack struct {
+	lang string
+	path string
+}
+
+// downloadedSubtitles returns the subtitle tracks downloaded from RTVE for
+// a video in folder, sorted by language. Imported files are left out.
+func downloadedSubtitles(folder, videoID string) ([]subtitleTrack, error) {
+	paths, err := filepath.Glob(filepath.Join(folder, "subs", videoID+"_*.vtt"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var tracks []subtitleTrack
+	for _, path := range paths {
+		lang := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), videoID+"_"), ".vtt")
+		if strings.Contains(lang, ".") {
+			// <id>_<lang>.imported.vtt
+			continue
+		}
+		tracks = append(tracks, subtitleTrack{lang: lang, path: path})
+	}
+
+	return tracks, nil
+}
+
+// iso639_2 returns the three letter code MP4 files use for the languages
+// RTVE publishes subtitles in, or lang itself for others.
+func iso639_2(lang string) string {
+	codes := map[string]string{
+		"es": "spa",
+		"en": "eng",
+		"ca": "cat",
+		"eu": "eus",
+		"gl": "glg",
+	}
+
+	if code, ok := codes[strings.ToLower(lang)]; ok {
+		return code
+	}
+	return lang
+}
+
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
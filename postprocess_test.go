@@ -0,0 +1,322 @@
+package rtve
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFFmpegArgs(t *testing.T) {
+	f := &FFmpeg{Remux: true, VideoCodec: "libx265", EmbedSubtitles: true}
+	subtitles := []subtitleTrack{{lang: "en", path: "subs/1_en.vtt"}, {lang: "es", path: "subs/1_es.vtt"}}
+
+	got := strings.Join(f.args("video_1.ts", subtitles, 0, "video_1.ffmpeg.mp4"), " ")
+	expected := "-hide_banner -loglevel error -y -i video_1.ts -i subs/1_en.vtt -i subs/1_es.vtt " +
+		"-map 0:v? -map 0:a? -map 1:s -map 2:s -c:v libx265 -c:a copy -c:s mov_text " +
+		"-metadata:s:s:0 language=eng -metadata:s:s:1 language=spa video_1.ffmpeg.mp4"
+	if got != expected {
+		t.Errorf("Unexpected ffmpeg arguments:\n got: %s\nwant: %s", got, expected)
+	}
+
+	// Offsets delay every subtitle input
+	got = strings.Join(f.args("video_1.ts", subtitles[:1], 1500*time.Millisecond, "video_1.ffmpeg.mp4"), " ")
+	if !strings.Contains(got, "-i video_1.ts -itsoffset 1.500 -i subs/1_en.vtt -map") {
+		t.Errorf("Expected the subtitle input to be offset, got %s", got)
+	}
+}
+
+func TestLeadingSilence(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected time.Duration
+	}{
+		{"[silencedetect @ 0x1] silence_start: 0\n[silencedetect @ 0x1] silence_end: 4.5123 | silence_duration: 4.5123\n", 4512 * time.Millisecond},
+		{"[silencedetect @ 0x1] silence_start: 12.3\n[silencedetect @ 0x1] silence_end: 13 | silence_duration: 0.7\n", 0},
+		{"[silencedetect @ 0x1] silence_start: -0.01\n", 0},
+		{"Stream #0:0: Audio: aac\n", 0},
+	}
+
+	for _, tt := range tests {
+		if got := leadingSilence(tt.output); got != tt.expected {
+			t.Errorf("leadingSilence(%q) = %v, expected %v", tt.output, got, tt.expected)
+		}
+	}
+}
+
+func TestFFmpegSRT(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake ffmpeg")
+	}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "subs"), 0755)
+	vtt := "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nBuenas <i>noches</i>\n"
+	os.WriteFile(filepath.Join(dir, "subs", "1_es.vtt"), []byte(vtt), 0644)
+	video := filepath.Join(dir, "video_1.mp4")
+	os.WriteFile(video, []byte("stream"), 0644)
+
+	// A fixed offset doesn't need ffmpeg
+	f := &FFmpeg{Path: filepath.Join(dir, "missing-ffmpeg"), SRT: true, SubtitleOffset: 2 * time.Second}
+	path, err := f.PostProcess(context.Background(), &VideoMetadata{ID: "1"}, video)
+	if err != nil || path != video {
+		t.Fatalf("PostProcess = %s, %v", path, err)
+	}
+	srt, _ := os.ReadFile(filepath.Join(dir, "video_1.es.srt"))
+	if expected := "1\n00:00:03,000 --> 00:00:04,000\nBuenas noches\n"; string(srt) != expected {
+		t.Errorf("Unexpected SRT:\n%s", srt)
+	}
+
+	// A 6.25s pre-roll of silence before speech
+	fake := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\necho '[silencedetect @ 0x1] silence_start: 0' >&2\necho '[silencedetect @ 0x1] silence_end: 6.25 | silence_duration: 6.25' >&2\n"
+	if err := os.WriteFile(fake, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f = &FFmpeg{Path: fake, SRT: true, DetectOffset: true}
+	if _, err := f.PostProcess(context.Background(), &VideoMetadata{ID: "1"}, video); err != nil {
+		t.Fatalf("PostProcess failed: %v", err)
+	}
+	srt, _ = os.ReadFile(filepath.Join(dir, "video_1.es.srt"))
+	if expected := "1\n00:00:06,250 --> 00:00:07,250\nBuenas noches\n"; string(srt) != expected {
+		t.Errorf("Unexpected SRT with a detected offset:\n%s", srt)
+	}
+}
+
+func TestDownloadedSubtitles(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "subs"), 0755)
+	for _, name := range []string{"1_es.vtt", "1_en.vtt", "1_es.imported.vtt", "2_es.vtt"} {
+		os.WriteFile(filepath.Join(dir, "subs", name), []byte("WEBVTT\n"), 0644)
+	}
+
+	tracks, err := downloadedSubtitles(dir, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var langs []string
+	for _, track := range tracks {
+		langs = append(langs, track.lang)
+	}
+	if !slices.Equal(langs, []string{"en", "es"}) {
+		t.Errorf("Expected en and es tracks, got %v", langs)
+	}
+}
+
+func TestFFmpegPostProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake ffmpeg")
+	}
+
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "ffmpeg")
+	// Copies the input to the output, the last argument
+	script := "#!/bin/sh\nin=\"$6\"\nfor out; do :; done\ncp \"$in\" \"$out\"\n"
+	if err := os.WriteFile(fake, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	video := filepath.Join(dir, "video_1.ts")
+	os.WriteFile(video, []byte("stream"), 0644)
+
+	f := &FFmpeg{Path: fake, Remux: true}
+	path, err := f.PostProcess(context.Background(), &VideoMetadata{ID: "1"}, video)
+	if err != nil {
+		t.Fatalf("PostProcess failed: %v", err)
+	}
+	if path != filepath.Join(dir, "video_1.mp4") {
+		t.Errorf("Unexpected output path: %s", path)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "stream" {
+		t.Errorf("Unexpected output content: %q", data)
+	}
+	if _, err := os.Stat(video); !os.IsNotExist(err) {
+		t.Errorf("Original file not removed: %v", err)
+	}
+
+	// MP4 downloads don't need remuxing
+	path, err = f.PostProcess(context.Background(), &VideoMetadata{ID: "1"}, path)
+	if err != nil || path != filepath.Join(dir, "video_1.mp4") {
+		t.Errorf("Expected MP4 files to be left alone, got %s, %v", path, err)
+	}
+
+	f.Path = filepath.Join(dir, "missing-ffmpeg")
+	if f.Available() {
+		t.Error("Expected a missing binary not to be available")
+	}
+}
+
+func TestFFmpegAudio(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake ffmpeg")
+	}
+
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "ffmpeg")
+	// Records its arguments and copies the input to the output
+	script := "#!/bin/sh\necho \"$@\" > \"$(dirname \"$0\")/args\"\nin=\"$6\"\nfor out; do :; done\ncp \"$in\" \"$out\"\n"
+	if err := os.WriteFile(fake, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	video := filepath.Join(dir, "video_1.ts")
+	os.WriteFile(video, []byte("stream"), 0644)
+
+	f := &FFmpeg{Path: fake, Audio: "mp3", Remux: true}
+	path, err := f.PostProcess(context.Background(), &VideoMetadata{ID: "1"}, video)
+	if err != nil {
+		t.Fatalf("PostProcess failed: %v", err)
+	}
+	if path != filepath.Join(dir, "audio_1.mp3") {
+		t.Errorf("Unexpected output path: %s", path)
+	}
+	if _, err := os.Stat(video); !os.IsNotExist(err) {
+		t.Errorf("Video file not removed: %v", err)
+	}
+	if !checkVideoFileExists(dir, "1") {
+		t.Error("Expected the audio file to count as downloaded media")
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	expected := "-hide_banner -loglevel error -y -i " + video + " -map 0:a -vn -c:a libmp3lame -q:a 2 " + filepath.Join(dir, "audio_1.ffmpeg.mp3")
+	if got := strings.TrimSpace(string(args)); got != expected {
+		t.Errorf("Unexpected ffmpeg arguments:\n got: %s\nwant: %s", got, expected)
+	}
+
+	f.Audio = "flac"
+	if _, err := f.PostProcess(context.Background(), &VideoMetadata{ID: "1"}, path); err == nil {
+		t.Error("Expected an error for an unsupported audio format")
+	}
+}
+
+func TestDownloadAudioTrack(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake ffmpeg")
+	}
+
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "ffmpeg")
+	// Copies the input to the output
+	script := "#!/bin/sh\nin=\"$6\"\nfor out; do :; done\ncp \"$in\" \"$out\"\n"
+	if err := os.WriteFile(fake, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f := &FFmpeg{Path: fake}
+
+	var requested []string
+	png := mediaPNG(
+		MediaSource{Quality: "HD_FULL", URL: "https://hls.example.com/1/master.m3u8"},
+		MediaSource{Quality: "HD_FULL", URL: "https://example.com/1_hd.mp4"},
+	)
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.Path)
+		switch req.URL.Path {
+		case "/ztnr/movil/thumbnail/rtveplayw/videos/1.png":
+			return newResponse(http.StatusOK, base64.StdEncoding.EncodeToString(png)), nil
+		case "/1/master.m3u8":
+			return newResponse(http.StatusOK, "#EXTM3U\n#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID=\"aac\",NAME=\"es\",DEFAULT=YES,URI=\"audio.m3u8\"\n#EXT-X-STREAM-INF:BANDWIDTH=1,AUDIO=\"aac\"\nvideo.m3u8\n"), nil
+		case "/1/audio.m3u8":
+			return newResponse(http.StatusOK, "#EXTM3U\n#EXTINF:10.0,\naudio-a.aac\n#EXTINF:10.0,\naudio-b.aac\n#EXT-X-ENDLIST\n"), nil
+		case "/1/audio-a.aac", "/1/audio-b.aac":
+			return newResponse(http.StatusOK, strings.TrimSuffix(strings.TrimPrefix(req.URL.Path, "/1/audio-"), ".aac")), nil
+		}
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	out := t.TempDir()
+	path, err := s.DownloadAudioTrack(&VideoMetadata{ID: "1"}, out, f)
+	if err != nil {
+		t.Fatalf("DownloadAudioTrack failed: %v", err)
+	}
+	if path != filepath.Join(out, "audio_1.m4a") {
+		t.Errorf("Unexpected audio path: %s", path)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "ab" {
+		t.Errorf("Expected the audio rendition segments, got %q, %v", data, err)
+	}
+	for _, p := range requested {
+		if p == "/1/video.m3u8" || strings.HasSuffix(p, ".mp4") {
+			t.Errorf("Unexpected request for video media: %s", p)
+		}
+	}
+	if entries, _ := os.ReadDir(out); len(entries) != 1 {
+		t.Errorf("Expected only the audio file to be left, found %d entries", len(entries))
+	}
+
+	// Without HLS, the lowest quality progressive file is used
+	requested = nil
+	png = mediaPNG(
+		MediaSource{Quality: "HD_FULL", URL: "https://example.com/2_hd.mp4"},
+		MediaSource{Quality: "Media", URL: "https://example.com/2_low.mp4"},
+	)
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.Path)
+		switch req.URL.Path {
+		case "/ztnr/movil/thumbnail/rtveplayw/videos/2.png":
+			return newResponse(http.StatusOK, base64.StdEncoding.EncodeToString(png)), nil
+		case "/2_low.mp4":
+			return newResponse(http.StatusOK, "low"), nil
+		}
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	f.Audio = "mp3"
+	path, err = s.DownloadAudioTrack(&VideoMetadata{ID: "2"}, out, f)
+	if err != nil {
+		t.Fatalf("DownloadAudioTrack failed: %v", err)
+	}
+	if path != filepath.Join(out, "audio_2.mp3") {
+		t.Errorf("Unexpected audio path: %s", path)
+	}
+	if len(requested) != 2 || requested[1] != "/2_low.mp4" {
+		t.Errorf("Expected only the lowest quality file to be downloaded, got %v", requested)
+	}
+	if _, err := os.Stat(filepath.Join(out, "audio_2.source.mp4")); !os.IsNotExist(err) {
+		t.Errorf("Downloaded video not removed: %v", err)
+	}
+
+	// The post-processor keeps the audio track, without extracting it again
+	processed, err := f.PostProcess(context.Background(), &VideoMetadata{ID: "2"}, path)
+	if err != nil || processed != path {
+		t.Errorf("Expected the audio file to be kept, got %q, %v", processed, err)
+	}
+
+	if !NewScrapper("telediario-2", WithAudioOnly(f)).downloadsMedia() {
+		t.Error("Expected WithAudioOnly to download the media of TV programs")
+	}
+	if !NewScrapper("", WithMediaType(MediaAudio), WithAudioOnly(f)).downloadsMedia() {
+		t.Error("Expected WithAudioOnly to download the media of radio programs")
+	}
+}
+
+func TestWithPostProcessor(t *testing.T) {
+	png := mediaPNG(MediaSource{Quality: "HQ", URL: "https://example.com/1.mp4"})
+
+	var processed string
+	s := NewScrapper("telediario-2", WithPostProcessor(func(ctx context.Context, meta *VideoMetadata, videoPath string) (string, error) {
+		processed = videoPath
+		return videoPath, nil
+	}))
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, ".png") {
+			return newResponse(http.StatusOK, base64.StdEncoding.EncodeToString(png)), nil
+		}
+		return newResponse(http.StatusOK, "video"), nil
+	})
+
+	dir := t.TempDir()
+	if err := s.downloadVideo(context.Background(), &VideoMetadata{ID: "1"}, dir); err != nil {
+		t.Fatalf("downloadVideo failed: %v", err)
+	}
+	if processed != filepath.Join(dir, "video_1.mp4") {
+		t.Errorf("Post-processor not called with the downloaded file, got %q", processed)
+	}
+}
@@ -0,0 +1,114 @@
+package rtve
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Priority controls the order videos are fetched in while scraping.
+type Priority int
+
+const (
+	// PriorityListing fetches videos in listing order, newest first, page
+	// by page. It's the default.
+	PriorityListing Priority = iota
+
+	// PriorityExpiring fetches the videos closest to their availability
+	// deadline (see VideoMetadata.ExpiresAt) first, so they're archived
+	// before RTVE takes them down. Videos without an expiry date follow, in
+	// listing order.
+	//
+	// Scrape lists every page in range before fetching any video, and
+	// needs the metadata of every video missing from the archive to sort
+	// them. A *TimeBudgetError then reports the first page of the range:
+	// videos aren't fetched page by page.
+	PriorityExpiring
+)
+
+// ParsePriority parses a fetch priority name: "listing" or "expiring".
+func ParsePriority(name string) (Priority, error) {
+	switch name {
+	case "", "listing":
+		return PriorityListing, nil
+	case "expiring":
+		return PriorityExpiring, nil
+	}
+	return PriorityListing, fmt.Errorf("invalid priority %q (valid: listing, expiring)", name)
+}
+
+func (p Priority) String() string {
+	if p == PriorityExpiring {
+		return "expiring"
+	}
+	return "listing"
+}
+
+// WithPriority sets the order videos are fetched in, see Priority.
+func WithPriority(p Priority) Option {
+	return func(s *Scrapper) {
+		s.priority = p
+	}
+}
+
+// sortByExpiry returns ids with the videos expiring soonest first. Videos
+// without an expiry date, including those already archived, keep their
+// relative order after them. Metadata is remembered by
+// DownloadVideoMetaContext, so processing the videos doesn't download it
+// again.
+func (s *Scrapper) sortByExpiry(ctx context.Context, ids []string) []string {
+	expires := make([]time.Time, len(ids))
+
+	// In read-only mode, nothing missing can be fetched anyway, and no
+	// request must be made
+	if !s.readOnly {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, max(s.concurrency, 1))
+		for i, id := range ids {
+			exists, folder := s.checkVideoExistsByID(id)
+			if exists && s.checkSubtitlesExist(folder) && (!s.downloadsMedia() || checkVideoFileExists(folder, id)) {
+				continue
+			}
+
+			sem <- struct{}{}
+			if ctx.Err() != nil {
+				<-sem
+				break
+			}
+
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				// Failures are reported when the video is processed
+				if meta, err := s.DownloadVideoMetaContext(ctx, id); err == nil {
+					expires[i] = meta.ExpiresAt
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	order := make([]int, len(ids))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ea, eb := expires[order[a]], expires[order[b]]
+		if ea.IsZero() || eb.IsZero() {
+			return !ea.IsZero() && eb.IsZero()
+		}
+		return ea.Before(eb)
+	})
+
+	sorted := make([]string, len(ids))
+	for i, j := range order {
+		sorted[i] = ids[j]
+	}
+	return sorted
+}
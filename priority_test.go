@@ -0,0 +1,81 @@
+package rtve
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParsePriority(t *testing.T) {
+	tests := map[string]Priority{
+		"":         PriorityListing,
+		"listing":  PriorityListing,
+		"expiring": PriorityExpiring,
+	}
+
+	for name, expected := range tests {
+		p, err := ParsePriority(name)
+		if err != nil {
+			t.Errorf("ParsePriority(%q) failed: %v", name, err)
+		}
+		if p != expected {
+			t.Errorf("ParsePriority(%q) = %v, expected %v", name, p, expected)
+		}
+	}
+
+	if _, err := ParsePriority("oldest"); err == nil {
+		t.Error("Expected error for invalid priority")
+	}
+}
+
+func TestScrapeVideosPriorityExpiring(t *testing.T) {
+	expirations := map[string]string{
+		"1": "",
+		"2": "20-03-2025 23:59:00",
+		"3": "",
+		"4": "15-03-2025 23:59:00",
+	}
+
+	metaRequests := make(map[string]int)
+	var order []string
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		id := path.Base(path.Dir(req.URL.Path))
+		if strings.HasSuffix(req.URL.Path, "/subtitulos.json") {
+			order = append(order, id)
+			return newResponse(http.StatusOK, `{"page":{"items":[]}}`), nil
+		}
+
+		id = strings.TrimSuffix(path.Base(req.URL.Path), ".json")
+		metaRequests[id]++
+		expires := "null"
+		if expirations[id] != "" {
+			expires = fmt.Sprintf("%q", expirations[id])
+		}
+		body := fmt.Sprintf(`{"page":{"items":[{"id":%q,"publicationDate":"14-03-2025 21:00:00","expirationDate":%s}]}}`, id, expires)
+		return newResponse(http.StatusOK, body), nil
+	})
+
+	s := NewScrapper("telediario-2", WithOutputPath(t.TempDir()), WithPriority(PriorityExpiring))
+	s.client.Transport = transport
+
+	downloaded, errs := s.ScrapeVideos([]string{"1", "2", "3", "4"})
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if downloaded != 4 {
+		t.Errorf("Expected 4 videos downloaded, got %d", downloaded)
+	}
+
+	// Soonest to expire first, then the rest in listing order
+	if expected := []string{"4", "2", "1", "3"}; !slices.Equal(order, expected) {
+		t.Errorf("Expected videos fetched in order %v, got %v", expected, order)
+	}
+	for id, n := range metaRequests {
+		if n != 1 {
+			t.Errorf("Expected metadata for %s requested once, got %d", id, n)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package rtve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ProgramSearchURL is RTVE's program search endpoint, queried by
+// SearchPrograms with the URL-encoded search terms.
+const ProgramSearchURL = "https://api2.rtve.es/api/programas.json?search=%s&size=%d"
+
+// programSearchSize is how many programs SearchPrograms asks for.
+const programSearchSize = 50
+
+// Program is an RTVE program found by SearchPrograms.
+type Program struct {
+	// ID is the RTVE program ID
+	ID string
+	// Title is the program's title, e.g. "Aquí la Tierra"
+	Title string
+	// Name is the slug RTVE Play uses in the URLs of the program's
+	// episodes, e.g. "aqui-la-tierra", the name to register it with
+	Name string
+	// HTMLURL is the program's page on RTVE Play
+	HTMLURL string
+	// Media is the media type the program publishes, MediaAudio for radio
+	// programs
+	Media MediaType
+}
+
+// Show returns the definition to register the program with, see
+// RegisterShow.
+func (p Program) Show() *Show {
+	return &Show{ID: p.ID, Media: p.Media}
+}
+
+// SearchPrograms finds the RTVE programs matching query, TV and radio, so
+// shows missing from ListShows can be registered (see RegisterShow) and
+// scraped. Programs without a page on RTVE Play can't be scraped and are
+// left out.
+func (s *Scrapper) SearchPrograms(query string) ([]Program, error) {
+	return s.SearchProgramsContext(context.Background(), query)
+}
+
+// SearchProgramsContext works like SearchPrograms, aborting the request
+// when ctx is done.
+func (s *Scrapper) SearchProgramsContext(ctx context.Context, query string) ([]Program, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("empty search query")
+	}
+
+	body, err := s.get(ctx, fmt.Sprintf(ProgramSearchURL, url.QueryEscape(query), programSearchSize))
+	if err != nil {
+		return nil, fmt.Errorf("error searching programs: %w", err)
+	}
+
+	var response struct {
+		Page struct {
+			Items []struct {
+				ID      json.RawMessage `json:"id"`
+				Name    string          `json:"name"`
+				Title   string          `json:"title"`
+				HTMLURL string          `json:"htmlUrl"`
+			} `json:"items"`
+		} `json:"page"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return nil, fmt.Errorf("error decoding program search results: %w", err)
+	}
+
+	programs := make([]Program, 0, len(response.Page.Items))
+	for _, item := range response.Page.Items {
+		// IDs are sent as strings or numbers
+		id, ok := parseLenientInt(item.ID)
+		name, media := programSlug(item.HTMLURL)
+		if !ok || name == "" {
+			continue
+		}
+
+		title := item.Title
+		if title == "" {
+			title = item.Name
+		}
+		programs = append(programs, Program{
+			ID:      strconv.Itoa(id),
+			Title:   title,
+			Name:    name,
+			HTMLURL: item.HTMLURL,
+			Media:   media,
+		})
+	}
+
+	return programs, nil
+}
+
+// programSlug returns the slug of an RTVE Play program page URL and the
+// media type of the program, e.g. "aqui-la-tierra" and MediaVideo for
+// https://www.rtve.es/play/videos/aqui-la-tierra/, or "" if it's not one.
+func programSlug(pageURL string) (string, MediaType) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return "", ""
+	}
+
+	path := strings.Trim(u.Path, "/")
+	media := MediaVideo
+	slug, ok := strings.CutPrefix(path, "play/videos/")
+	if !ok {
+		media = MediaAudio
+		slug, ok = strings.CutPrefix(path, "play/audios/")
+	}
+	if !ok || slug == "" || strings.Contains(slug, "/") {
+		return "", ""
+	}
+	return slug, media
+}
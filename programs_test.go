@@ -0,0 +1,52 @@
+package rtve
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSearchPrograms(t *testing.T) {
+	var requested string
+	s := NewScrapper("")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = req.URL.String()
+		return newResponse(http.StatusOK, `{"page":{"items":[
+			{"id":"48150","name":"Aquí la Tierra","htmlUrl":"https://www.rtve.es/play/videos/aqui-la-tierra/"},
+			{"id":135930,"title":"Telediario 2","htmlUrl":"https://www.rtve.es/play/videos/telediario-2"},
+			{"id":"1000","title":"No es un día cualquiera","htmlUrl":"https://www.rtve.es/play/audios/no-es-un-dia-cualquiera/"},
+			{"id":"1","name":"Sin página","htmlUrl":"https://www.rtve.es/television/sin-pagina/"},
+			{"id":"","name":"Sin ID","htmlUrl":"https://www.rtve.es/play/videos/sin-id/"}
+		]}}`), nil
+	})
+
+	programs, err := s.SearchPrograms(" aquí la tierra ")
+	if err != nil {
+		t.Fatalf("SearchPrograms failed: %v", err)
+	}
+
+	if expected := "https://api2.rtve.es/api/programas.json?search=aqu%C3%AD+la+tierra&size=50"; requested != expected {
+		t.Errorf("Unexpected request %s", requested)
+	}
+
+	expected := []Program{
+		{ID: "48150", Title: "Aquí la Tierra", Name: "aqui-la-tierra", HTMLURL: "https://www.rtve.es/play/videos/aqui-la-tierra/", Media: MediaVideo},
+		{ID: "135930", Title: "Telediario 2", Name: "telediario-2", HTMLURL: "https://www.rtve.es/play/videos/telediario-2", Media: MediaVideo},
+		{ID: "1000", Title: "No es un día cualquiera", Name: "no-es-un-dia-cualquiera", HTMLURL: "https://www.rtve.es/play/audios/no-es-un-dia-cualquiera/", Media: MediaAudio},
+	}
+	if len(programs) != len(expected) {
+		t.Fatalf("Expected %d programs, got %+v", len(expected), programs)
+	}
+	for i := range expected {
+		if programs[i] != expected[i] {
+			t.Errorf("Program %d = %+v, expected %+v", i, programs[i], expected[i])
+		}
+	}
+
+	if show := programs[2].Show(); show.Media != MediaAudio {
+		t.Errorf("Expected radio programs to register as audio shows, got %q", show.Media)
+	}
+
+	if _, err := s.SearchPrograms("  "); err == nil {
+		t.Error("Expected error for an empty query")
+	}
+}
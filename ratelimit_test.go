@@ -0,0 +1,62 @@
+package rtve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(100)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatalf("wait failed: %v", err)
+		}
+	}
+
+	// The first request goes out immediately, the other 4 are spaced 10ms apart
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected requests to be throttled, 5 took %v", elapsed)
+	}
+}
+
+func TestRateLimiterCanceled(t *testing.T) {
+	l := newRateLimiter(0.001)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatalf("First wait should not block: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	requests := 0
+	s := NewScrapper("telediario-2", WithRateLimit(50))
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return newResponse(http.StatusOK, ""), nil
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := s.ScrapePage(i); err != nil {
+			t.Fatalf("ScrapePage failed: %v", err)
+		}
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected requests to be throttled, 3 took %v", elapsed)
+	}
+}
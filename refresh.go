@@ -0,0 +1,175 @@
+package rtve
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// MetadataChange is a metadata field RTVE changed since an episode was
+// archived. Old is nil for added fields and New is nil for removed ones.
+type MetadataChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old,omitempty"`
+	New   any    `json:"new,omitempty"`
+}
+
+// MetadataRefresh is an entry of an episode's metadata change log.
+type MetadataRefresh struct {
+	RefreshedAt time.Time        `json:"refreshedAt"`
+	Changes     []MetadataChange `json:"changes"`
+}
+
+// ChangesFile returns the name of the file, stored next to
+// video_<id>.json, logging the metadata changes found by RefreshMetadata.
+func ChangesFile(videoID string) string {
+	return fmt.Sprintf("changes_%s.json", videoID)
+}
+
+// RefreshMetadata downloads the metadata of a video archived in folder
+// again and, if RTVE changed it, e.g. correcting the title or adding a
+// description, overwrites video_<id>.json and appends the changes to the
+// episode's change log (see ChangesFile). It returns the changes found,
+// none if the metadata is unchanged. Audios of radio programs are told
+// apart from videos by their archived URLs, see VideoMetadata.MediaType.
+//
+// The metadata file is touched even when nothing changed, so its
+// modification time is when it was last refreshed.
+func (s *Scrapper) RefreshMetadata(folder, videoID string) ([]MetadataChange, error) {
+	return s.RefreshMetadataContext(context.Background(), folder, videoID)
+}
+
+// RefreshMetadataContext works like RefreshMetadata, aborting the request
+// when ctx is done.
+func (s *Scrapper) RefreshMetadataContext(ctx context.Context, folder, videoID string) ([]MetadataChange, error) {
+	if err := s.checkWritable(folder); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(folder, fmt.Sprintf("video_%s.json", videoID))
+	stored, err := readJSONObject(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading stored metadata for %s: %w", videoID, err)
+	}
+
+	// Archives mix videos and audios, the archived metadata tells which
+	media := s.mediaType()
+	htmlURL, _ := stored["htmlUrl"].(string)
+	uri, _ := stored["uri"].(string)
+	if archived := (&VideoMetadata{HTMLUrl: htmlURL, URI: uri}).MediaType(); archived != "" {
+		media = archived
+	}
+
+	// Metadata remembered from earlier downloads would hide the changes
+	meta, err := s.downloadMeta(ctx, videoID, media)
+	if err != nil {
+		return nil, err
+	}
+	s.metaCache.store(videoID, meta)
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal video metadata: %v", err)
+	}
+	var fresh map[string]any
+	if err := json.Unmarshal(data, &fresh); err != nil {
+		return nil, err
+	}
+
+	changes := diffJSONObjects(stored, fresh)
+	if len(changes) == 0 {
+		now := time.Now()
+		return nil, os.Chtimes(path, now, now)
+	}
+
+	if err := s.SaveVideoToFile(meta, folder); err != nil {
+		return nil, err
+	}
+	if err := s.logMetadataChanges(folder, videoID, changes); err != nil {
+		return changes, err
+	}
+
+	return changes, nil
+}
+
+// ReadMetadataChanges returns the metadata change log of a video archived
+// in folder, oldest first. It returns an empty slice if the metadata never
+// changed.
+func ReadMetadataChanges(folder, videoID string) ([]MetadataRefresh, error) {
+	data, err := os.ReadFile(filepath.Join(folder, ChangesFile(videoID)))
+	if errors.Is(err, os.ErrNotExist) {
+		return []MetadataRefresh{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading metadata changes: %w", err)
+	}
+
+	var log []MetadataRefresh
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("parsing metadata changes: %w", err)
+	}
+	return log, nil
+}
+
+func (s *Scrapper) logMetadataChanges(folder, videoID string, changes []MetadataChange) error {
+	log, err := ReadMetadataChanges(folder, videoID)
+	if err != nil {
+		return err
+	}
+	log = append(log, MetadataRefresh{RefreshedAt: time.Now().UTC(), Changes: changes})
+
+	jsonData, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling metadata changes: %w", err)
+	}
+
+	if err := s.writeFile(filepath.Join(folder, ChangesFile(videoID)), jsonData, false); err != nil {
+		return fmt.Errorf("writing metadata changes: %w", err)
+	}
+	return nil
+}
+
+func readJSONObject(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// diffJSONObjects returns the top-level fields that differ between old and
+// new, sorted by name.
+func diffJSONObjects(old, new map[string]any) []MetadataChange {
+	fields := make(map[string]bool)
+	for field := range old {
+		fields[field] = true
+	}
+	for field := range new {
+		fields[field] = true
+	}
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	var changes []MetadataChange
+	for _, field := range names {
+		if !reflect.DeepEqual(old[field], new[field]) {
+			changes = append(changes, MetadataChange{Field: field, Old: old[field], New: new[field]})
+		}
+	}
+	return changes
+}
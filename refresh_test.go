@@ -0,0 +1,153 @@
+package rtve
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRefreshMetadata(t *testing.T) {
+	dir := t.TempDir()
+	s := NewScrapper("telediario-2", WithOutputPath(dir))
+	s.client.Transport = fixtureTransport(t)
+	if _, errs := s.ScrapeVideos([]string{"16492499"}); len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+
+	folder := filepath.Join(dir, "2025", "2025-03-14")
+	path := filepath.Join(folder, "video_16492499.json")
+
+	// Archived before RTVE fixed the title
+	stored, err := readJSONObject(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	title := stored["longTitle"]
+	stored["longTitle"] = "Old title"
+	data, _ := json.Marshal(stored)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s = NewScrapper("telediario-2", WithOutputPath(dir))
+	s.client.Transport = fixtureTransport(t)
+	changes, err := s.RefreshMetadata(folder, "16492499")
+	if err != nil {
+		t.Fatalf("RefreshMetadata failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Field != "longTitle" || changes[0].Old != "Old title" || changes[0].New != title {
+		t.Errorf("Unexpected changes: %+v", changes)
+	}
+
+	stored, _ = readJSONObject(path)
+	if stored["longTitle"] != title {
+		t.Errorf("Metadata file not updated, title is %v", stored["longTitle"])
+	}
+
+	log, err := ReadMetadataChanges(folder, "16492499")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 1 || len(log[0].Changes) != 1 || log[0].RefreshedAt.IsZero() {
+		t.Errorf("Unexpected change log: %+v", log)
+	}
+
+	// Unchanged metadata is only touched
+	old := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(path, old, old)
+	changes, err = s.RefreshMetadata(folder, "16492499")
+	if err != nil || len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v, %v", changes, err)
+	}
+	if info, _ := os.Stat(path); time.Since(info.ModTime()) > time.Hour {
+		t.Errorf("Metadata file not touched, modified at %s", info.ModTime())
+	}
+	if log, _ := ReadMetadataChanges(folder, "16492499"); len(log) != 1 {
+		t.Errorf("Expected the change log to be left alone, got %+v", log)
+	}
+
+	ro := NewScrapper("telediario-2", WithOutputPath(dir), WithReadOnly(true))
+	if _, err := ro.RefreshMetadata(folder, "16492499"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestRefreshMetadataTwice(t *testing.T) {
+	folder := t.TempDir()
+	stored := `{"id": "1", "longTitle": "Telediario", "publicationDate": "14-03-2025 21:00:00"}`
+	if err := os.WriteFile(filepath.Join(folder, "video_1.json"), []byte(stored), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	title := "Telediario - 21 horas"
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, `{"page": {"items": [{"id": "1", "longTitle": "`+title+`", "publicationDate": "14-03-2025 21:00:00"}]}}`), nil
+	})
+
+	for _, fixed := range []string{"Telediario - 21 horas", "Telediario - 21 horas - 14/03/25"} {
+		title = fixed
+		changes, err := s.RefreshMetadata(folder, "1")
+		if err != nil {
+			t.Fatalf("RefreshMetadata failed: %v", err)
+		}
+		i := slices.IndexFunc(changes, func(c MetadataChange) bool { return c.Field == "longTitle" })
+		if i < 0 || changes[i].New != fixed {
+			t.Errorf("Expected the title to change to %q, got %+v", fixed, changes)
+		}
+	}
+
+	// Later downloads get the refreshed metadata
+	meta, err := s.DownloadVideoMeta("1")
+	if err != nil || meta.LongTitle != title {
+		t.Errorf("Expected the refreshed metadata to be remembered, got %+v, %v", meta, err)
+	}
+}
+
+func TestRefreshAudioMetadata(t *testing.T) {
+	folder := t.TempDir()
+	stored := `{"id": "16500001", "longTitle": "Bloque 1", "htmlUrl": "https://www.rtve.es/play/audios/no-es-un-dia-cualquiera/bloque-1/16500001/"}`
+	if err := os.WriteFile(filepath.Join(folder, "video_16500001.json"), []byte(stored), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Scrappers without a show default to videos
+	s := NewScrapper("")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() == "https://api2.rtve.es/api/audios/16500001.json" {
+			return newResponse(http.StatusOK, `{"page":{"items":[{"id":"16500001","title":"No es un día cualquiera - Bloque 1","htmlUrl":"https://www.rtve.es/play/audios/no-es-un-dia-cualquiera/bloque-1/16500001/"}]}}`), nil
+		}
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	changes, err := s.RefreshMetadata(folder, "16500001")
+	if err != nil {
+		t.Fatalf("RefreshMetadata failed: %v", err)
+	}
+	i := slices.IndexFunc(changes, func(c MetadataChange) bool { return c.Field == "longTitle" })
+	if i < 0 || changes[i].New != "No es un día cualquiera - Bloque 1" {
+		t.Errorf("Expected the audio title to be refreshed, got %+v", changes)
+	}
+}
+
+func TestDiffJSONObjects(t *testing.T) {
+	old := map[string]any{"a": 1.0, "b": "x", "c": []any{"1"}}
+	new := map[string]any{"a": 1.0, "c": []any{"1", "2"}, "d": true}
+
+	changes := diffJSONObjects(old, new)
+	var fields []string
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+	}
+	if len(changes) != 3 || fields[0] != "b" || fields[1] != "c" || fields[2] != "d" {
+		t.Fatalf("Unexpected changes: %+v", changes)
+	}
+	if changes[0].New != nil || changes[2].Old != nil {
+		t.Errorf("Expected removed and added fields to have nil values: %+v", changes)
+	}
+}
@@ -0,0 +1,104 @@
+package rtve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ResumeFile is the name of the file, stored at the root of an archive,
+// that records the listing page each show's scraping stopped at when its
+// time budget ran out, see SaveResumePage.
+const ResumeFile = ".rtve-subs-resume.json"
+
+// ResumeState is where scraping a show stopped when its time budget ran
+// out.
+type ResumeState struct {
+	// Page is the first listing page that wasn't fully processed
+	Page int `json:"page"`
+	// SavedAt is when the run stopped
+	SavedAt time.Time `json:"savedAt"`
+}
+
+// ReadResumeState returns where scraping program, a show name or program
+// ID, into the archive at root last ran out of time budget, or nil if it
+// didn't.
+func ReadResumeState(root, program string) (*ResumeState, error) {
+	states, err := readResumeStates(root)
+	if err != nil {
+		return nil, err
+	}
+	state, ok := states[program]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// SaveResumePage records page, usually TimeBudgetError.Page, as the listing
+// page to resume scraping s.Program from, see ReadResumeState. Updates to
+// the ResumeFile are serialized with a lock file next to it.
+func (s *Scrapper) SaveResumePage(page int) error {
+	return s.updateResumeStates(func(states map[string]ResumeState) {
+		states[s.Program] = ResumeState{Page: page, SavedAt: time.Now().UTC()}
+	})
+}
+
+// ClearResumePage forgets the page saved with SaveResumePage, once a run
+// got through every page.
+func (s *Scrapper) ClearResumePage() error {
+	state, err := ReadResumeState(s.outputPath, s.Program)
+	if err != nil || state == nil {
+		return err
+	}
+	return s.updateResumeStates(func(states map[string]ResumeState) {
+		delete(states, s.Program)
+	})
+}
+
+func (s *Scrapper) updateResumeStates(update func(map[string]ResumeState)) error {
+	if err := s.checkWritable(s.outputPath); err != nil {
+		return err
+	}
+
+	// Runs of other shows can share the archive
+	lock, err := Lock(filepath.Join(s.outputPath, ResumeFile+".lock"), true)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	states, err := readResumeStates(s.outputPath)
+	if err != nil {
+		return err
+	}
+	update(states)
+
+	jsonData, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling resume state: %w", err)
+	}
+	if err := s.writeFile(filepath.Join(s.outputPath, ResumeFile), jsonData, true); err != nil {
+		return fmt.Errorf("writing resume state: %w", err)
+	}
+	return nil
+}
+
+func readResumeStates(root string) (map[string]ResumeState, error) {
+	data, err := os.ReadFile(filepath.Join(root, ResumeFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]ResumeState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading resume state: %w", err)
+	}
+
+	states := map[string]ResumeState{}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("parsing resume state: %w", err)
+	}
+	return states, nil
+}
@@ -0,0 +1,43 @@
+package rtve
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResumePage(t *testing.T) {
+	root := t.TempDir()
+	s := NewScrapper("telediario-2", WithOutputPath(root))
+	other := NewScrapper("135930", WithOutputPath(root))
+
+	if state, err := ReadResumeState(root, s.Program); err != nil || state != nil {
+		t.Fatalf("Expected no resume state, got %+v, %v", state, err)
+	}
+
+	if err := s.SaveResumePage(40); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.SaveResumePage(3); err != nil {
+		t.Fatal(err)
+	}
+	state, err := ReadResumeState(root, s.Program)
+	if err != nil || state == nil || state.Page != 40 || state.SavedAt.IsZero() {
+		t.Fatalf("Unexpected resume state: %+v, %v", state, err)
+	}
+
+	// Finishing a run only forgets its own show's page
+	if err := s.ClearResumePage(); err != nil {
+		t.Fatal(err)
+	}
+	if state, err := ReadResumeState(root, s.Program); err != nil || state != nil {
+		t.Errorf("Expected the resume state to be cleared, got %+v, %v", state, err)
+	}
+	if state, err := ReadResumeState(root, other.Program); err != nil || state == nil || state.Page != 3 {
+		t.Errorf("Unexpected resume state of another show: %+v, %v", state, err)
+	}
+
+	ro := NewScrapper("135930", WithOutputPath(root), WithReadOnly(true))
+	if err := ro.SaveResumePage(4); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
@@ -1,33 +1,95 @@
 package rtve
 
 import (
+	"container/list"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 // DownloadVideoMeta fetches and parses video metadata for a given video ID
 func (s *Scrapper) DownloadVideoMeta(videoID string) (*VideoMetadata, error) {
+	return s.DownloadVideoMetaContext(context.Background(), videoID)
+}
+
+// DownloadVideoMetaContext works like DownloadVideoMeta, aborting the
+// request when ctx is done.
+//
+// Metadata is requested once per video ID while it's among the most
+// recently downloaded by the Scrapper: successful downloads are remembered,
+// up to metadataCacheSize of them, and later calls return a copy of the
+// remembered metadata.
+func (s *Scrapper) DownloadVideoMetaContext(ctx context.Context, videoID string) (*VideoMetadata, error) {
+	if m := s.metaCache.load(videoID); m != nil {
+		return m, nil
+	}
+
+	m, err := s.downloadVideoMeta(ctx, videoID)
+	if err == nil {
+		s.metaCache.store(videoID, m)
+	}
+
+	return m, err
+}
+
+func (s *Scrapper) downloadVideoMeta(ctx context.Context, videoID string) (*VideoMetadata, error) {
+	return s.downloadMeta(ctx, videoID, s.mediaType())
+}
+
+// downloadMeta downloads the metadata of a video or audio, per media,
+// without looking at the metadata remembered by the Scrapper.
+func (s *Scrapper) downloadMeta(ctx context.Context, videoID string, media MediaType) (*VideoMetadata, error) {
+	audio := media == MediaAudio
 	url := fmt.Sprintf(ApiURL, videoID)
+	if audio {
+		url = fmt.Sprintf(AudioApiURL, videoID)
+	}
 
-	body, err := s.get(url)
+	body, err := s.get(ctx, url)
+	if errors.Is(err, ErrPageNotFound) {
+		return nil, fmt.Errorf("error fetching video metadata: %w: %w", ErrMetadataNotFound, err)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error fetching video metadata: %v", err)
+		return nil, fmt.Errorf("error fetching video metadata: %w", err)
 	}
 
 	m := &VideoMetadata{}
+	parse := m.Parse
+	if audio {
+		parse = m.ParseAudio
+	}
+	if err := parse(body); err != nil {
+		return m, err
+	}
+
+	if err := s.corrections.Apply(m); err != nil {
+		return m, err
+	}
 
-	return m, m.Parse(body)
+	// Corrections may fix the fields that would fail validation
+	if s.strict {
+		return m, m.Validate()
+	}
+
+	return m, nil
 }
 
 func (s *Scrapper) SaveVideoToFile(meta *VideoMetadata, directory string) error {
+	if err := s.checkWritable(directory); err != nil {
+		return err
+	}
+
 	jsonData, err := json.MarshalIndent(meta, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal video metadata: %v", err)
@@ -37,43 +99,80 @@ func (s *Scrapper) SaveVideoToFile(meta *VideoMetadata, directory string) error
 	filename := fmt.Sprintf("%s/video_%s.json", directory, meta.ID)
 
 	// Write to file
-	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+	if err := s.writeFile(filename, jsonData, true); err != nil {
 		return fmt.Errorf("failed to write video metadata to file: %v", err)
 	}
 
 	return nil
 }
 
-func (s *Scrapper) get(url string) (string, error) {
+// get fetches url, retrying on server errors and rate limiting. Concurrent
+// calls for the same URL share a single request, unless the call that sent
+// it is canceled: the others then send it again.
+func (s *Scrapper) get(ctx context.Context, url string) (string, error) {
+	return s.inflight.do(ctx, url, func() (string, error) {
+		return s.fetch(ctx, url)
+	})
+}
+
+func (s *Scrapper) fetch(ctx context.Context, url string) (string, error) {
 	const maxRetries = 3
 	const initialBackoff = 1 * time.Second
 
+	cached := s.cache.load(url)
+
 	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if err := s.limiter.wait(ctx); err != nil {
+			return "", err
+		}
+
 		// Create a new request
-		req, err := http.NewRequest("GET", url, nil)
+		req, err := s.newRequest(ctx, url, "application/json")
 		if err != nil {
 			return "", fmt.Errorf("error creating request: %w", err)
 		}
-
-		// Set headers
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/134.0.0.0 Safari/537.36")
-		req.Header.Set("Accept", "application/json")
+		cached.setValidators(req)
 
 		// Execute the request
 		resp, err := s.client.Do(req)
 		if err != nil {
-			return "", fmt.Errorf("error executing request: %v", err)
+			return "", fmt.Errorf("error executing request: %w", err)
+		}
+
+		// Unchanged since it was cached
+		if resp.StatusCode == http.StatusNotModified && cached != nil {
+			resp.Body.Close()
+			if s.verbose {
+				fmt.Printf("Using cached response for %s\n", url)
+			}
+			return cached.Body, nil
 		}
 
 		// Check status code
 		if resp.StatusCode == 404 {
 			resp.Body.Close()
-			return "", ErrPageNotFound
+			return "", &StatusError{URL: url, StatusCode: resp.StatusCode, Err: ErrPageNotFound}
 		}
 
 		if resp.StatusCode == 403 {
 			resp.Body.Close()
-			return "", ErrForbidden
+			return "", &StatusError{URL: url, StatusCode: resp.StatusCode, Err: ErrForbidden}
+		}
+
+		// Retry when rate limited, waiting as long as RTVE asks
+		if resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			if attempt < maxRetries {
+				wait := retryAfter(resp.Header.Get("Retry-After"), initialBackoff*time.Duration(1<<uint(attempt)))
+				if s.verbose {
+					fmt.Printf("Rate limited, retrying in %v (attempt %d/%d)...\n", wait, attempt+1, maxRetries)
+				}
+				if err := sleepContext(ctx, wait); err != nil {
+					return "", err
+				}
+				continue
+			}
+			return "", &StatusError{URL: url, StatusCode: resp.StatusCode, Err: ErrRateLimited}
 		}
 
 		// Retry on 5xx errors
@@ -84,15 +183,17 @@ func (s *Scrapper) get(url string) (string, error) {
 				if s.verbose {
 					fmt.Printf("Server error %d, retrying in %v (attempt %d/%d)...\n", resp.StatusCode, backoff, attempt+1, maxRetries)
 				}
-				time.Sleep(backoff)
+				if err := sleepContext(ctx, backoff); err != nil {
+					return "", err
+				}
 				continue
 			}
-			return "", fmt.Errorf("server error after %d retries: status code %d", maxRetries, resp.StatusCode)
+			return "", &StatusError{URL: url, StatusCode: resp.StatusCode, Err: fmt.Errorf("server error after %d retries: status code %d", maxRetries, resp.StatusCode)}
 		}
 
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
-			return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			return "", &StatusError{URL: url, StatusCode: resp.StatusCode, Err: fmt.Errorf("unexpected status code: %d", resp.StatusCode)}
 		}
 
 		// Read response body
@@ -102,22 +203,122 @@ func (s *Scrapper) get(url string) (string, error) {
 			return "", fmt.Errorf("error reading response body: %w", err)
 		}
 
+		// A broken cache only costs a full download next time
+		if err := s.cache.store(url, resp.Header, string(body)); err != nil && s.verbose {
+			fmt.Printf("Error caching response for %s: %v\n", url, err)
+		}
+
 		return string(body), nil
 	}
 
 	return "", fmt.Errorf("unexpected error in retry loop")
 }
 
+// newRequest builds a GET request to RTVE with the scraper's headers. The
+// Accept header is only set if accept isn't empty. Headers set with
+// WithHeaders take precedence over the defaults.
+func (s *Scrapper) newRequest(ctx context.Context, url, accept string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", s.userAgent)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	for name, values := range s.headers {
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+
+	return req, nil
+}
+
+// retryAfter returns how long a Retry-After header value asks to wait,
+// either in seconds or as an HTTP date, or fallback if the header is
+// missing or invalid.
+func retryAfter(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return fallback
+}
+
+// sleepContext pauses for d, returning early with ctx's error if ctx is
+// done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (s *Scrapper) ScrapePage(page int) ([]*VideoInfo, error) {
-	content, err := s.get(fmt.Sprintf(urlMap[s.Program].URL, page))
+	return s.ScrapePageContext(context.Background(), page)
+}
+
+// ScrapePageContext works like ScrapePage, aborting the request when ctx
+// is done.
+func (s *Scrapper) ScrapePageContext(ctx context.Context, page int) ([]*VideoInfo, error) {
+	if s.currentShow() == nil {
+		return nil, fmt.Errorf("unknown show %q, see RegisterShow", s.Program)
+	}
+
+	content, err := s.get(ctx, s.pageURL(page))
 	if err != nil {
 		return nil, fmt.Errorf("error downloading HTML: %w", err)
 	}
 	return s.scrape(content)
 }
 
+// currentShow returns the show the Scrapper scrapes, or nil if Program
+// isn't registered.
+func (s *Scrapper) currentShow() *Show {
+	if s.show != nil {
+		return s.show
+	}
+	return ShowMap(s.Program)
+}
+
+// pageURL returns the URL of a listing page of the scraper's show, asking
+// for the configured page size if there's one.
+func (s *Scrapper) pageURL(page int) string {
+	pageURL := fmt.Sprintf(s.currentShow().URL, page)
+	if s.pageSize <= 0 {
+		return pageURL
+	}
+
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	query := u.Query()
+	query.Set("size", strconv.Itoa(s.pageSize))
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
 func (s *Scrapper) scrape(content string) ([]*VideoInfo, error) {
-	pattern := regexp.MustCompile(urlMap[s.Program].Regex)
+	show := s.currentShow()
+	if show == nil {
+		return nil, fmt.Errorf("unknown show %q, see RegisterShow", s.Program)
+	}
+	pattern := regexp.MustCompile(show.Regex)
 
 	matches := pattern.FindAllString(content, -1)
 
@@ -141,13 +342,17 @@ func (s *Scrapper) scrape(content string) ([]*VideoInfo, error) {
 }
 
 func (s *Scrapper) folderForVideo(meta *VideoMetadata) (string, error) {
-	layout := "02-01-2006 15:04:05"
-	pubDate, err := time.Parse(layout, meta.PublicationDate)
+	pubDate, err := ParseRTVEDate(meta.PublicationDate)
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.Join(s.outputPath, pubDate.Format("2006"), pubDate.Format("2006-01-02")), nil
+	if s.layout != "" {
+		folder, err := FormatLayout(s.layout, meta)
+		return filepath.Join(s.outputPath, folder), err
+	}
+
+	return VideoFolder(s.outputPath, pubDate, s.monthShards), nil
 }
 
 func (s *Scrapper) checkVideoExists(meta *VideoMetadata) bool {
@@ -165,9 +370,16 @@ func (s *Scrapper) checkVideoExists(meta *VideoMetadata) bool {
 // checkVideoExistsByID checks if a video exists by searching for its JSON file
 // and returns the folder path if found. This is more efficient than fetching metadata first.
 func (s *Scrapper) checkVideoExistsByID(videoID string) (bool, string) {
+	foundPath := FindVideoFolder(s.outputPath, videoID)
+	return foundPath != "", foundPath
+}
+
+// FindVideoFolder searches root for the folder holding video_<videoID>.json
+// and returns it, or an empty string if the video hasn't been archived.
+func FindVideoFolder(root, videoID string) string {
 	var foundPath string
 
-	filepath.Walk(s.outputPath, func(path string, info os.FileInfo, err error) error {
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -178,7 +390,7 @@ func (s *Scrapper) checkVideoExistsByID(videoID string) (bool, string) {
 		return nil
 	})
 
-	return foundPath != "", foundPath
+	return foundPath
 }
 
 // checkSubtitlesExist checks if subtitles directory exists for a video in the given folder
@@ -194,10 +406,74 @@ func (s *Scrapper) checkSubtitlesExist(folder string) bool {
 	return false
 }
 
+// downloadVideo downloads the video file of a video, or just its audio
+// track with WithAudioOnly, to folder and runs the post-processor on it, if
+// there's one.
+func (s *Scrapper) downloadVideo(ctx context.Context, meta *VideoMetadata, folder string) error {
+	// Post-processing works on videos, audio files are saved as published
+	if s.mediaType() == MediaAudio {
+		_, err := s.DownloadAudioContext(ctx, meta, folder)
+		return err
+	}
+
+	var path string
+	var err error
+	if s.audioOnly != nil {
+		path, err = s.DownloadAudioTrackContext(ctx, meta, folder, s.audioOnly)
+	} else {
+		path, err = s.DownloadVideoContext(ctx, meta, folder)
+	}
+	if err != nil || s.postProcessor == nil {
+		return err
+	}
+
+	if _, err := s.postProcessor(ctx, meta, path); err != nil {
+		return fmt.Errorf("post-processing %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// checkWritable returns an error wrapping ErrReadOnly if the scrapper
+// must not write to path.
+func (s *Scrapper) checkWritable(path string) error {
+	if s.readOnly {
+		return fmt.Errorf("%w: refusing to write to %s", ErrReadOnly, path)
+	}
+	return nil
+}
+
+// checkVideoFileExists checks if the video file of a video, progressive or
+// merged from HLS, or the audio file extracted from it exists in the given
+// folder
+func checkVideoFileExists(folder, videoID string) bool {
+	names := []string{
+		VideoFile(videoID, ".mp4"),
+		VideoFile(videoID, ".ts"),
+		AudioFile(videoID, ".m4a"),
+		AudioFile(videoID, ".mp3"),
+	}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(folder, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkThumbnailExists checks if the thumbnail of a video was saved to
+// folder, see DownloadImages.
+func checkThumbnailExists(folder, videoID string) bool {
+	matches, _ := filepath.Glob(filepath.Join(folder, ThumbnailFile(videoID, ".*")))
+	return len(matches) > 0
+}
+
 func (s *Scrapper) updateFolderTime(meta *VideoMetadata, folder string) error {
+	if err := s.checkWritable(folder); err != nil {
+		return err
+	}
+
 	if meta.PublicationDate != "" {
-		layout := "02-01-2006 15:04:05"
-		pubDate, err := time.Parse(layout, meta.PublicationDate)
+		pubDate, err := ParseRTVEDate(meta.PublicationDate)
 		if err != nil {
 			return fmt.Errorf("parsing publication date for %s: %w", meta.ID, err)
 		} else {
@@ -212,17 +488,60 @@ func (s *Scrapper) updateFolderTime(meta *VideoMetadata, folder string) error {
 }
 
 func (s *Scrapper) Scrape(maxPages int) (int, []error) {
+	return s.ScrapeContext(context.Background(), maxPages)
+}
+
+// ScrapeContext works like Scrape, stopping once ctx is done. The context
+// error is then included in the returned errors.
+func (s *Scrapper) ScrapeContext(ctx context.Context, maxPages int) (int, []error) {
+	if maxPages == 0 {
+		maxPages = NoPageLimit
+	}
+	return s.ScrapeRangeContext(ctx, 0, maxPages)
+}
+
+// NoPageLimit is the last page passed to ScrapeRange to scrape up to the
+// last listing page.
+const NoPageLimit = -1
+
+// ScrapeRange works like Scrape but starts at listing page startPage instead
+// of the first one, for content known to live deep in the catalog. endPage is
+// the last page scraped (inclusive), NoPageLimit scrapes up to the last one.
+func (s *Scrapper) ScrapeRange(startPage, endPage int) (int, []error) {
+	return s.ScrapeRangeContext(context.Background(), startPage, endPage)
+}
+
+// ScrapeRangeContext works like ScrapeRange, stopping once ctx is done.
+func (s *Scrapper) ScrapeRangeContext(ctx context.Context, startPage, endPage int) (int, []error) {
 	videosDownloaded := 0
 	errs := make([]error, 0)
+	var queue []string
+	started := time.Now()
+	budgetSpent := func() bool {
+		return s.timeBudget > 0 && time.Since(started) >= s.timeBudget
+	}
 
-	page := 0
+	page := startPage
 	for {
-		// Check if we've reached the max pages limit (0 means unlimited)
-		if maxPages > 0 && page > maxPages {
+		// Check if we've reached the last page
+		if endPage != NoPageLimit && page > endPage {
 			break
 		}
 
-		links, err := s.ScrapePage(page)
+		if err := ctx.Err(); err != nil {
+			return videosDownloaded, append(errs, err)
+		}
+
+		if budgetSpent() {
+			if s.priority == PriorityExpiring {
+				// Nothing was fetched yet
+				return videosDownloaded, append(errs, &TimeBudgetError{Page: startPage})
+			}
+			errs = append(errs, &TimeBudgetError{Page: page})
+			break
+		}
+
+		links, err := s.ScrapePageContext(ctx, page)
 		if errors.Is(err, ErrPageNotFound) || errors.Is(err, ErrForbidden) {
 			break
 		}
@@ -233,89 +552,387 @@ func (s *Scrapper) Scrape(maxPages int) (int, []error) {
 			continue
 		}
 
+		ids := make([]string, 0, len(links))
 		for _, link := range links {
-			// Check if video already exists before fetching metadata
-			exists, existingFolder := s.checkVideoExistsByID(link.ID)
-
-			if exists {
-				// Video metadata exists, but check if subtitles are missing
-				if !s.checkSubtitlesExist(existingFolder) {
-					// Need to download subtitles - fetch metadata for that
-					meta, err := s.DownloadVideoMeta(link.ID)
-					if err != nil {
-						errs = append(errs, fmt.Errorf("Error downloading video metadata for %s: %w", link.ID, err))
-						continue
-					}
-
-					if s.verbose {
-						fmt.Printf("Video exists but subtitles missing, downloading subtitles: %s (ID: %s)\n", meta.LongTitle, link.ID)
-					}
-
-					err = s.DownloadSubtitles(meta, existingFolder)
-					if err != nil {
-						errs = append(errs, fmt.Errorf("Error downloading subtitles for %s: %w", link.ID, err))
-					}
-				} else {
-					if s.verbose {
-						fmt.Printf("Already downloaded, ignoring video: (ID: %s)\n", link.ID)
-					}
-				}
-				continue
+			ids = append(ids, link.ID)
+		}
+
+		// Videos are fetched once the whole range is listed
+		if s.priority == PriorityExpiring {
+			queue = append(queue, ids...)
+			page++
+			continue
+		}
+
+		downloaded, videoErrs, completed := s.processVideos(ctx, ids, budgetSpent)
+		videosDownloaded += downloaded
+		errs = append(errs, videoErrs...)
+
+		if !completed {
+			if err := ctx.Err(); err != nil {
+				errs = append(errs, err)
+			} else {
+				// The page wasn't completed, resume from it
+				errs = append(errs, &TimeBudgetError{Page: page})
 			}
+			return videosDownloaded, errs
+		}
 
-			// Video doesn't exist, download everything
-			meta, err := s.DownloadVideoMeta(link.ID)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("Error downloading video metadata for %s: %w", link.ID, err))
-				continue
+		page++
+	}
+
+	if len(queue) > 0 {
+		downloaded, videoErrs, completed := s.processVideos(ctx, s.sortByExpiry(ctx, queue), budgetSpent)
+		videosDownloaded += downloaded
+		errs = append(errs, videoErrs...)
+
+		if !completed {
+			if err := ctx.Err(); err != nil {
+				errs = append(errs, err)
+			} else {
+				errs = append(errs, &TimeBudgetError{Page: startPage})
 			}
+		}
+	}
 
-			folder, err := s.folderForVideo(meta)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("Error creating folder for %s: %w", link.ID, err))
-				continue
+	return videosDownloaded, errs
+}
+
+// ScrapeVideos downloads metadata and subtitles for specific video IDs, the
+// same way Scrape does for the videos it finds on listing pages: missing
+// videos are downloaded in full, and archived videos without subtitles get
+// their subtitles downloaded again. It's useful to re-attempt episodes that
+// failed during a previous run without scraping the whole show.
+func (s *Scrapper) ScrapeVideos(ids []string) (int, []error) {
+	return s.ScrapeVideosContext(context.Background(), ids)
+}
+
+// ScrapeVideosContext works like ScrapeVideos, stopping once ctx is done.
+func (s *Scrapper) ScrapeVideosContext(ctx context.Context, ids []string) (int, []error) {
+	if s.priority == PriorityExpiring {
+		ids = s.sortByExpiry(ctx, ids)
+	}
+
+	videosDownloaded, errs, completed := s.processVideos(ctx, ids, nil)
+	if !completed {
+		errs = append(errs, ctx.Err())
+	}
+
+	return videosDownloaded, errs
+}
+
+// processVideos runs processVideo for each of ids, on up to s.concurrency
+// videos at a time. It stops starting new videos once ctx is done or stop,
+// if set, returns true, and reports whether every video was started.
+func (s *Scrapper) processVideos(ctx context.Context, ids []string, stop func() bool) (int, []error, bool) {
+	var (
+		mu               sync.Mutex
+		wg               sync.WaitGroup
+		videosDownloaded int
+		errs             = make([]error, 0)
+	)
+
+	sem := make(chan struct{}, max(s.concurrency, 1))
+	for _, id := range ids {
+		sem <- struct{}{}
+		if ctx.Err() != nil || (stop != nil && stop()) {
+			<-sem
+			wg.Wait()
+			return videosDownloaded, errs, false
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			downloaded, videoErrs := s.processVideo(ctx, id)
+
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, videoErrs...)
+			if downloaded {
+				videosDownloaded++
 			}
-			if err := os.MkdirAll(folder, 0755); err != nil {
-				errs = append(errs, fmt.Errorf("Error creating folder for %s: %w", link.ID, err))
-				continue
+		}()
+	}
+
+	wg.Wait()
+	return videosDownloaded, errs, true
+}
+
+// downloadSubtitles downloads the subtitles of a video to folder. Videos
+// without subtitles, common for older episodes, aren't failures.
+func (s *Scrapper) downloadSubtitles(ctx context.Context, meta *VideoMetadata, folder string) error {
+	_, err := s.DownloadSubtitlesContext(ctx, meta, folder)
+	if errors.Is(err, ErrNoSubtitles) {
+		if s.verbose {
+			fmt.Printf("No subtitles available: %s (ID: %s)\n", meta.LongTitle, meta.ID)
+		}
+		return nil
+	}
+	return err
+}
+
+// processVideo downloads whatever is missing from the archive for a video
+// and reports whether the video was newly downloaded.
+func (s *Scrapper) processVideo(ctx context.Context, id string) (bool, []error) {
+	var errs []error
+
+	// Check if video already exists before fetching metadata
+	exists, existingFolder := s.checkVideoExistsByID(id)
+
+	if exists {
+		// Video metadata exists, but check if subtitles are missing. Audios
+		// have none.
+		if s.mediaType() == MediaVideo && !s.checkSubtitlesExist(existingFolder) {
+			if err := s.checkWritable(existingFolder); err != nil {
+				return false, append(errs, fmt.Errorf("Subtitles missing for %s: %w", id, err))
 			}
 
-			err = s.SaveVideoToFile(meta, folder)
+			// Need to download subtitles - fetch metadata for that
+			meta, err := s.DownloadVideoMetaContext(ctx, id)
 			if err != nil {
-				errs = append(errs, fmt.Errorf("Error saving video metadata for %s: %w", link.ID, err))
-				continue
+				return false, append(errs, fmt.Errorf("Error downloading video metadata for %s: %w", id, err))
+			}
+
+			if !meta.DurationInRange(s.minDuration, s.maxDuration) {
+				if s.verbose {
+					fmt.Printf("Skipping video outside duration limits: %s (ID: %s, %s)\n", meta.LongTitle, id, meta.Length())
+				}
+				return false, errs
+			}
+
+			if s.verbose {
+				fmt.Printf("Video exists but subtitles missing, downloading subtitles: %s (ID: %s)\n", meta.LongTitle, id)
+			}
+
+			if err := s.downloadSubtitles(ctx, meta, existingFolder); err != nil {
+				errs = append(errs, fmt.Errorf("Error downloading subtitles for %s: %w", id, err))
 			}
+		} else {
+			if s.verbose {
+				fmt.Printf("Already downloaded, ignoring video: (ID: %s)\n", id)
+			}
+		}
 
-			err = s.DownloadSubtitles(meta, folder)
+		// Resume video files missing from the archive, e.g. after an
+		// interrupted run
+		if s.downloadsMedia() && !checkVideoFileExists(existingFolder, id) {
+			if err := s.checkWritable(existingFolder); err != nil {
+				return false, append(errs, fmt.Errorf("Video file missing for %s: %w", id, err))
+			}
+
+			meta, err := s.DownloadVideoMetaContext(ctx, id)
 			if err != nil {
-				errs = append(errs, fmt.Errorf("Error downloading subtitles for %s: %w", link.ID, err))
+				return false, append(errs, fmt.Errorf("Error downloading video metadata for %s: %w", id, err))
+			}
+
+			if meta.DurationInRange(s.minDuration, s.maxDuration) {
+				if s.verbose {
+					fmt.Printf("Video exists but video file missing, downloading it: %s (ID: %s)\n", meta.LongTitle, id)
+				}
+				if err := s.downloadVideo(ctx, meta, existingFolder); err != nil {
+					errs = append(errs, fmt.Errorf("Error downloading video for %s: %w", id, err))
+				}
+			}
+		}
+
+		// Resume images missing from the archive, e.g. for episodes
+		// archived before images were asked for
+		if s.downloadImages && !checkThumbnailExists(existingFolder, id) {
+			if err := s.checkWritable(existingFolder); err != nil {
+				return false, append(errs, fmt.Errorf("Images missing for %s: %w", id, err))
 			}
 
-			err = s.updateFolderTime(meta, folder)
+			meta, err := s.DownloadVideoMetaContext(ctx, id)
 			if err != nil {
-				errs = append(errs, fmt.Errorf("Error updating folder time for %s: %w", link.ID, err))
+				return false, append(errs, fmt.Errorf("Error downloading video metadata for %s: %w", id, err))
+			}
+			if _, err := s.DownloadImagesContext(ctx, meta, existingFolder); err != nil {
+				errs = append(errs, fmt.Errorf("Error downloading images for %s: %w", id, err))
 			}
+		}
+
+		return false, errs
+	}
+
+	// Video doesn't exist, download everything
+	if err := s.checkWritable(s.outputPath); err != nil {
+		return false, append(errs, fmt.Errorf("Video %s missing from the archive: %w", id, err))
+	}
 
-			fmt.Printf("Downloaded video %s\n", meta.LongTitle)
-			videosDownloaded++
+	meta, err := s.DownloadVideoMetaContext(ctx, id)
+	if err != nil {
+		return false, append(errs, fmt.Errorf("Error downloading video metadata for %s: %w", id, err))
+	}
+
+	if !meta.DurationInRange(s.minDuration, s.maxDuration) {
+		if s.verbose {
+			fmt.Printf("Skipping video outside duration limits: %s (ID: %s, %s)\n", meta.LongTitle, id, meta.Length())
 		}
+		return false, errs
+	}
 
-		page++
+	folder, err := s.folderForVideo(meta)
+	if err != nil {
+		return false, append(errs, fmt.Errorf("Error creating folder for %s: %w", id, err))
+	}
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		return false, append(errs, fmt.Errorf("Error creating folder for %s: %w", id, err))
 	}
 
-	return videosDownloaded, errs
+	err = s.SaveVideoToFile(meta, folder)
+	if err != nil {
+		return false, append(errs, fmt.Errorf("Error saving video metadata for %s: %w", id, err))
+	}
+
+	if err := s.downloadSubtitles(ctx, meta, folder); err != nil {
+		errs = append(errs, fmt.Errorf("Error downloading subtitles for %s: %w", id, err))
+	}
+
+	// RTVE re-publishes corrected episodes under a new ID, keep every
+	// version and record which one is current
+	if replaced, err := s.recordVersion(meta, folder); err != nil {
+		errs = append(errs, fmt.Errorf("Error recording versions for %s: %w", id, err))
+	} else if len(replaced) > 0 {
+		fmt.Printf("Re-published episode %s (ID: %s) replaces %s\n", meta.LongTitle, id, strings.Join(replaced, ", "))
+	}
+
+	if s.downloadImages {
+		if _, err := s.DownloadImagesContext(ctx, meta, folder); err != nil {
+			errs = append(errs, fmt.Errorf("Error downloading images for %s: %w", id, err))
+		}
+	}
+
+	if s.downloadsMedia() {
+		if err := s.downloadVideo(ctx, meta, folder); err != nil {
+			errs = append(errs, fmt.Errorf("Error downloading video for %s: %w", id, err))
+		}
+	}
+
+	err = s.updateFolderTime(meta, folder)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("Error updating folder time for %s: %w", id, err))
+	}
+
+	fmt.Printf("Downloaded video %s\n", meta.LongTitle)
+	if len(errs) > 0 && meta.ExpiresWithin(expiryWarning, time.Now()) {
+		fmt.Printf("Warning: video %s expires on %s, retry it before then\n", id, meta.ExpiresAt.Format(time.DateTime))
+	}
+
+	return true, errs
 }
 
+// expiryWarning is how close to its expiry date a video that couldn't be
+// fully archived must be for processVideo to warn about it.
+const expiryWarning = 72 * time.Hour
+
 type VideoInfo struct {
 	URL string
 	ID  string
 }
 
 type Scrapper struct {
-	Program    string
-	client     *http.Client
-	outputPath string
-	verbose    bool
+	Program     string
+	client      *http.Client
+	outputPath  string
+	verbose     bool
+	minDuration time.Duration
+	maxDuration time.Duration
+	corrections Corrections
+	timeBudget  time.Duration
+	durability  Durability
+	monthShards bool
+	layout      string
+	concurrency int
+	limiter     *rateLimiter
+	userAgent   string
+	headers     http.Header
+	cache       *httpCache
+	strict      bool
+	metaCache   metadataCache
+	inflight    flightGroup
+	pageSize    int
+
+	downloadVideos bool
+	downloadAudios bool
+	// audioOnly extracts the audio tracks of videos, see WithAudioOnly
+	audioOnly      *FFmpeg
+	postProcessor  PostProcessor
+	readOnly       bool
+	downloadImages bool
+	priority       Priority
+
+	// show is the show scraped by a Scrapper created with NewScrapperByID,
+	// nil to look Program up in the registered shows
+	show *Show
+	// proxy is the URL of the proxy requests are sent through, see
+	// WithProxy
+	proxy string
+	// faults are the faults injected into requests, see WithFaults
+	faults *Faults
+	// media overrides the media type of the show, see WithMediaType
+	media MediaType
+}
+
+// metadataCacheSize is the number of videos a metadataCache remembers,
+// enough for the listing pages a fetch looks ahead at to be downloaded
+// once while long scrapes keep a bounded memory footprint.
+const metadataCacheSize = 512
+
+// metadataCache remembers the metadata downloaded for the most recently
+// used video IDs.
+type metadataCache struct {
+	mu sync.Mutex
+	// videos maps a video ID to its element in recent
+	videos map[string]*list.Element
+	// recent holds the remembered metadataCacheEntry values, most recently
+	// used first
+	recent list.List
+}
+
+type metadataCacheEntry struct {
+	id   string
+	meta VideoMetadata
+}
+
+// load returns a copy of the metadata remembered for id, or nil.
+func (c *metadataCache) load(id string) *VideoMetadata {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.videos[id]
+	if !ok {
+		return nil
+	}
+	c.recent.MoveToFront(e)
+	m := e.Value.(metadataCacheEntry).meta
+	return &m
+}
+
+func (c *metadataCache) store(id string, m *VideoMetadata) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.videos[id]; ok {
+		e.Value = metadataCacheEntry{id: id, meta: *m}
+		c.recent.MoveToFront(e)
+		return
+	}
+
+	if c.videos == nil {
+		c.videos = make(map[string]*list.Element)
+	}
+	c.videos[id] = c.recent.PushFront(metadataCacheEntry{id: id, meta: *m})
+
+	if c.recent.Len() > metadataCacheSize {
+		oldest := c.recent.Back()
+		c.recent.Remove(oldest)
+		delete(c.videos, oldest.Value.(metadataCacheEntry).id)
+	}
 }
 
 type Option func(*Scrapper)
@@ -332,6 +949,279 @@ func WithVerbose(verbose bool) Option {
 	}
 }
 
+// WithMinDuration skips videos shorter than d, such as the short summary
+// clips some show modules list alongside full episodes.
+func WithMinDuration(d time.Duration) Option {
+	return func(s *Scrapper) {
+		s.minDuration = d
+	}
+}
+
+// WithMaxDuration skips videos longer than d.
+func WithMaxDuration(d time.Duration) Option {
+	return func(s *Scrapper) {
+		s.maxDuration = d
+	}
+}
+
+// WithCorrections applies local metadata corrections to every video
+// metadata download. See LoadCorrections.
+func WithCorrections(c Corrections) Option {
+	return func(s *Scrapper) {
+		s.corrections = c
+	}
+}
+
+// WithTimeBudget makes Scrape stop cleanly once d has elapsed, so runs fit
+// in fixed windows such as nightly cron jobs. The returned errors then
+// include a *TimeBudgetError with the page to resume from.
+func WithTimeBudget(d time.Duration) Option {
+	return func(s *Scrapper) {
+		s.timeBudget = d
+	}
+}
+
+// WithDurability sets when written files are synced to disk. Syncing
+// protects the archive against power loss at the cost of slower backfills.
+func WithDurability(d Durability) Option {
+	return func(s *Scrapper) {
+		s.durability = d
+	}
+}
+
+// WithMonthShards saves videos to YYYY/MM/YYYY-MM-DD folders instead of
+// YYYY/YYYY-MM-DD, keeping the number of folders per directory small for
+// shows with many episodes a day. See MigrateLayout to convert an existing
+// archive.
+func WithMonthShards(enabled bool) Option {
+	return func(s *Scrapper) {
+		s.monthShards = enabled
+	}
+}
+
+// WithLayout saves new videos to the folders template gives them, see
+// FormatLayout, e.g. "{slug}" to group the episodes of non-daily shows by
+// title, instead of publication date folders. It takes precedence over
+// WithMonthShards. Videos already archived are found wherever they are.
+func WithLayout(template string) Option {
+	return func(s *Scrapper) {
+		s.layout = template
+	}
+}
+
+// WithConcurrency processes up to n videos of a listing page at the same
+// time when scraping, instead of one after another. Values below 1 are
+// treated as 1.
+func WithConcurrency(n int) Option {
+	return func(s *Scrapper) {
+		s.concurrency = n
+	}
+}
+
+// WithRateLimit throttles all requests to RTVE (listing pages, metadata and
+// subtitles) to requestsPerSecond, shared by all concurrent downloads.
+// Zero or negative values disable the limit.
+func WithRateLimit(requestsPerSecond float64) Option {
+	return func(s *Scrapper) {
+		s.limiter = nil
+		if requestsPerSecond > 0 {
+			s.limiter = newRateLimiter(requestsPerSecond)
+		}
+	}
+}
+
+// WithHTTPClient makes the scraper send all requests through client, e.g.
+// one configured with a proxy, instrumentation or custom TLS settings.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Scrapper) {
+		if client != nil {
+			s.client = client
+		}
+	}
+}
+
+// WithTransport sets the http.RoundTripper used for all requests, keeping
+// the default client settings. It's useful to test against httptest
+// servers or to add instrumentation.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(s *Scrapper) {
+		client := *s.client
+		client.Transport = transport
+		s.client = &client
+	}
+}
+
+// WithProxy routes all requests through an HTTP, HTTPS or SOCKS5 proxy,
+// e.g. "http://proxy.example.com:3128" or "socks5://127.0.0.1:1080", which
+// helps reaching geo-blocked endpoints from outside Spain. If proxyURL is
+// invalid every request fails with the parse error, rather than silently
+// bypassing the proxy. An empty proxyURL disables it.
+//
+// The proxy is set on the transport the client ends up with, whatever order
+// the options come in, so it combines with WithHTTPClient and WithTransport
+// as long as their transport is an *http.Transport, which is cloned. Other
+// transports can't be given a proxy: every request fails instead, set the
+// proxy in the transport itself.
+func WithProxy(proxyURL string) Option {
+	return func(s *Scrapper) {
+		s.proxy = proxyURL
+	}
+}
+
+// proxyClient returns a copy of client sending requests through proxyURL,
+// see WithProxy.
+func proxyClient(client *http.Client, proxyURL string) *http.Client {
+	u, err := parseProxyURL(proxyURL)
+	proxy := func(*http.Request) (*url.URL, error) {
+		return u, err
+	}
+
+	proxied := *client
+	switch transport := client.Transport.(type) {
+	case nil:
+		clone := http.DefaultTransport.(*http.Transport).Clone()
+		clone.Proxy = proxy
+		proxied.Transport = clone
+	case *http.Transport:
+		clone := transport.Clone()
+		clone.Proxy = proxy
+		proxied.Transport = clone
+	default:
+		proxied.Transport = errTransport{fmt.Errorf("can't set a proxy on a %T transport, set it in the transport instead of using WithProxy", transport)}
+	}
+
+	return &proxied
+}
+
+// errTransport is an http.RoundTripper failing every request with err.
+type errTransport struct {
+	err error
+}
+
+func (t errTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https, socks5 or socks5h", proxyURL)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", proxyURL)
+	}
+
+	return u, nil
+}
+
+// WithUserAgent sets the User-Agent header sent with every request, e.g. to
+// identify your application. Defaults to DefaultUserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(s *Scrapper) {
+		s.userAgent = userAgent
+	}
+}
+
+// WithHeaders adds headers to every request, such as Accept-Language.
+// They replace the scraper's own headers of the same name.
+func WithHeaders(headers http.Header) Option {
+	return func(s *Scrapper) {
+		if s.headers == nil {
+			s.headers = make(http.Header)
+		}
+		for name, values := range headers {
+			s.headers[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+}
+
+// WithCacheDir caches listing pages, video metadata and subtitle listings
+// in dir. Cached responses are revalidated with If-None-Match and
+// If-Modified-Since, so unchanged resources aren't downloaded again on
+// later runs. An empty dir disables the cache.
+func WithCacheDir(dir string) Option {
+	return func(s *Scrapper) {
+		s.cache = nil
+		if dir != "" {
+			s.cache = &httpCache{dir: dir}
+		}
+	}
+}
+
+// WithStrictParsing makes metadata downloads fail when fields the archive
+// relies on are missing or malformed, see VideoMetadata.Validate, instead
+// of saving them with zero values. It suits dataset builders that need
+// guarantees about every record.
+func WithStrictParsing(strict bool) Option {
+	return func(s *Scrapper) {
+		s.strict = strict
+	}
+}
+
+// WithPageSize asks RTVE for n videos per listing page instead of the
+// module's default, cutting the number of listing requests needed for
+// full-history backfills. Modules that don't support it ignore the
+// parameter. Page numbers passed to ScrapeRange count pages of this size.
+// Zero or negative values keep the default.
+func WithPageSize(n int) Option {
+	return func(s *Scrapper) {
+		s.pageSize = n
+	}
+}
+
+// WithVideoDownload also downloads the media of new videos of TV programs,
+// see DownloadVideo, next to their metadata and subtitles. Radio programs
+// need WithAudioDownload.
+func WithVideoDownload(enabled bool) Option {
+	return func(s *Scrapper) {
+		s.downloadVideos = enabled
+	}
+}
+
+// WithAudioOnly downloads just the audio track of new videos of TV
+// programs instead of their video files, see DownloadAudioTrack, in the
+// Audio format of f. Radio programs get their audio files, as published,
+// like with WithAudioDownload. A nil f disables it.
+func WithAudioOnly(f *FFmpeg) Option {
+	return func(s *Scrapper) {
+		s.audioOnly = f
+	}
+}
+
+// WithImages also saves the thumbnail of videos and the poster of their
+// program, see DownloadImages. Images missing from videos already archived
+// are downloaded too.
+func WithImages(enabled bool) Option {
+	return func(s *Scrapper) {
+		s.downloadImages = enabled
+	}
+}
+
+// WithPostProcessor runs p on every video file downloaded while scraping,
+// e.g. FFmpeg.PostProcess to remux HLS downloads or embed subtitles.
+func WithPostProcessor(p PostProcessor) Option {
+	return func(s *Scrapper) {
+		s.postProcessor = p
+	}
+}
+
+// WithReadOnly guarantees nothing is written to the output path, for
+// archives mounted read-only or owned by another user. Anything that would
+// need a write, such as saving a video missing from the archive, fails
+// with ErrReadOnly before any request is made.
+func WithReadOnly(readOnly bool) Option {
+	return func(s *Scrapper) {
+		s.readOnly = readOnly
+	}
+}
+
 func NewScrapper(program string, options ...Option) *Scrapper {
 	// Create a new HTTP client
 	client := &http.Client{
@@ -341,14 +1231,109 @@ func NewScrapper(program string, options ...Option) *Scrapper {
 		Program:    program,
 		client:     client,
 		outputPath: "rtve-videos",
+		userAgent:  DefaultUserAgent,
 	}
 
 	for _, option := range options {
 		option(s)
 	}
 
+	// Configure the final transport, whatever order the options came in
+	if s.proxy != "" {
+		s.client = proxyClient(s.client, s.proxy)
+	}
+	if s.faults != nil {
+		client := *s.client
+		client.Transport = newFaultTransport(client.Transport, *s.faults)
+		s.client = &client
+	}
+
 	return s
 }
 
+// NewScrapperByID returns a Scrapper for the RTVE program with the given
+// numeric ID, e.g. "135930" for telediario-2, which needn't be registered
+// (see RegisterShow). Its listing pages are found from the ID alone and
+// every RTVE Play episode URL in them is scraped. Program is set to the ID.
+func NewScrapperByID(programID string, options ...Option) (*Scrapper, error) {
+	show, err := ProgramShow(programID)
+	if err != nil {
+		return nil, err
+	}
+
+	s := NewScrapper(programID, options...)
+	s.show = show
+	return s, nil
+}
+
+// DefaultUserAgent is the User-Agent header sent unless WithUserAgent is
+// used. RTVE serves some pages differently to clients it doesn't recognize
+// as browsers.
+const DefaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/134.0.0.0 Safari/537.36"
+
 var ErrPageNotFound = errors.New("page not found")
 var ErrForbidden = errors.New("access not allowed")
+
+// ErrRateLimited is wrapped by the StatusError returned when RTVE keeps
+// answering 429 Too Many Requests after all retries.
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrMetadataNotFound is returned by DownloadVideoMeta when RTVE has no
+// metadata for the requested video ID.
+var ErrMetadataNotFound = errors.New("video metadata not found")
+
+// ErrNoSubtitles is returned by DownloadSubtitles when a video has no
+// subtitle tracks, which is common for older episodes.
+var ErrNoSubtitles = errors.New("no subtitles available")
+
+// ErrGeoBlocked is wrapped by errors for requests RTVE refused because the
+// video isn't available in the client's country.
+//
+// RTVE answers geo-blocked requests like any other refused one, so this is
+// a heuristic: subtitle and media downloads failing with 403 Forbidden, or
+// 410 Gone, wrap ErrGeoBlocked when the video's metadata flags it as not
+// allowed in the client's country (see VideoMetadata.GeoBlocked). The
+// status error is kept, so 403s still match ErrForbidden. Other 403s, and
+// requests made without the video's metadata, such as listing pages, only
+// wrap ErrForbidden.
+var ErrGeoBlocked = errors.New("geo-blocked")
+
+// ErrReadOnly is returned when a Scrapper created WithReadOnly would need
+// to write to the archive.
+var ErrReadOnly = errors.New("archive is read-only")
+
+// ErrTimeBudgetExhausted is wrapped by TimeBudgetError.
+var ErrTimeBudgetExhausted = errors.New("time budget exhausted")
+
+// TimeBudgetError is reported by Scrape when the time budget set with
+// WithTimeBudget runs out. Videos already archived are skipped on the next
+// run, so resuming from Page avoids re-scanning earlier pages.
+type TimeBudgetError struct {
+	// Page is the first listing page that wasn't fully processed
+	Page int
+}
+
+func (e *TimeBudgetError) Error() string {
+	return fmt.Sprintf("%v, resume from page %d", ErrTimeBudgetExhausted, e.Page)
+}
+
+func (e *TimeBudgetError) Unwrap() error {
+	return ErrTimeBudgetExhausted
+}
+
+// StatusError is returned when RTVE answers a request with an unexpected
+// HTTP status code. 404 and 403 responses wrap ErrPageNotFound and
+// ErrForbidden respectively, so errors.Is keeps working on them.
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
@@ -1,8 +1,19 @@
 package rtve
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestScrape(t *testing.T) {
@@ -55,3 +66,605 @@ func TestScrape(t *testing.T) {
 		t.Errorf("Failed to scrape HTML with different show: %v", err)
 	}
 }
+
+func TestDownloadVideoMetaCorrections(t *testing.T) {
+	data, err := os.ReadFile("fixtures/video.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+
+	corrections := Corrections{
+		"16492499": {"longTitle": json.RawMessage(`"Corrected title"`)},
+	}
+
+	s := NewScrapper("telediario-2", WithCorrections(corrections))
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, string(data)), nil
+	})
+
+	meta, err := s.DownloadVideoMeta("16492499")
+	if err != nil {
+		t.Fatalf("Failed to download metadata: %v", err)
+	}
+
+	if meta.LongTitle != "Corrected title" {
+		t.Errorf("Expected corrected title, got %s", meta.LongTitle)
+	}
+	if meta.PublicationDate != "14-03-2025 21:00:00" {
+		t.Errorf("Expected uncorrected fields to be kept, got %s", meta.PublicationDate)
+	}
+}
+
+func TestDownloadVideoMetaStrict(t *testing.T) {
+	body := `{"page":{"items":[{"id":"1","longTitle":"No date","duration":1000}]}}`
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, body), nil
+	})
+
+	// Lenient parsing keeps the zero values
+	meta, err := NewScrapper("telediario-2", WithTransport(transport)).DownloadVideoMeta("1")
+	if err != nil {
+		t.Fatalf("Lenient download failed: %v", err)
+	}
+	if meta.PublicationDate != "" {
+		t.Errorf("Expected empty publication date, got %q", meta.PublicationDate)
+	}
+
+	_, err = NewScrapper("telediario-2", WithTransport(transport), WithStrictParsing(true)).DownloadVideoMeta("1")
+	if !errors.Is(err, ErrInvalidMetadata) {
+		t.Errorf("Expected ErrInvalidMetadata in strict mode, got %v", err)
+	}
+
+	// Corrections are applied before validating
+	corrections := Corrections{"1": {
+		"uri":             json.RawMessage(`"https://www.rtve.es/api/videos/1"`),
+		"htmlUrl":         json.RawMessage(`"https://www.rtve.es/v/1/"`),
+		"publicationDate": json.RawMessage(`"14-03-2025 21:00:00"`),
+	}}
+	s := NewScrapper("telediario-2", WithTransport(transport), WithStrictParsing(true), WithCorrections(corrections))
+	if _, err := s.DownloadVideoMeta("1"); err != nil {
+		t.Errorf("Expected corrected metadata to pass strict parsing, got %v", err)
+	}
+}
+
+func TestDownloadVideoMetaMemoized(t *testing.T) {
+	var requests atomic.Int32
+	fixtures := fixtureTransport(t)
+	s := NewScrapper("telediario-2", WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		return fixtures(req)
+	})))
+
+	first, err := s.DownloadVideoMeta("16492499")
+	if err != nil {
+		t.Fatalf("DownloadVideoMeta failed: %v", err)
+	}
+	first.LongTitle = "Changed by the caller"
+
+	second, err := s.DownloadVideoMeta("16492499")
+	if err != nil {
+		t.Fatalf("DownloadVideoMeta failed: %v", err)
+	}
+	if second.LongTitle != "Telediario - 21 horas - 14/03/25" {
+		t.Errorf("Remembered metadata was modified through a returned copy: %q", second.LongTitle)
+	}
+
+	// Failures aren't remembered
+	for i := 0; i < 2; i++ {
+		if _, err := s.DownloadVideoMeta("404"); !errors.Is(err, ErrMetadataNotFound) {
+			t.Fatalf("Expected ErrMetadataNotFound for a missing video, got %v", err)
+		}
+	}
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("Expected 3 requests, got %d", got)
+	}
+}
+
+func TestMetadataCacheBounded(t *testing.T) {
+	var c metadataCache
+	for i := 0; i <= metadataCacheSize; i++ {
+		id := strconv.Itoa(i)
+		c.store(id, &VideoMetadata{ID: id})
+		if i == 0 {
+			continue
+		}
+		// The first video stays in use, the second one is evicted
+		if c.load("0") == nil {
+			t.Fatalf("Recently used metadata evicted after %d videos", i)
+		}
+	}
+
+	if c.recent.Len() != metadataCacheSize || len(c.videos) != metadataCacheSize {
+		t.Errorf("Expected %d remembered videos, got %d", metadataCacheSize, c.recent.Len())
+	}
+	if c.load("1") != nil {
+		t.Error("Expected the least recently used metadata to be evicted")
+	}
+	if m := c.load(strconv.Itoa(metadataCacheSize)); m == nil || m.ID != strconv.Itoa(metadataCacheSize) {
+		t.Errorf("Expected the newest metadata to be remembered, got %+v", m)
+	}
+}
+
+func TestWithPageSize(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	if got := s.pageURL(3); got != "https://www.rtve.es/play/videos/modulos/capitulos/135930/?page=3" {
+		t.Errorf("Unexpected default page URL: %s", got)
+	}
+
+	s = NewScrapper("telediario-2", WithPageSize(100))
+	u, err := url.Parse(s.pageURL(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Query().Get("page") != "3" || u.Query().Get("size") != "100" {
+		t.Errorf("Expected page and size parameters, got %s", u)
+	}
+}
+
+// fixtureTransport answers metadata, subtitle listing and subtitle file
+// requests from the fixtures directory.
+func fixtureTransport(t *testing.T) roundTripFunc {
+	video, err := os.ReadFile("fixtures/video.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+	subs, err := os.ReadFile("fixtures/subtitulos.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Path == "/api/videos/16492499.json":
+			return newResponse(http.StatusOK, string(video)), nil
+		case req.URL.Path == "/api/videos/16492499/subtitulos.json":
+			return newResponse(http.StatusOK, string(subs)), nil
+		case filepath.Ext(req.URL.Path) == ".vtt":
+			return newResponse(http.StatusOK, "WEBVTT\n"), nil
+		}
+		return newResponse(http.StatusNotFound, ""), nil
+	}
+}
+
+func TestScrapeVideos(t *testing.T) {
+	dir := t.TempDir()
+	s := NewScrapper("telediario-2", WithOutputPath(dir))
+	s.client.Transport = fixtureTransport(t)
+
+	downloaded, errs := s.ScrapeVideos([]string{"16492499", "404"})
+	if downloaded != 1 {
+		t.Errorf("Expected 1 video downloaded, got %d", downloaded)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 error for the missing video, got %v", errs)
+	}
+
+	folder := filepath.Join(dir, "2025", "2025-03-14")
+	if _, err := os.Stat(filepath.Join(folder, "video_16492499.json")); err != nil {
+		t.Errorf("Metadata file not saved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "subs", "16492499_es.vtt")); err != nil {
+		t.Errorf("Subtitle file not saved: %v", err)
+	}
+
+	// Already archived videos are not downloaded again
+	downloaded, errs = s.ScrapeVideos([]string{"16492499"})
+	if downloaded != 0 || len(errs) != 0 {
+		t.Errorf("Expected nothing to do for an archived video, got %d downloaded, errors %v", downloaded, errs)
+	}
+
+	// Missing subtitles are downloaded again
+	if err := os.RemoveAll(filepath.Join(folder, "subs")); err != nil {
+		t.Fatal(err)
+	}
+	if _, errs = s.ScrapeVideos([]string{"16492499"}); len(errs) != 0 {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "subs", "16492499_es.vtt")); err != nil {
+		t.Errorf("Missing subtitles were not downloaded again: %v", err)
+	}
+}
+
+func TestScrapeVideosNoSubtitles(t *testing.T) {
+	dir := t.TempDir()
+	s := NewScrapper("telediario-2", WithOutputPath(dir))
+	fixtures := fixtureTransport(t)
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/subtitulos.json") {
+			return newResponse(http.StatusOK, `{"page":{"items":[]}}`), nil
+		}
+		return fixtures(req)
+	})
+
+	// Older episodes without subtitles are archived without errors
+	for i := 0; i < 2; i++ {
+		if _, errs := s.ScrapeVideos([]string{"16492499"}); len(errs) != 0 {
+			t.Errorf("Unexpected errors for a video without subtitles: %v", errs)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2025", "2025-03-14", "video_16492499.json")); err != nil {
+		t.Errorf("Metadata file not saved: %v", err)
+	}
+}
+
+func TestScrapeVideosReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	s := NewScrapper("telediario-2", WithOutputPath(dir))
+	s.client.Transport = fixtureTransport(t)
+	if _, errs := s.ScrapeVideos([]string{"16492499"}); len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	folder := filepath.Join(dir, "2025", "2025-03-14")
+	if err := os.RemoveAll(filepath.Join(folder, "subs")); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := 0
+	ro := NewScrapper("telediario-2", WithOutputPath(dir), WithReadOnly(true))
+	ro.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return fixtureTransport(t)(req)
+	})
+
+	downloaded, errs := ro.ScrapeVideos([]string{"16492499", "16492500"})
+	if downloaded != 0 {
+		t.Errorf("Expected nothing downloaded, got %d", downloaded)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", errs)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected ErrReadOnly, got %v", err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests before failing, got %d", requests)
+	}
+	if _, err := os.Stat(filepath.Join(folder, "subs")); !os.IsNotExist(err) {
+		t.Errorf("Subtitles folder written in read-only mode: %v", err)
+	}
+
+	// Complete videos need no writes
+	if err := os.MkdirAll(filepath.Join(folder, "subs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "subs", "16492499_es.vtt"), []byte("WEBVTT\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, errs := ro.ScrapeVideos([]string{"16492499"}); len(errs) != 0 {
+		t.Errorf("Unexpected errors for an archived video: %v", errs)
+	}
+
+	meta := &VideoMetadata{ID: "16492499"}
+	if err := ro.SaveVideoToFile(meta, t.TempDir()); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly saving metadata, got %v", err)
+	}
+}
+
+func TestScrapeTimeBudget(t *testing.T) {
+	s := NewScrapper("telediario-2", WithOutputPath(t.TempDir()), WithTimeBudget(time.Nanosecond))
+	s.client.Transport = fixtureTransport(t)
+
+	downloaded, errs := s.ScrapeRange(3, NoPageLimit)
+	if downloaded != 0 {
+		t.Errorf("Expected no videos downloaded, got %d", downloaded)
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("Expected a single time budget error, got %v", errs)
+	}
+
+	var budgetErr *TimeBudgetError
+	if !errors.As(errs[0], &budgetErr) || !errors.Is(errs[0], ErrTimeBudgetExhausted) {
+		t.Fatalf("Expected *TimeBudgetError, got %v", errs[0])
+	}
+
+	if budgetErr.Page != 3 {
+		t.Errorf("Expected to resume from page 3, got %d", budgetErr.Page)
+	}
+}
+
+func TestScrapeVideosContextCanceled(t *testing.T) {
+	s := NewScrapper("telediario-2", WithOutputPath(t.TempDir()))
+	s.client.Transport = fixtureTransport(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	downloaded, errs := s.ScrapeVideosContext(ctx, []string{"16492499"})
+	if downloaded != 0 {
+		t.Errorf("Expected no videos downloaded, got %d", downloaded)
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+		t.Errorf("Expected a single context.Canceled error, got %v", errs)
+	}
+}
+
+func TestScrapeVideosConcurrency(t *testing.T) {
+	video, err := os.ReadFile("fixtures/video.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+	subs, err := os.ReadFile("fixtures/subtitulos.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+
+	var inFlight, maxInFlight atomic.Int32
+	ids := []string{"1001", "1002", "1003", "1004"}
+
+	dir := t.TempDir()
+	s := NewScrapper("telediario-2", WithOutputPath(dir), WithConcurrency(len(ids)))
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/subtitulos.json"):
+			return newResponse(http.StatusOK, string(subs)), nil
+		case filepath.Ext(req.URL.Path) == ".vtt":
+			return newResponse(http.StatusOK, "WEBVTT\n"), nil
+		}
+
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+
+		id := strings.TrimSuffix(filepath.Base(req.URL.Path), ".json")
+		return newResponse(http.StatusOK, strings.ReplaceAll(string(video), "16492499", id)), nil
+	})
+
+	downloaded, errs := s.ScrapeVideos(ids)
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	}
+	if downloaded != len(ids) {
+		t.Errorf("Expected %d videos downloaded, got %d", len(ids), downloaded)
+	}
+
+	if maxInFlight.Load() < 2 {
+		t.Errorf("Expected metadata to be fetched concurrently, max in flight was %d", maxInFlight.Load())
+	}
+
+	for _, id := range ids {
+		if _, err := os.Stat(filepath.Join(dir, "2025", "2025-03-14", "video_"+id+".json")); err != nil {
+			t.Errorf("Metadata file for %s not saved: %v", id, err)
+		}
+	}
+}
+
+func TestGetRetriesRateLimited(t *testing.T) {
+	requests := 0
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		if requests == 1 {
+			resp := newResponse(http.StatusTooManyRequests, "")
+			resp.Header.Set("Retry-After", "0")
+			return resp, nil
+		}
+		return newResponse(http.StatusOK, "ok"), nil
+	})
+
+	body, err := s.get(context.Background(), "https://www.rtve.es/")
+	if err != nil {
+		t.Fatalf("Expected the request to be retried, got %v", err)
+	}
+	if body != "ok" || requests != 2 {
+		t.Errorf("Expected 2 requests and body ok, got %d requests and %q", requests, body)
+	}
+}
+
+func TestGetRateLimitedCanceled(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp := newResponse(http.StatusTooManyRequests, "")
+		resp.Header.Set("Retry-After", "3600")
+		return resp, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err := s.get(ctx, "https://www.rtve.es/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the Retry-After wait to be canceled, got %v", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	fallback := 3 * time.Second
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", fallback},
+		{"0", 0},
+		{"120", 2 * time.Minute},
+		{"soon", fallback},
+		{"-5", fallback},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0},
+	}
+
+	for _, tt := range tests {
+		if got := retryAfter(tt.value, fallback); got != tt.expected {
+			t.Errorf("retryAfter(%q) = %v, expected %v", tt.value, got, tt.expected)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := retryAfter(future, fallback); got < 59*time.Minute || got > time.Hour {
+		t.Errorf("retryAfter(%q) = %v, expected about an hour", future, got)
+	}
+}
+
+func TestWithTransportHTTPTestServer(t *testing.T) {
+	video, err := os.ReadFile("fixtures/video.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/videos/16492499.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(video)
+	}))
+	defer srv.Close()
+
+	// Send every request to the test server, whatever host it was meant for
+	target, _ := url.Parse(srv.URL)
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = target.Scheme
+		req.URL.Host = target.Host
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	s := NewScrapper("telediario-2", WithTransport(transport))
+	meta, err := s.DownloadVideoMeta("16492499")
+	if err != nil {
+		t.Fatalf("DownloadVideoMeta failed: %v", err)
+	}
+	if meta.ID != "16492499" {
+		t.Errorf("Unexpected video ID %s", meta.ID)
+	}
+
+	if s.client.Timeout != 10*time.Second {
+		t.Errorf("WithTransport should keep the default timeout, got %v", s.client.Timeout)
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	requests := 0
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return newResponse(http.StatusOK, ""), nil
+		}),
+	}
+
+	s := NewScrapper("telediario-2", WithHTTPClient(client))
+	if _, err := s.ScrapePage(0); err != nil {
+		t.Fatalf("ScrapePage failed: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected the request to go through the custom client, got %d requests", requests)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	s := NewScrapper("telediario-2", WithProxy("socks5://127.0.0.1:1080"))
+
+	transport, ok := s.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected an *http.Transport, got %T", s.client.Transport)
+	}
+
+	req, _ := http.NewRequest("GET", "https://www.rtve.es/", nil)
+	proxy, err := transport.Proxy(req)
+	if err != nil || proxy.String() != "socks5://127.0.0.1:1080" {
+		t.Errorf("Unexpected proxy %v, err %v", proxy, err)
+	}
+}
+
+func TestWithProxyCustomTransport(t *testing.T) {
+	custom := &http.Transport{MaxIdleConnsPerHost: 42}
+
+	for _, options := range [][]Option{
+		{WithTransport(custom), WithProxy("http://proxy:3128")},
+		{WithProxy("http://proxy:3128"), WithTransport(custom)},
+		{WithProxy("http://proxy:3128"), WithHTTPClient(&http.Client{Transport: custom})},
+	} {
+		s := NewScrapper("telediario-2", options...)
+		transport, ok := s.client.Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("Expected an *http.Transport, got %T", s.client.Transport)
+		}
+		if transport.MaxIdleConnsPerHost != 42 {
+			t.Error("Expected the custom transport settings to be kept")
+		}
+
+		req, _ := http.NewRequest("GET", "https://www.rtve.es/", nil)
+		if proxy, err := transport.Proxy(req); err != nil || proxy.String() != "http://proxy:3128" {
+			t.Errorf("Unexpected proxy %v, err %v", proxy, err)
+		}
+	}
+	if custom.Proxy != nil {
+		t.Error("Expected the custom transport to be left alone")
+	}
+
+	// Other transports can't get a proxy, requests fail instead of
+	// bypassing it
+	roundTripper := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, ""), nil
+	})
+	s := NewScrapper("telediario-2", WithProxy("http://proxy:3128"), WithTransport(roundTripper))
+	if _, err := s.ScrapePage(0); err == nil || !strings.Contains(err.Error(), "proxy") {
+		t.Errorf("Expected a proxy error, got %v", err)
+	}
+}
+
+func TestWithProxyInvalid(t *testing.T) {
+	for _, proxyURL := range []string{"127.0.0.1:1080", "ftp://proxy:21", "http://"} {
+		s := NewScrapper("telediario-2", WithProxy(proxyURL))
+		if _, err := s.ScrapePage(0); err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+			t.Errorf("Expected an invalid proxy error for %q, got %v", proxyURL, err)
+		}
+	}
+}
+
+func TestRequestHeaders(t *testing.T) {
+	var got []http.Header
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		got = append(got, req.Header)
+		if filepath.Ext(req.URL.Path) == ".vtt" {
+			return newResponse(http.StatusOK, "WEBVTT\n"), nil
+		}
+		return newResponse(http.StatusOK, `{"page":{"items":[{"src":"https://www.rtve.es/resources/vtt/es.vtt","lang":"es"}]}}`), nil
+	})
+
+	s := NewScrapper("telediario-2",
+		WithTransport(transport),
+		WithUserAgent("my-archiver/1.0"),
+		WithHeaders(http.Header{"accept-language": {"es-ES"}, "Accept": {"*/*"}}),
+	)
+
+	if _, err := s.DownloadSubtitles(&VideoMetadata{ID: "123"}, t.TempDir()); err != nil {
+		t.Fatalf("DownloadSubtitles failed: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Expected a listing and a subtitle request, got %d", len(got))
+	}
+
+	for _, h := range got {
+		if h.Get("User-Agent") != "my-archiver/1.0" {
+			t.Errorf("Unexpected User-Agent %q", h.Get("User-Agent"))
+		}
+		if h.Get("Accept-Language") != "es-ES" {
+			t.Errorf("Unexpected Accept-Language %q", h.Get("Accept-Language"))
+		}
+		if h.Get("Accept") != "*/*" {
+			t.Errorf("Expected WithHeaders to override Accept, got %q", h.Get("Accept"))
+		}
+	}
+}
+
+func TestDefaultUserAgent(t *testing.T) {
+	req, err := NewScrapper("telediario-2").newRequest(context.Background(), "https://www.rtve.es/", "application/json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if req.Header.Get("User-Agent") != DefaultUserAgent || req.Header.Get("Accept") != "application/json" {
+		t.Errorf("Unexpected default headers %v", req.Header)
+	}
+}
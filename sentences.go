@@ -0,0 +1,62 @@
+package rtve
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Sentence is a sentence of a transcript, spanning one or more cues.
+type Sentence struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// sentenceEnd matches sentence terminating punctuation, including closing
+// quotes or parentheses, followed by whitespace or the end of the text.
+var sentenceEnd = regexp.MustCompile(`[.!?…]+["'”»)]*(\s+|$)`)
+
+// Sentences merges cue fragments into full sentences using simple
+// punctuation rules. A sentence starts when the first cue contributing to it
+// starts and ends when the cue holding its final punctuation ends. Trailing
+// text without final punctuation is returned as a last sentence.
+func Sentences(cues []Cue) []Sentence {
+	var sentences []Sentence
+	var current []string
+	var start time.Duration
+
+	for _, cue := range cues {
+		text := cue.PlainText()
+		for text != "" {
+			if len(current) == 0 {
+				start = cue.Start
+			}
+
+			loc := sentenceEnd.FindStringIndex(text)
+			if loc == nil {
+				current = append(current, text)
+				break
+			}
+
+			current = append(current, strings.TrimSpace(text[:loc[1]]))
+			sentences = append(sentences, Sentence{
+				Start: start,
+				End:   cue.End,
+				Text:  strings.Join(current, " "),
+			})
+			current = nil
+			text = text[loc[1]:]
+		}
+	}
+
+	if len(current) > 0 {
+		sentences = append(sentences, Sentence{
+			Start: start,
+			End:   cues[len(cues)-1].End,
+			Text:  strings.Join(current, " "),
+		})
+	}
+
+	return sentences
+}
@@ -0,0 +1,64 @@
+package rtve
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup collapses concurrent calls with the same key into one, like
+// golang.org/x/sync/singleflight, so concurrent workers asking for the same
+// URL only send one request.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done chan struct{}
+	body string
+	err  error
+	// canceled is set when the context of the caller running the call was
+	// done by the time it returned, so its result says nothing about key
+	canceled bool
+}
+
+// do runs fn for key unless a call for key is already in flight, in which
+// case it waits for that call and returns its result instead. Waiting stops
+// when ctx is done. A call that failed because its own caller's context was
+// done isn't shared: the waiters still interested run fn again.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (string, error)) (string, error) {
+	for {
+		g.mu.Lock()
+		call, ok := g.calls[key]
+		if !ok {
+			break
+		}
+		g.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-call.done:
+		}
+		if !call.canceled || call.err == nil {
+			return call.body, call.err
+		}
+	}
+
+	call := &flightCall{done: make(chan struct{})}
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.body, call.err = fn()
+	call.canceled = ctx.Err() != nil
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+
+	return call.body, call.err
+}
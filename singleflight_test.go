@@ -0,0 +1,141 @@
+package rtve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroup(t *testing.T) {
+	var g flightGroup
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = g.do(context.Background(), "key", func() (string, error) {
+				calls.Add(1)
+				<-release
+				return "body", nil
+			})
+		}()
+	}
+
+	// Let every caller reach do before the first call returns
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected 1 call, got %d", got)
+	}
+	for i, body := range results {
+		if body != "body" {
+			t.Errorf("Caller %d got %q", i, body)
+		}
+	}
+
+	// Later calls aren't collapsed into finished ones
+	g.do(context.Background(), "key", func() (string, error) {
+		calls.Add(1)
+		return "", nil
+	})
+	if got := calls.Load(); got != 2 {
+		t.Errorf("Expected a new call once the first finished, got %d calls", got)
+	}
+}
+
+func TestFlightGroupCanceled(t *testing.T) {
+	var g flightGroup
+	var calls atomic.Int32
+
+	// The caller sending the request gives up, the waiter sends it again
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	leader := make(chan error)
+	go func() {
+		_, err := g.do(ctx, "key", func() (string, error) {
+			calls.Add(1)
+			close(started)
+			<-ctx.Done()
+			return "", ctx.Err()
+		})
+		leader <- err
+	}()
+	<-started
+
+	waiter := make(chan string)
+	go func() {
+		body, err := g.do(context.Background(), "key", func() (string, error) {
+			calls.Add(1)
+			return "body", nil
+		})
+		if err != nil {
+			t.Errorf("Waiter failed: %v", err)
+		}
+		waiter <- body
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-leader; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the leader to be canceled, got %v", err)
+	}
+	if body := <-waiter; body != "body" {
+		t.Errorf("Expected the waiter to get its own result, got %q", body)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("Expected the call to be sent again, got %d calls", got)
+	}
+
+	// A waiter giving up doesn't wait for the call in flight
+	release := make(chan struct{})
+	defer close(release)
+	started = make(chan struct{})
+	go g.do(context.Background(), "slow", func() (string, error) {
+		close(started)
+		<-release
+		return "body", nil
+	})
+	<-started
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := g.do(ctx, "slow", func() (string, error) { return "", nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the waiter's deadline error, got %v", err)
+	}
+}
+
+func TestDownloadVideoMetaConcurrentDeduplicated(t *testing.T) {
+	var requests atomic.Int32
+	fixtures := fixtureTransport(t)
+	s := NewScrapper("telediario-2", WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return fixtures(req)
+	})))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.DownloadVideoMeta("16492499"); err != nil {
+				t.Errorf("DownloadVideoMeta failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("Expected concurrent requests to be collapsed into 1, got %d", got)
+	}
+}
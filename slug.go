@@ -0,0 +1,105 @@
+package rtve
+
+import (
+	"strings"
+	"unicode"
+)
+
+// transliterations maps accented and special letters found in Spanish,
+// Catalan, Galician and Basque titles to their plain ASCII equivalents.
+var transliterations = map[rune]string{
+	'á': "a", 'à': "a", 'â': "a", 'ä': "a", 'ã': "a", 'å': "a",
+	'é': "e", 'è': "e", 'ê': "e", 'ë': "e",
+	'í': "i", 'ì': "i", 'î': "i", 'ï': "i",
+	'ó': "o", 'ò': "o", 'ô': "o", 'ö': "o", 'õ': "o", 'ø': "o",
+	'ú': "u", 'ù': "u", 'û': "u", 'ü': "u",
+	'ý': "y", 'ÿ': "y",
+	'ñ': "n", 'ç': "c",
+	'æ': "ae", 'œ': "oe", 'ß': "ss",
+	// Catalan "l·l" is written as "ll" in slugs
+	'·': "",
+}
+
+// Slugify turns a title into a stable, filesystem-safe slug: accents are
+// transliterated, letters are lowercased and any run of other characters
+// becomes a single dash.
+//
+//	Slugify("Telediario - 21 horas - 14/03/25") // "telediario-21-horas-14-03-25"
+//	Slugify("España, año 2025")                 // "espana-ano-2025"
+func Slugify(title string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(title) {
+		if t, ok := transliterations[r]; ok {
+			if t == "" {
+				continue
+			}
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteString(t)
+			continue
+		}
+
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(r)
+			continue
+		}
+
+		dash = true
+	}
+
+	return b.String()
+}
+
+// Slug returns a filesystem-safe slug for the video's LongTitle, falling
+// back to the video ID when the title has no usable characters.
+func (m *VideoMetadata) Slug() string {
+	if slug := Slugify(m.LongTitle); slug != "" {
+		return slug
+	}
+	return m.ID
+}
+
+// UniqueSlug returns the video's slug followed by its ID, e.g.
+// "telediario-21-horas-14-03-25-16492499", which tells apart videos with
+// the same title, such as re-published episodes, and doesn't depend on the
+// order they're found in.
+func (m *VideoMetadata) UniqueSlug() string {
+	slug := m.Slug()
+	if slug == m.ID {
+		return slug
+	}
+	return slug + "-" + m.ID
+}
+
+// languageCodes maps the ISO 639-2 codes and the names RTVE uses for the
+// languages it publishes in to their ISO 639-1 codes.
+var languageCodes = map[string]string{
+	"spa": "es", "esp": "es", "espanol": "es", "castellano": "es", "spanish": "es",
+	"cat": "ca", "catala": "ca", "catalan": "ca",
+	"eus": "eu", "baq": "eu", "euskera": "eu", "euskara": "eu", "basque": "eu",
+	"glg": "gl", "gallego": "gl", "galego": "gl", "galician": "gl",
+	"eng": "en", "ingles": "en", "english": "en",
+}
+
+// NormalizeLanguage returns the ISO 639-1 code of a language as RTVE
+// writes it, which varies between programs: "ES", "es-ES", "spa" and
+// "Español" are all "es". Unknown languages are returned lowercased,
+// without their region.
+func NormalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if primary, _, ok := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-"); ok {
+		lang = primary
+	}
+	if code, ok := languageCodes[Slugify(lang)]; ok {
+		return code
+	}
+	return lang
+}
@@ -0,0 +1,107 @@
+package rtve
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"Telediario - 21 horas - 14/03/25", "telediario-21-horas-14-03-25"},
+		{"España, año 2025", "espana-ano-2025"},
+		{"Informe Semanal: ¿Qué pasó?", "informe-semanal-que-paso"},
+		{"Col·lecció d'estiu", "colleccio-d-estiu"},
+		{"  --Leading and trailing--  ", "leading-and-trailing"},
+		{"Çà et là", "ca-et-la"},
+		{"日本", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.expected {
+				t.Errorf("Slugify(%q) = %q, expected %q", tt.title, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestVideoMetadataSlug(t *testing.T) {
+	m := &VideoMetadata{ID: "16492499", LongTitle: "Telediario - 21 horas - 14/03/25"}
+	if m.Slug() != "telediario-21-horas-14-03-25" {
+		t.Errorf("Unexpected slug: %s", m.Slug())
+	}
+
+	m = &VideoMetadata{ID: "16492499", LongTitle: "¿?"}
+	if m.Slug() != "16492499" {
+		t.Errorf("Expected slug to fall back to the video ID, got %s", m.Slug())
+	}
+}
+
+func TestVideoMetadataUniqueSlug(t *testing.T) {
+	a := &VideoMetadata{ID: "1", LongTitle: "Informe Semanal"}
+	b := &VideoMetadata{ID: "2", LongTitle: "Informe Semanal"}
+	if a.UniqueSlug() != "informe-semanal-1" || b.UniqueSlug() != "informe-semanal-2" {
+		t.Errorf("Unexpected unique slugs: %s, %s", a.UniqueSlug(), b.UniqueSlug())
+	}
+
+	untitled := &VideoMetadata{ID: "3"}
+	if untitled.UniqueSlug() != "3" {
+		t.Errorf("Expected the video ID for untitled videos, got %s", untitled.UniqueSlug())
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := map[string]string{
+		"es":       "es",
+		"ES":       "es",
+		"es-ES":    "es",
+		"ca_ES":    "ca",
+		"spa":      "es",
+		"Español":  "es",
+		"Català":   "ca",
+		"Euskera":  "eu",
+		" galego ": "gl",
+		"fr-FR":    "fr",
+		"":         "",
+	}
+
+	for lang, expected := range tests {
+		if got := NormalizeLanguage(lang); got != expected {
+			t.Errorf("NormalizeLanguage(%q) = %q, expected %q", lang, got, expected)
+		}
+	}
+}
+
+func TestMetadataSlugAndLanguage(t *testing.T) {
+	m := &VideoMetadata{}
+	if err := json.Unmarshal([]byte(`{"id": "1", "longTitle": "España, año 2025", "language": "es-ES"}`), m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Language != "es" {
+		t.Errorf("Expected the language to be normalized, got %q", m.Language)
+	}
+
+	// Corrected titles get their slug saved too
+	m.LongTitle = "España, año 2026"
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]any
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved["slug"] != "espana-ano-2026" || saved["language"] != "es" {
+		t.Errorf("Unexpected saved slug and language: %v, %v", saved["slug"], saved["language"])
+	}
+
+	data, err = json.Marshal(&VideoMetadata{ID: "2", LongTitle: "Telediario"})
+	if err != nil || !strings.Contains(string(data), `"slug":"telediario"`) {
+		t.Errorf("Expected the slug in metadata without raw JSON, got %s, %v", data, err)
+	}
+}
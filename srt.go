@@ -0,0 +1,72 @@
+package rtve
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SRTFile returns the name of the SRT file saved for a subtitle track of a
+// media file: the media file's name with the language and .srt extension,
+// e.g. video_16492499.es.srt for video_16492499.mp4, which media players
+// pick up automatically.
+func SRTFile(mediaPath, lang string) string {
+	return strings.TrimSuffix(mediaPath, filepath.Ext(mediaPath)) + "." + lang + ".srt"
+}
+
+// ShiftCues returns cues moved offset later, or earlier if negative. Cues
+// that would end before the start of the media are dropped and cues that
+// would start before it are trimmed.
+func ShiftCues(cues []Cue, offset time.Duration) []Cue {
+	shifted := make([]Cue, 0, len(cues))
+	for _, cue := range cues {
+		cue.Start += offset
+		cue.End += offset
+		if cue.End <= 0 {
+			continue
+		}
+		cue.Start = max(cue.Start, 0)
+		shifted = append(shifted, cue)
+	}
+	return shifted
+}
+
+// EncodeSRT returns cues as a SubRip (SRT) file. WebVTT markup is removed
+// and character references are decoded, SRT has neither.
+func EncodeSRT(cues []Cue) []byte {
+	var b bytes.Buffer
+	for i, cue := range cues {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		text := html.UnescapeString(vttTagPattern.ReplaceAllString(cue.Text, ""))
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n", i+1, formatSRTTimestamp(cue.Start), formatSRTTimestamp(cue.End), text)
+	}
+	return b.Bytes()
+}
+
+// formatSRTTimestamp formats d as an SRT timestamp, e.g. "01:02:03,456".
+func formatSRTTimestamp(d time.Duration) string {
+	return strings.Replace(FormatVTTTimestamp(d), ".", ",", 1)
+}
+
+// saveSRT converts the WebVTT file at vttPath to SRT, shifted by offset,
+// and saves it as path.
+func saveSRT(vttPath, path string, offset time.Duration) error {
+	f, err := os.Open(vttPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cues, err := ParseVTT(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", vttPath, err)
+	}
+
+	return os.WriteFile(path, EncodeSRT(ShiftCues(cues, offset)), 0644)
+}
@@ -0,0 +1,48 @@
+package rtve
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSRTFile(t *testing.T) {
+	if got := SRTFile("2025/2025-03-14/video_1.mp4", "es"); got != "2025/2025-03-14/video_1.es.srt" {
+		t.Errorf("Unexpected SRT file %s", got)
+	}
+}
+
+func TestShiftCues(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: time.Second, Text: "a"},
+		{Start: time.Second, End: 3 * time.Second, Text: "b"},
+		{Start: 4 * time.Second, End: 5 * time.Second, Text: "c"},
+	}
+
+	shifted := ShiftCues(cues, -2*time.Second)
+	if len(shifted) != 2 {
+		t.Fatalf("Expected cues ending before the start to be dropped, got %+v", shifted)
+	}
+	if shifted[0].Start != 0 || shifted[0].End != time.Second {
+		t.Errorf("Expected the first cue to be trimmed, got %+v", shifted[0])
+	}
+	if shifted[1].Start != 2*time.Second || shifted[1].End != 3*time.Second {
+		t.Errorf("Unexpected shifted cue %+v", shifted[1])
+	}
+
+	if cues[1].Start != time.Second {
+		t.Error("ShiftCues modified its input")
+	}
+}
+
+func TestEncodeSRT(t *testing.T) {
+	cues := []Cue{
+		{Start: 1500 * time.Millisecond, End: 3 * time.Second, Text: "<v Presentador>Buenas noches</v>"},
+		{Start: time.Hour, End: time.Hour + 61*time.Second, Text: "Tom &amp; Jerry\nsegunda línea"},
+	}
+
+	expected := "1\n00:00:01,500 --> 00:00:03,000\nBuenas noches\n\n" +
+		"2\n01:00:00,000 --> 01:01:01,000\nTom & Jerry\nsegunda línea\n"
+	if got := string(EncodeSRT(cues)); got != expected {
+		t.Errorf("Unexpected SRT:\n%s\nwant:\n%s", got, expected)
+	}
+}
@@ -1,13 +1,16 @@
 package rtve
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -40,18 +43,95 @@ type Subtitles struct {
 	Subtitles []SubtitleItem
 }
 
-// FetchSubtitles fetches subtitle metadata for a video and returns a Subtitles object
+// SubtitlesError is returned by FetchSubtitles when the subtitle listing for
+// a video can't be fetched or parsed.
+type SubtitlesError struct {
+	// VideoID is the ID of the video the subtitles were requested for
+	VideoID string
+	// URL is the subtitle listing URL that was requested
+	URL string
+	// StatusCode is the HTTP status code returned by RTVE, or 0 if the
+	// failure wasn't caused by an HTTP error status
+	StatusCode int
+	// Err is the underlying error
+	Err error
+}
+
+func (e *SubtitlesError) Error() string {
+	return fmt.Sprintf("subtitles for video %s: %v", e.VideoID, e.Err)
+}
+
+func (e *SubtitlesError) Unwrap() error {
+	return e.Err
+}
+
+// SubtitleDownloadError is returned, joined with the others, by
+// DownloadSubtitles for each subtitle track that couldn't be downloaded.
+type SubtitleDownloadError struct {
+	// VideoID is the ID of the video the track belongs to
+	VideoID string
+	// Lang is the language code of the track
+	Lang string
+	// URL is the subtitle file URL that was requested
+	URL string
+	// Err is the underlying error
+	Err error
+}
+
+func (e *SubtitleDownloadError) Error() string {
+	return fmt.Sprintf("error downloading subtitle for %s: %v", e.Lang, e.Err)
+}
+
+func (e *SubtitleDownloadError) Unwrap() error {
+	return e.Err
+}
+
+// geoBlockedError marks err as caused by geo-blocking when RTVE refused a
+// request for a video it flags as unavailable in the client's country.
+func geoBlockedError(meta *VideoMetadata, err error) error {
+	if errors.Is(err, ErrForbidden) || isExpiredURLError(err) {
+		if meta.GeoBlocked() {
+			return fmt.Errorf("%w: %w", ErrGeoBlocked, err)
+		}
+	}
+	return err
+}
+
+func newSubtitlesError(videoID, url string, err error) *SubtitlesError {
+	subsErr := &SubtitlesError{VideoID: videoID, URL: url, Err: err}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		subsErr.StatusCode = statusErr.StatusCode
+	}
+
+	return subsErr
+}
+
+// FetchSubtitles fetches subtitle metadata for a video and returns a Subtitles object.
+// Errors are returned as *SubtitlesError.
 func (s *Scrapper) FetchSubtitles(meta *VideoMetadata) (*Subtitles, error) {
+	return s.FetchSubtitlesContext(context.Background(), meta)
+}
+
+// FetchSubtitlesContext works like FetchSubtitles, aborting the request when
+// ctx is done.
+func (s *Scrapper) FetchSubtitlesContext(ctx context.Context, meta *VideoMetadata) (*Subtitles, error) {
+	// RTVE publishes no subtitles for audios
+	if s.mediaType() == MediaAudio {
+		return &Subtitles{VideoID: meta.ID}, nil
+	}
+
 	url := fmt.Sprintf(SubsURL, meta.ID)
 
-	body, err := s.get(url)
+	body, err := s.get(ctx, url)
 	if err != nil {
-		return nil, err
+		return nil, newSubtitlesError(meta.ID, url, geoBlockedError(meta, err))
 	}
 
 	var subtitleResp SubtitleResponse
 	if err := json.Unmarshal([]byte(body), &subtitleResp); err != nil {
-		return nil, err
+		return nil, newSubtitlesError(meta.ID, url, err)
 	}
 
 	return &Subtitles{
@@ -60,10 +140,10 @@ func (s *Scrapper) FetchSubtitles(meta *VideoMetadata) (*Subtitles, error) {
 	}, nil
 }
 
-func (s *Scrapper) fetchSubtitlesResponse(id string) (*SubtitleResponse, error) {
+func (s *Scrapper) fetchSubtitlesResponse(ctx context.Context, id string) (*SubtitleResponse, error) {
 	url := fmt.Sprintf(SubsURL, id)
 
-	body, err := s.get(url)
+	body, err := s.get(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -77,24 +157,44 @@ func (s *Scrapper) fetchSubtitlesResponse(id string) (*SubtitleResponse, error)
 }
 
 // downloadWithRetry downloads a file with retry logic for 5xx errors
-func (s *Scrapper) downloadWithRetry(url string, maxRetries int) ([]byte, error) {
+func (s *Scrapper) downloadWithRetry(ctx context.Context, url string, maxRetries int) ([]byte, error) {
 	const initialBackoff = 1 * time.Second
 
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	// Subtitle files can be large, allow them more time than API calls
+	client := *s.client
+	if client.Timeout > 0 {
+		client.Timeout = max(client.Timeout, 30*time.Second)
 	}
 
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		req, err := http.NewRequest("GET", url, nil)
+		if err := s.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := s.newRequest(ctx, url, "")
 		if err != nil {
 			return nil, fmt.Errorf("error creating request: %w", err)
 		}
 
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/134.0.0.0 Safari/537.36")
-
 		resp, err := client.Do(req)
 		if err != nil {
-			return nil, fmt.Errorf("error executing request: %v", err)
+			return nil, fmt.Errorf("error executing request: %w", err)
+		}
+
+		// Retry when rate limited, waiting as long as RTVE asks
+		if resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			if attempt < maxRetries {
+				wait := retryAfter(resp.Header.Get("Retry-After"), initialBackoff*time.Duration(1<<uint(attempt)))
+				if s.verbose {
+					fmt.Printf("Rate limited downloading subtitle, retrying in %v (attempt %d/%d)...\n", wait, attempt+1, maxRetries)
+				}
+				if err := sleepContext(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, &StatusError{URL: url, StatusCode: resp.StatusCode, Err: ErrRateLimited}
 		}
 
 		// Retry on 5xx errors
@@ -105,15 +205,17 @@ func (s *Scrapper) downloadWithRetry(url string, maxRetries int) ([]byte, error)
 				if s.verbose {
 					fmt.Printf("Server error %d downloading subtitle, retrying in %v (attempt %d/%d)...\n", resp.StatusCode, backoff, attempt+1, maxRetries)
 				}
-				time.Sleep(backoff)
+				if err := sleepContext(ctx, backoff); err != nil {
+					return nil, err
+				}
 				continue
 			}
-			return nil, fmt.Errorf("server error after %d retries: status code %d", maxRetries, resp.StatusCode)
+			return nil, &StatusError{URL: url, StatusCode: resp.StatusCode, Err: fmt.Errorf("server error after %d retries: status code %d", maxRetries, resp.StatusCode)}
 		}
 
 		if resp.StatusCode != http.StatusOK {
 			resp.Body.Close()
-			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			return nil, &StatusError{URL: url, StatusCode: resp.StatusCode, Err: fmt.Errorf("unexpected status code: %d", resp.StatusCode)}
 		}
 
 		// Read response body
@@ -129,46 +231,197 @@ func (s *Scrapper) downloadWithRetry(url string, maxRetries int) ([]byte, error)
 	return nil, fmt.Errorf("unexpected error in retry loop")
 }
 
-// DownloadSubtitles downloads all available subtitles for a given video ID and saves them to the specified directory
-func (s *Scrapper) DownloadSubtitles(meta *VideoMetadata, outputDir string) error {
+// subtitleDownloadWorkers bounds the number of subtitle tracks of a video
+// downloaded at the same time.
+const subtitleDownloadWorkers = 4
+
+// SubtitleDownloadResult is the outcome of downloading one subtitle track.
+type SubtitleDownloadResult struct {
+	// Lang is the language code of the track
+	Lang string
+	// Path is the file the track was saved to, empty if it failed
+	Path string
+	// Size is the size of the saved file in bytes
+	Size int64
+	// Err is the error downloading or saving the track, a
+	// *SubtitleDownloadError for download failures
+	Err error
+}
+
+// DownloadSubtitles downloads all available subtitles for a given video ID and saves them to the specified directory.
+// Tracks are downloaded concurrently. Tracks that fail don't stop the others
+// from being saved. It returns a result per track, in listing order, and
+// the track errors joined, so callers can check errors.Is without looking
+// at the results. Failures before any track is downloaded, such as
+// ErrNoSubtitles, return no results.
+func (s *Scrapper) DownloadSubtitles(meta *VideoMetadata, outputDir string) ([]SubtitleDownloadResult, error) {
+	return s.DownloadSubtitlesContext(context.Background(), meta, outputDir)
+}
+
+// DownloadSubtitlesContext works like DownloadSubtitles, stopping once ctx
+// is done.
+func (s *Scrapper) DownloadSubtitlesContext(ctx context.Context, meta *VideoMetadata, outputDir string) ([]SubtitleDownloadResult, error) {
 	outputDir = filepath.Join(outputDir, "subs")
+	if err := s.checkWritable(outputDir); err != nil {
+		return nil, err
+	}
+
+	if s.mediaType() == MediaAudio {
+		return nil, fmt.Errorf("%w for audio ID: %s", ErrNoSubtitles, meta.ID)
+	}
 
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %v", err)
+		return nil, fmt.Errorf("failed to create output directory: %v", err)
 	}
 
 	// Fetch subtitle information
-	subtitles, err := s.fetchSubtitlesResponse(meta.ID)
+	subtitles, err := s.fetchSubtitlesResponse(ctx, meta.ID)
 	if err != nil {
-		return fmt.Errorf("failed to fetch subtitles: %v", err)
+		return nil, fmt.Errorf("failed to fetch subtitles: %w", geoBlockedError(meta, err))
 	}
 
 	// Check if there are any subtitles
 	if len(subtitles.Page.Items) == 0 {
-		return fmt.Errorf("no subtitles found for video ID: %s", meta.ID)
+		return nil, fmt.Errorf("%w for video ID: %s", ErrNoSubtitles, meta.ID)
+	}
+
+	return s.saveSubtitles(ctx, meta, subtitles.Page.Items, outputDir)
+}
+
+// SaveSubtitles downloads the tracks of an already fetched subtitle
+// listing (see FetchSubtitles) and saves them to the subs folder of
+// outputDir, like DownloadSubtitles does, without fetching the listing
+// again. Tracks are converted to UTF-8 WebVTT. A listing without tracks
+// saves nothing.
+func (s *Scrapper) SaveSubtitles(meta *VideoMetadata, subtitles *Subtitles, outputDir string) ([]SubtitleDownloadResult, error) {
+	return s.SaveSubtitlesContext(context.Background(), meta, subtitles, outputDir)
+}
+
+// SaveSubtitlesContext works like SaveSubtitles, stopping once ctx is done.
+func (s *Scrapper) SaveSubtitlesContext(ctx context.Context, meta *VideoMetadata, subtitles *Subtitles, outputDir string) ([]SubtitleDownloadResult, error) {
+	if subtitles == nil || len(subtitles.Subtitles) == 0 {
+		return nil, nil
+	}
+
+	outputDir = filepath.Join(outputDir, "subs")
+	if err := s.checkWritable(outputDir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	return s.saveSubtitles(ctx, meta, subtitles.Subtitles, outputDir)
+}
+
+// saveSubtitles downloads tracks concurrently and saves them to outputDir,
+// returning a result per track, in listing order, and the track errors
+// joined.
+func (s *Scrapper) saveSubtitles(ctx context.Context, meta *VideoMetadata, items []SubtitleItem, outputDir string) ([]SubtitleDownloadResult, error) {
+	// One slot per track keeps the results in listing order
+	results := make([]SubtitleDownloadResult, len(items))
+	sem := make(chan struct{}, subtitleDownloadWorkers)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = s.saveSubtitle(ctx, meta, item, outputDir)
+		}()
+	}
+	wg.Wait()
+
+	errs := make([]error, len(results))
+	for i, result := range results {
+		errs[i] = result.Err
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// saveSubtitle downloads a subtitle track and saves it to outputDir.
+// Download failures are reported as *SubtitleDownloadError.
+func (s *Scrapper) saveSubtitle(ctx context.Context, meta *VideoMetadata, item SubtitleItem, outputDir string) SubtitleDownloadResult {
+	result := SubtitleDownloadResult{Lang: item.Lang}
+	if err := ctx.Err(); err != nil {
+		result.Err = err
+		return result
 	}
 
-	for _, item := range subtitles.Page.Items {
-		// Create a filename based on video ID and language
-		filename := fmt.Sprintf("%s_%s.vtt", meta.ID, item.Lang)
-		outputPath := filepath.Join(outputDir, filename)
+	// Create a filename based on video ID and language
+	filename := fmt.Sprintf("%s_%s.vtt", meta.ID, item.Lang)
+	outputPath := filepath.Join(outputDir, filename)
 
-		// Download the subtitle file with retries
-		content, err := s.downloadWithRetry(item.Src, 3)
+	// Download the subtitle file with retries
+	content, err := s.downloadSubtitle(ctx, meta.ID, item)
+	if err != nil {
+		result.Err = &SubtitleDownloadError{VideoID: meta.ID, Lang: item.Lang, URL: item.Src, Err: geoBlockedError(meta, err)}
+		return result
+	}
+
+	// RTVE occasionally serves Latin-1 or BOM-prefixed tracks
+	content = ToUTF8(content)
+
+	// Some tracks are TTML, save them as WebVTT so every file is playable
+	if IsTTML(content) {
+		content, err = ttmlToVTT(content)
 		if err != nil {
-			fmt.Printf("Error downloading subtitle for %s: %v\n", item.Lang, err)
-			continue
+			result.Err = &SubtitleDownloadError{VideoID: meta.ID, Lang: item.Lang, URL: item.Src, Err: err}
+			return result
 		}
+	}
+
+	if err := s.writeFile(outputPath, content, false); err != nil {
+		result.Err = fmt.Errorf("error writing subtitle for %s: %w", item.Lang, err)
+		return result
+	}
+
+	result.Path = outputPath
+	result.Size = int64(len(content))
+	return result
+}
 
-		// Write to file
-		if err := os.WriteFile(outputPath, content, 0644); err != nil {
-			fmt.Printf("Error writing subtitle for %s: %v\n", item.Lang, err)
-			continue
+// downloadSubtitle downloads a subtitle track. Subtitle URLs can expire
+// between listing and download during long runs, so if RTVE rejects the URL
+// as forbidden or gone, the listing is resolved again and the download is
+// retried once with the fresh URL for the same language.
+func (s *Scrapper) downloadSubtitle(ctx context.Context, videoID string, item SubtitleItem) ([]byte, error) {
+	content, err := s.downloadWithRetry(ctx, item.Src, 3)
+	if !isExpiredURLError(err) {
+		return content, err
+	}
+
+	subtitles, resolveErr := s.fetchSubtitlesResponse(ctx, videoID)
+	if resolveErr != nil {
+		return nil, fmt.Errorf("%w (re-resolving subtitle URL failed: %v)", err, resolveErr)
+	}
+
+	for _, fresh := range subtitles.Page.Items {
+		if fresh.Lang == item.Lang && fresh.Src != item.Src {
+			if s.verbose {
+				fmt.Printf("Subtitle URL for %s expired, retrying with a fresh one\n", item.Lang)
+			}
+			return s.downloadWithRetry(ctx, fresh.Src, 3)
 		}
 	}
 
-	return nil
+	return nil, err
+}
+
+// isExpiredURLError reports whether err is an HTTP status that RTVE uses
+// for expired resource URLs.
+func isExpiredURLError(err error) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	return statusErr.StatusCode == http.StatusForbidden || statusErr.StatusCode == http.StatusGone
 }
 
 // Helper function to get language name from language code
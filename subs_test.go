@@ -0,0 +1,327 @@
+package rtve
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc lets tests answer HTTP requests without network access.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}
+}
+
+func TestFetchSubtitles(t *testing.T) {
+	data, err := os.ReadFile("fixtures/subtitulos.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, string(data)), nil
+	})
+
+	subs, err := s.FetchSubtitles(&VideoMetadata{ID: "16492499"})
+	if err != nil {
+		t.Fatalf("Failed to fetch subtitles: %v", err)
+	}
+
+	if subs.VideoID != "16492499" {
+		t.Errorf("Expected VideoID 16492499, got %s", subs.VideoID)
+	}
+
+	if len(subs.Subtitles) != 5 {
+		t.Errorf("Expected 5 subtitle tracks, got %d", len(subs.Subtitles))
+	}
+}
+
+func TestFetchSubtitlesStatusError(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	_, err := s.FetchSubtitles(&VideoMetadata{ID: "16492499"})
+	if err == nil {
+		t.Fatal("Expected error for 404 response")
+	}
+
+	var subsErr *SubtitlesError
+	if !errors.As(err, &subsErr) {
+		t.Fatalf("Expected *SubtitlesError, got %T", err)
+	}
+
+	if subsErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status code 404, got %d", subsErr.StatusCode)
+	}
+
+	if subsErr.URL != "https://api2.rtve.es/api/videos/16492499/subtitulos.json" {
+		t.Errorf("Unexpected URL: %s", subsErr.URL)
+	}
+
+	if subsErr.VideoID != "16492499" {
+		t.Errorf("Expected VideoID 16492499, got %s", subsErr.VideoID)
+	}
+
+	if !errors.Is(err, ErrPageNotFound) {
+		t.Error("Expected error to wrap ErrPageNotFound")
+	}
+}
+
+func TestFetchSubtitlesInvalidJSON(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, "{not json"), nil
+	})
+
+	_, err := s.FetchSubtitles(&VideoMetadata{ID: "16492499"})
+
+	var subsErr *SubtitlesError
+	if !errors.As(err, &subsErr) {
+		t.Fatalf("Expected *SubtitlesError, got %T", err)
+	}
+
+	if subsErr.StatusCode != 0 {
+		t.Errorf("Expected status code 0 for parse errors, got %d", subsErr.StatusCode)
+	}
+}
+
+func TestDownloadSubtitlesReresolvesExpiredURL(t *testing.T) {
+	listings := 0
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.String() {
+		case "https://api2.rtve.es/api/videos/123/subtitulos.json":
+			listings++
+			src := "https://www.rtve.es/resources/vtt/expired.vtt"
+			if listings > 1 {
+				src = "https://www.rtve.es/resources/vtt/fresh.vtt"
+			}
+			return newResponse(http.StatusOK, `{"page":{"items":[{"src":"`+src+`","lang":"es"}]}}`), nil
+		case "https://www.rtve.es/resources/vtt/expired.vtt":
+			return newResponse(http.StatusForbidden, ""), nil
+		case "https://www.rtve.es/resources/vtt/fresh.vtt":
+			return newResponse(http.StatusOK, "WEBVTT\n"), nil
+		}
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	dir := t.TempDir()
+	if _, err := s.DownloadSubtitles(&VideoMetadata{ID: "123"}, dir); err != nil {
+		t.Fatalf("Failed to download subtitles: %v", err)
+	}
+
+	if listings != 2 {
+		t.Errorf("Expected the listing to be resolved twice, got %d", listings)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "subs", "123_es.vtt"))
+	if err != nil {
+		t.Fatalf("Subtitle file not saved: %v", err)
+	}
+	if string(data) != "WEBVTT\n" {
+		t.Errorf("Unexpected subtitle content: %q", data)
+	}
+}
+
+func TestDownloadSubtitlesJoinsTrackErrors(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.String() {
+		case "https://api2.rtve.es/api/videos/123/subtitulos.json":
+			return newResponse(http.StatusOK, `{"page":{"items":[
+				{"src":"https://www.rtve.es/resources/vtt/es.vtt","lang":"es"},
+				{"src":"https://www.rtve.es/resources/vtt/en.vtt","lang":"en"},
+				{"src":"https://www.rtve.es/resources/vtt/ca.vtt","lang":"ca"}]}}`), nil
+		case "https://www.rtve.es/resources/vtt/es.vtt":
+			return newResponse(http.StatusOK, "WEBVTT\n"), nil
+		}
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	dir := t.TempDir()
+	_, err := s.DownloadSubtitles(&VideoMetadata{ID: "123"}, dir)
+	if err == nil {
+		t.Fatal("Expected an error for the missing tracks")
+	}
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a 404 *StatusError, got %v", err)
+	}
+
+	var trackErr *SubtitleDownloadError
+	if !errors.As(err, &trackErr) || trackErr.Lang != "en" || trackErr.URL != "https://www.rtve.es/resources/vtt/en.vtt" {
+		t.Errorf("Expected a *SubtitleDownloadError for the en track, got %v", err)
+	}
+
+	if msg := err.Error(); !strings.Contains(msg, "for en") || !strings.Contains(msg, "for ca") {
+		t.Errorf("Expected errors for both failed tracks, got %q", msg)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "subs", "123_es.vtt")); err != nil {
+		t.Errorf("Successful track not saved: %v", err)
+	}
+}
+
+func TestDownloadSubtitlesResults(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.String() {
+		case "https://api2.rtve.es/api/videos/123/subtitulos.json":
+			return newResponse(http.StatusOK, `{"page":{"items":[
+				{"src":"https://www.rtve.es/resources/vtt/es.vtt","lang":"es"},
+				{"src":"https://www.rtve.es/resources/vtt/en.vtt","lang":"en"}]}}`), nil
+		case "https://www.rtve.es/resources/vtt/es.vtt":
+			return newResponse(http.StatusOK, "WEBVTT\n"), nil
+		}
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	dir := t.TempDir()
+	results, err := s.DownloadSubtitles(&VideoMetadata{ID: "123"}, dir)
+	if err == nil {
+		t.Error("Expected the failed track to be reported in the error")
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %+v", results)
+	}
+
+	es, en := results[0], results[1]
+	if es.Lang != "es" || es.Err != nil || es.Path != filepath.Join(dir, "subs", "123_es.vtt") || es.Size != int64(len("WEBVTT\n")) {
+		t.Errorf("Unexpected result for es: %+v", es)
+	}
+	var trackErr *SubtitleDownloadError
+	if en.Lang != "en" || en.Path != "" || !errors.As(en.Err, &trackErr) {
+		t.Errorf("Unexpected result for en: %+v", en)
+	}
+}
+
+func TestSaveSubtitles(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() == "https://www.rtve.es/resources/vtt/es.vtt" {
+			// Latin-1 with a BOM-less "canción"
+			return newResponse(http.StatusOK, "WEBVTT\n\n00:00.000 --> 00:01.000\ncanci\xf3n\n"), nil
+		}
+		t.Errorf("Unexpected request %s, the listing is already fetched", req.URL)
+		return newResponse(http.StatusNotFound, ""), nil
+	})
+
+	dir := t.TempDir()
+	subtitles := &Subtitles{VideoID: "123", Subtitles: []SubtitleItem{{Src: "https://www.rtve.es/resources/vtt/es.vtt", Lang: "es"}}}
+	results, err := s.SaveSubtitles(&VideoMetadata{ID: "123"}, subtitles, dir)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("SaveSubtitles = %+v, %v", results, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "subs", "123_es.vtt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "canción") {
+		t.Errorf("Expected the track converted to UTF-8, got %q", data)
+	}
+
+	// Nothing to save
+	if results, err := s.SaveSubtitles(&VideoMetadata{ID: "124"}, &Subtitles{VideoID: "124"}, dir); err != nil || results != nil {
+		t.Errorf("Expected no results for an empty listing, got %+v, %v", results, err)
+	}
+}
+
+func TestDownloadSubtitlesNoSubtitles(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusOK, `{"page":{"items":[]}}`), nil
+	})
+
+	_, err := s.DownloadSubtitles(&VideoMetadata{ID: "123"}, t.TempDir())
+	if !errors.Is(err, ErrNoSubtitles) {
+		t.Errorf("Expected ErrNoSubtitles, got %v", err)
+	}
+}
+
+func TestGeoBlockedError(t *testing.T) {
+	blocked := &VideoMetadata{}
+	if err := json.Unmarshal([]byte(`{"id":"123","allowedInCountry":false}`), blocked); err != nil {
+		t.Fatal(err)
+	}
+	allowed := &VideoMetadata{ID: "123"}
+
+	forbidden := &StatusError{StatusCode: http.StatusForbidden, Err: ErrForbidden}
+	gone := &StatusError{StatusCode: http.StatusGone}
+	notFound := &StatusError{StatusCode: http.StatusNotFound, Err: ErrPageNotFound}
+
+	for _, tc := range []struct {
+		name      string
+		meta      *VideoMetadata
+		err       error
+		geo       bool
+		forbidden bool
+	}{
+		{"403 for a blocked video", blocked, forbidden, true, true},
+		{"410 for a blocked video", blocked, gone, true, false},
+		{"404 for a blocked video", blocked, notFound, false, false},
+		{"403 for an allowed video", allowed, forbidden, false, true},
+		{"410 for an allowed video", allowed, gone, false, false},
+	} {
+		err := geoBlockedError(tc.meta, tc.err)
+		if errors.Is(err, ErrGeoBlocked) != tc.geo || errors.Is(err, ErrForbidden) != tc.forbidden {
+			t.Errorf("%s: got %v, expected ErrGeoBlocked=%v ErrForbidden=%v", tc.name, err, tc.geo, tc.forbidden)
+		}
+	}
+
+	// Requests made without metadata report a plain 403 as ErrForbidden
+	s := NewScrapper("telediario-2", WithTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newResponse(http.StatusForbidden, "Not available in your country"), nil
+	})))
+	_, err := s.ScrapePage(0)
+	if !errors.Is(err, ErrForbidden) || errors.Is(err, ErrGeoBlocked) {
+		t.Errorf("Expected ErrForbidden only for a listing page, got %v", err)
+	}
+}
+
+func TestDownloadSubtitlesGeoBlocked(t *testing.T) {
+	s := NewScrapper("telediario-2")
+	s.client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/subtitulos.json") {
+			return newResponse(http.StatusOK, `{"page":{"items":[{"src":"https://www.rtve.es/resources/vtt/es.vtt","lang":"es"}]}}`), nil
+		}
+		return newResponse(http.StatusForbidden, ""), nil
+	})
+
+	blocked := &VideoMetadata{}
+	if err := json.Unmarshal([]byte(`{"id":"123","allowedInCountry":false}`), blocked); err != nil {
+		t.Fatal(err)
+	}
+	if !blocked.GeoBlocked() {
+		t.Fatal("Expected the video to be flagged as geo-blocked")
+	}
+
+	_, err := s.DownloadSubtitles(blocked, t.TempDir())
+	if !errors.Is(err, ErrGeoBlocked) {
+		t.Errorf("Expected ErrGeoBlocked, got %v", err)
+	}
+
+	// Forbidden responses for videos available everywhere aren't geo-blocking
+	_, err = s.DownloadSubtitles(&VideoMetadata{ID: "123"}, t.TempDir())
+	if err == nil || errors.Is(err, ErrGeoBlocked) {
+		t.Errorf("Expected a non geo-blocking error, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package rtve
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"strings"
+)
+
+// Transcript returns the text of cues as a plain transcript, without
+// timestamps or markup, with one sentence per line (see Sentences).
+func Transcript(cues []Cue) string {
+	var b strings.Builder
+	for _, s := range Sentences(cues) {
+		b.WriteString(html.UnescapeString(s.Text))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// TranscriptFile returns the name of the transcript file saved by
+// SaveTranscript for a subtitle file, the same name with a .txt
+// extension, e.g. subs/16492499_es.txt for subs/16492499_es.vtt.
+func TranscriptFile(vttPath string) string {
+	return strings.TrimSuffix(vttPath, ".vtt") + ".txt"
+}
+
+// SaveTranscript parses the WebVTT file at vttPath and saves its plain
+// transcript next to it, see Transcript and TranscriptFile. It returns the
+// path of the transcript.
+func SaveTranscript(vttPath string) (string, error) {
+	f, err := os.Open(vttPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	cues, err := ParseVTT(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", vttPath, err)
+	}
+
+	path := TranscriptFile(vttPath)
+	if err := os.WriteFile(path, []byte(Transcript(cues)), 0644); err != nil {
+		return "", fmt.Errorf("writing transcript: %w", err)
+	}
+
+	return path, nil
+}
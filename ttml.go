@@ -0,0 +1,303 @@
+package rtve
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ttmlParameterNS is the namespace of TTML timing parameters such as
+// ttp:frameRate.
+const ttmlParameterNS = "http://www.w3.org/ns/ttml#parameter"
+
+// IsTTML reports whether content is a TTML (or DFXP, its older name)
+// document rather than WebVTT, judging by its root element.
+func IsTTML(content []byte) bool {
+	content = bytes.TrimPrefix(content, bomUTF8)
+	if !bytes.HasPrefix(bytes.TrimSpace(content), []byte("<")) {
+		return false
+	}
+
+	dec := newTTMLDecoder(bytes.NewReader(content))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local == "tt"
+		}
+	}
+}
+
+func newTTMLDecoder(r io.Reader) *xml.Decoder {
+	dec := xml.NewDecoder(r)
+	// Content is converted to UTF-8 before parsing, whatever the XML
+	// declaration says
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return dec
+}
+
+// ttmlTiming is the active time interval of a TTML element.
+type ttmlTiming struct {
+	begin time.Duration
+	end   time.Duration
+	// hasEnd is false for elements, like the body of most documents, that
+	// last until the end of the document
+	hasEnd bool
+}
+
+// ParseTTML parses the paragraphs of a UTF-8 TTML/DFXP subtitle document
+// as cues. Line breaks are kept as "\n" and styling spans are dropped.
+// Timing on ancestor elements, clock times with frames and offset times
+// (such as "12.5s" or "300t") are supported.
+func ParseTTML(r io.Reader) ([]Cue, error) {
+	dec := newTTMLDecoder(r)
+
+	frameRate, tickRate := 30.0, 1.0
+	var stack []ttmlTiming
+	var cues []Cue
+	var text *strings.Builder
+	var cue Cue
+	sawRoot := false
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing TTML: %w", err)
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if !sawRoot {
+				if tok.Name.Local != "tt" {
+					return nil, errors.New("not a TTML document: missing tt root element")
+				}
+				sawRoot = true
+				frameRate, tickRate = ttmlRates(tok.Attr, frameRate, tickRate)
+			}
+
+			parent := ttmlTiming{}
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			timing, err := elementTiming(tok.Attr, parent, frameRate, tickRate)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, timing)
+
+			switch {
+			case tok.Name.Local == "p":
+				text = &strings.Builder{}
+				cue = Cue{Start: timing.begin, End: timing.end}
+				if !timing.hasEnd {
+					cue.End = timing.begin
+				}
+			case tok.Name.Local == "br" && text != nil:
+				text.WriteByte('\n')
+			}
+
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			if tok.Name.Local == "p" && text != nil {
+				cue.Text = normalizeTTMLText(text.String())
+				if cue.Text != "" && cue.End > cue.Start {
+					cues = append(cues, cue)
+				}
+				text = nil
+			}
+
+		case xml.CharData:
+			if text != nil {
+				text.Write(tok)
+			}
+		}
+	}
+
+	if !sawRoot {
+		return nil, errors.New("not a TTML document: missing tt root element")
+	}
+
+	return cues, nil
+}
+
+// ttmlRates returns the frame and tick rates declared on the tt element.
+func ttmlRates(attrs []xml.Attr, frameRate, tickRate float64) (float64, float64) {
+	for _, attr := range attrs {
+		if attr.Name.Space != ttmlParameterNS {
+			continue
+		}
+		v, err := strconv.ParseFloat(attr.Value, 64)
+		if err != nil || v <= 0 {
+			continue
+		}
+		switch attr.Name.Local {
+		case "frameRate":
+			frameRate = v
+		case "tickRate":
+			tickRate = v
+		}
+	}
+	return frameRate, tickRate
+}
+
+// elementTiming resolves the begin, end and dur attributes of an element
+// against the timing of its parent.
+func elementTiming(attrs []xml.Attr, parent ttmlTiming, frameRate, tickRate float64) (ttmlTiming, error) {
+	timing := ttmlTiming{begin: parent.begin, end: parent.end, hasEnd: parent.hasEnd}
+
+	var begin, end, dur string
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case "begin":
+			begin = attr.Value
+		case "end":
+			end = attr.Value
+		case "dur":
+			dur = attr.Value
+		}
+	}
+
+	if begin != "" {
+		d, err := parseTTMLTime(begin, frameRate, tickRate)
+		if err != nil {
+			return timing, err
+		}
+		timing.begin = parent.begin + d
+	}
+
+	switch {
+	case end != "":
+		d, err := parseTTMLTime(end, frameRate, tickRate)
+		if err != nil {
+			return timing, err
+		}
+		timing.end, timing.hasEnd = parent.begin+d, true
+	case dur != "":
+		d, err := parseTTMLTime(dur, frameRate, tickRate)
+		if err != nil {
+			return timing, err
+		}
+		timing.end, timing.hasEnd = timing.begin+d, true
+	}
+
+	// Children can't outlast their parent
+	if parent.hasEnd && timing.end > parent.end {
+		timing.end = parent.end
+	}
+
+	return timing, nil
+}
+
+// parseTTMLTime parses a TTML time expression: a clock time such as
+// "00:00:01.500" or "00:00:01:12" (with frames), or an offset time such as
+// "1.5s", "1500ms" or "45f".
+func parseTTMLTime(v string, frameRate, tickRate float64) (time.Duration, error) {
+	v = strings.TrimSpace(v)
+
+	if strings.Contains(v, ":") {
+		parts := strings.Split(v, ":")
+		if len(parts) != 3 && len(parts) != 4 {
+			return 0, fmt.Errorf("invalid TTML time %q", v)
+		}
+
+		var seconds float64
+		for i, part := range parts[:3] {
+			n, err := strconv.ParseFloat(part, 64)
+			if err != nil || n < 0 || (i < 2 && strings.Contains(part, ".")) {
+				return 0, fmt.Errorf("invalid TTML time %q", v)
+			}
+			seconds = seconds*60 + n
+		}
+		if len(parts) == 4 {
+			frames, err := strconv.ParseFloat(parts[3], 64)
+			if err != nil || frames < 0 {
+				return 0, fmt.Errorf("invalid TTML time %q", v)
+			}
+			seconds += frames / frameRate
+		}
+
+		return secondsDuration(v, seconds)
+	}
+
+	units := []struct {
+		suffix  string
+		seconds float64
+	}{
+		{"ms", 0.001},
+		{"h", 3600},
+		{"m", 60},
+		{"s", 1},
+		{"f", 1 / frameRate},
+		{"t", 1 / tickRate},
+	}
+	for _, unit := range units {
+		number, ok := strings.CutSuffix(v, unit.suffix)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseFloat(number, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid TTML time %q", v)
+		}
+		return secondsDuration(v, n*unit.seconds)
+	}
+
+	return 0, fmt.Errorf("invalid TTML time %q", v)
+}
+
+// secondsDuration converts the seconds of the time expression v to a
+// duration rounded to milliseconds, the precision of WebVTT timestamps.
+func secondsDuration(v string, seconds float64) (time.Duration, error) {
+	// Also rejects NaN, which strconv accepts
+	if !(seconds >= 0 && seconds <= maxTTMLSeconds) {
+		return 0, fmt.Errorf("invalid TTML time %q", v)
+	}
+	return time.Duration(seconds*1000+0.5) * time.Millisecond, nil
+}
+
+// maxTTMLSeconds bounds time expressions well within time.Duration.
+const maxTTMLSeconds = 1e9
+
+// normalizeTTMLText collapses the whitespace of each line of a paragraph,
+// as TTML's default xml:space handling does.
+func normalizeTTMLText(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// EncodeVTT returns cues as a WebVTT file.
+func EncodeVTT(cues []Cue) []byte {
+	var b bytes.Buffer
+	b.WriteString("WEBVTT\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "\n%s --> %s\n%s\n", FormatVTTTimestamp(cue.Start), FormatVTTTimestamp(cue.End), cue.Text)
+	}
+	return b.Bytes()
+}
+
+// ttmlToVTT converts a TTML subtitle document to WebVTT.
+func ttmlToVTT(content []byte) ([]byte, error) {
+	cues, err := ParseTTML(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	return EncodeVTT(cues), nil
+}
@@ -0,0 +1,124 @@
+package rtve
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testTTML = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttp="http://www.w3.org/ns/ttml#parameter" ttp:frameRate="25" ttp:tickRate="10000000">
+  <body>
+    <div begin="10s">
+      <p begin="00:00:01.000" end="00:00:03.500"><span style="yellow">Buenas tardes,</span><br/>
+        bienvenidos   al Telediario.</p>
+      <p begin="00:00:04:05" dur="2s">El Gobierno ha aprobado hoy</p>
+      <p begin="80000000t" end="100000000t">la nueva ley.</p>
+      <p begin="1s" end="1s">Vacío</p>
+    </div>
+  </body>
+</tt>
+`
+
+func TestIsTTML(t *testing.T) {
+	if !IsTTML([]byte(testTTML)) {
+		t.Error("Expected TTML to be detected")
+	}
+	if !IsTTML([]byte("\ufeff<tt xmlns=\"http://www.w3.org/2006/10/ttaf1\"><body/></tt>")) {
+		t.Error("Expected DFXP with a BOM to be detected")
+	}
+	for _, content := range []string{testVTT, "<html><body/></html>", "", "<tt"} {
+		if IsTTML([]byte(content)) {
+			t.Errorf("Expected %q not to be detected as TTML", content)
+		}
+	}
+}
+
+func TestParseTTML(t *testing.T) {
+	cues, err := ParseTTML(strings.NewReader(testTTML))
+	if err != nil {
+		t.Fatalf("ParseTTML failed: %v", err)
+	}
+
+	expected := []Cue{
+		{Start: 11 * time.Second, End: 13500 * time.Millisecond, Text: "Buenas tardes,\nbienvenidos al Telediario."},
+		{Start: 14200 * time.Millisecond, End: 16200 * time.Millisecond, Text: "El Gobierno ha aprobado hoy"},
+		{Start: 18 * time.Second, End: 20 * time.Second, Text: "la nueva ley."},
+	}
+	if len(cues) != len(expected) {
+		t.Fatalf("Expected %d cues, got %+v", len(expected), cues)
+	}
+	for i, cue := range cues {
+		if cue != expected[i] {
+			t.Errorf("Cue %d: expected %+v, got %+v", i, expected[i], cue)
+		}
+	}
+
+	if _, err := ParseTTML(strings.NewReader("<html/>")); err == nil {
+		t.Error("Expected an error for a non-TTML document")
+	}
+	if _, err := ParseTTML(strings.NewReader(`<tt><body><p begin="soon">x</p></body></tt>`)); err == nil {
+		t.Error("Expected an error for an invalid time expression")
+	}
+}
+
+func TestParseTTMLTime(t *testing.T) {
+	tests := map[string]time.Duration{
+		"01:02:03.250": time.Hour + 2*time.Minute + 3250*time.Millisecond,
+		"00:00:01:15":  1500 * time.Millisecond,
+		"1.5s":         1500 * time.Millisecond,
+		"250ms":        250 * time.Millisecond,
+		"2m":           2 * time.Minute,
+		"1h":           time.Hour,
+		"45f":          1500 * time.Millisecond,
+		"5t":           500 * time.Millisecond,
+	}
+	for v, expected := range tests {
+		got, err := parseTTMLTime(v, 30, 10)
+		if err != nil || got != expected {
+			t.Errorf("parseTTMLTime(%q) = %v, %v, want %v", v, got, err, expected)
+		}
+	}
+
+	for _, v := range []string{"", "1x", "1:2", "-1s", "00:00.5:01", "NaNs", "Infs", "1e300s"} {
+		if _, err := parseTTMLTime(v, 30, 10); err == nil {
+			t.Errorf("Expected an error for %q", v)
+		}
+	}
+}
+
+func TestDownloadSubtitlesTTML(t *testing.T) {
+	fixtures := fixtureTransport(t)
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if filepath.Ext(req.URL.Path) == ".vtt" {
+			return newResponse(http.StatusOK, testTTML), nil
+		}
+		return fixtures(req)
+	})
+
+	s := NewScrapper("telediario-2", WithTransport(transport))
+	meta, err := s.DownloadVideoMeta("16492499")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if _, err := s.DownloadSubtitles(meta, dir); err != nil {
+		t.Fatalf("DownloadSubtitles failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "subs", "16492499_es.vtt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cues, err := ParseVTT(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("Saved subtitles aren't valid WebVTT: %v\n%s", err, data)
+	}
+	if len(cues) != 3 || cues[0].Text != "Buenas tardes,\nbienvenidos al Telediario." {
+		t.Errorf("Unexpected cues: %+v", cues)
+	}
+}
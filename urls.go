@@ -1,5 +1,22 @@
 package rtve
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// showsMu guards urlMap, which RegisterShow can change at any time.
+var showsMu sync.RWMutex
+
 var urlMap = map[string]*Show{
 	"telediario-2": {
 		ID:    "135930",
@@ -26,20 +43,174 @@ var urlMap = map[string]*Show{
 const ApiURL = "https://api2.rtve.es/api/videos/%s.json"
 const SubsURL = "https://api2.rtve.es/api/videos/%s/subtitulos.json"
 
+// MediaURL is RTVE's ztnr endpoint, which publishes the media URLs of a
+// video encoded in a PNG image. See DecodeMediaPNG.
+const MediaURL = "https://www.rtve.es/ztnr/movil/thumbnail/rtveplayw/videos/%s.png?q=v2"
+
+// Show describes where the episodes of an RTVE program are listed.
 type Show struct {
-	ID    string
-	URL   string
-	Regex string
+	// ID is the RTVE program ID, e.g. "1631" for informe-semanal
+	ID string `json:"id"`
+	// URL is the listing page URL, with a %d verb for the page number
+	URL string `json:"url,omitempty"`
+	// Regex matches the URLs of the episodes in a listing page
+	Regex string `json:"regex,omitempty"`
+	// Media is the media type the program publishes, MediaVideo if empty
+	Media MediaType `json:"media,omitempty"`
+}
+
+// RegisterShow makes an RTVE program available to Scrapper, the api
+// package and ListShows under name, the slug RTVE Play uses in the URLs of
+// its episodes, e.g. "aqui-la-tierra" for
+// https://www.rtve.es/play/videos/aqui-la-tierra/.
+//
+// Only show.ID is required: the listing URL and the episode URL pattern
+// default to the ones of RTVE Play programs, under videos or, for radio
+// programs (Media is MediaAudio), audios. Registering an existing name
+// replaces the show, e.g. to update a built-in one after RTVE moved it.
+func RegisterShow(name string, show *Show) error {
+	s, err := completeShow(name, show)
+	if err != nil {
+		return err
+	}
+
+	showsMu.Lock()
+	defer showsMu.Unlock()
+	urlMap[name] = s
+	return nil
+}
+
+// LoadShows registers the shows defined in a JSON file, as RegisterShow
+// does, and returns their names, sorted. The file maps show names to their
+// definition:
+//
+//	{
+//	  "aqui-la-tierra": {"id": "48150"},
+//	  "no-es-un-dia-cualquiera": {"id": "<program ID>", "media": "audio"},
+//	  "informe-semanal": {
+//	    "id": "1631",
+//	    "url": "https://www.rtve.es/play/videos/modulos/capitulos/1631/?page=%d",
+//	    "regex": "https://www\\.rtve\\.es/play/videos/informe-semanal/[^/]+/[0-9]+/"
+//	  }
+//	}
+//
+// No show is registered if any of them is invalid.
+func LoadShows(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading shows file: %w", err)
+	}
+
+	var shows map[string]*Show
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Catch misspelled fields, which would silently fall back to defaults
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&shows); err != nil {
+		return nil, fmt.Errorf("parsing shows file %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(shows))
+	for name, show := range shows {
+		s, err := completeShow(name, show)
+		if err != nil {
+			return nil, fmt.Errorf("shows file %s: %w", path, err)
+		}
+		shows[name] = s
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	showsMu.Lock()
+	defer showsMu.Unlock()
+	for name, show := range shows {
+		urlMap[name] = show
+	}
+	return names, nil
+}
+
+// DefaultShowsFile returns the shows file the rtve-subs command loads when
+// it exists, rtve-go/shows.json in the user's configuration directory (see
+// os.UserConfigDir), e.g. ~/.config/rtve-go/shows.json on Linux.
+func DefaultShowsFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "rtve-go", "shows.json"), nil
 }
 
+// programEpisodeRegex matches the URLs of RTVE Play episodes of any
+// program.
+const programEpisodeRegex = `https://www\.rtve\.es/play/videos/[^/"?#]+/[^/"?#]+/[0-9]+/`
+
+// ProgramShow returns the definition of the RTVE program with the given
+// numeric ID, for programs whose name isn't known: its listing URL is
+// built from the ID and its episodes are matched by a pattern for any
+// RTVE Play episode URL. See NewScrapperByID.
+func ProgramShow(programID string) (*Show, error) {
+	if _, err := strconv.ParseUint(programID, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid program ID %q: must be numeric", programID)
+	}
+
+	return &Show{
+		ID:    programID,
+		URL:   fmt.Sprintf("https://www.rtve.es/play/videos/modulos/capitulos/%s/?page=%%d", programID),
+		Regex: programEpisodeRegex,
+	}, nil
+}
+
+// completeShow validates a show definition, returning a copy with the
+// default listing URL and episode URL pattern filled in.
+func completeShow(name string, show *Show) (*Show, error) {
+	if name == "" {
+		return nil, errors.New("show name is required")
+	}
+	if show == nil || (show.ID == "" && show.URL == "") {
+		return nil, fmt.Errorf("show %s: an ID or a listing URL is required", name)
+	}
+
+	s := *show
+	media, err := ParseMediaType(string(s.Media))
+	if err != nil {
+		return nil, fmt.Errorf("show %s: %w", name, err)
+	}
+	// RTVE Play lists audios under /play/audios/
+	section := "videos"
+	if media == MediaAudio {
+		section = "audios"
+	}
+
+	if s.URL == "" {
+		s.URL = fmt.Sprintf("https://www.rtve.es/play/%s/modulos/capitulos/%s/?page=%%d", section, s.ID)
+	}
+	if s.Regex == "" {
+		s.Regex = fmt.Sprintf(`https://www\.rtve\.es/play/%s/%s/[^/]+/[0-9]+/`, section, regexp.QuoteMeta(name))
+	}
+	if !strings.Contains(s.URL, "%d") {
+		return nil, fmt.Errorf("show %s: listing URL %q has no %%d page number", name, s.URL)
+	}
+	if _, err := regexp.Compile(s.Regex); err != nil {
+		return nil, fmt.Errorf("show %s: invalid episode URL pattern: %w", name, err)
+	}
+	return &s, nil
+}
+
+// ShowMap returns the show registered under name, or nil.
 func ShowMap(name string) *Show {
-	return (urlMap[name])
+	showsMu.RLock()
+	defer showsMu.RUnlock()
+	return urlMap[name]
 }
 
+// ListShows returns the names of the registered shows, sorted.
 func ListShows() []string {
+	showsMu.RLock()
+	defer showsMu.RUnlock()
+
 	var shows []string
-	for k, _ := range urlMap {
+	for k := range urlMap {
 		shows = append(shows, k)
 	}
+	sort.Strings(shows)
 	return shows
 }
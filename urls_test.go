@@ -0,0 +1,157 @@
+package rtve
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRegisterShow(t *testing.T) {
+	t.Cleanup(func() {
+		showsMu.Lock()
+		delete(urlMap, "aqui-la-tierra")
+		showsMu.Unlock()
+	})
+
+	if err := RegisterShow("aqui-la-tierra", &Show{ID: "48150"}); err != nil {
+		t.Fatalf("RegisterShow failed: %v", err)
+	}
+
+	if !slices.Contains(ListShows(), "aqui-la-tierra") {
+		t.Errorf("Registered show missing from ListShows: %v", ListShows())
+	}
+
+	s := NewScrapper("aqui-la-tierra")
+	if u := s.pageURL(2); u != "https://www.rtve.es/play/videos/modulos/capitulos/48150/?page=2" {
+		t.Errorf("Unexpected listing URL %s", u)
+	}
+
+	html := `<a href="https://www.rtve.es/play/videos/aqui-la-tierra/la-vendimia/16500000/">
+<a href="https://www.rtve.es/play/videos/telediario-2/14-03-25/16492499/">`
+	videos, err := s.scrape(html)
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	if len(videos) != 1 || videos[0].ID != "16500000" {
+		t.Errorf("Expected only the show's episode, got %+v", videos)
+	}
+}
+
+func TestNewScrapperByID(t *testing.T) {
+	s, err := NewScrapperByID("48150", WithPageSize(20))
+	if err != nil {
+		t.Fatalf("NewScrapperByID failed: %v", err)
+	}
+	if s.Program != "48150" || ShowMap("48150") != nil {
+		t.Errorf("Expected an unregistered show named after the ID, got %q", s.Program)
+	}
+	if u := s.pageURL(2); u != "https://www.rtve.es/play/videos/modulos/capitulos/48150/?page=2&size=20" {
+		t.Errorf("Unexpected listing URL %s", u)
+	}
+
+	html := `<a href="https://www.rtve.es/play/videos/aqui-la-tierra/la-vendimia/16500000/">
+<a href="https://www.rtve.es/play/videos/aqui-la-tierra/">
+<a href="https://www.rtve.es/play/videos/aqui-la-tierra/la-vendimia/16500000/?t=10">`
+	videos, err := s.scrape(html)
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	if len(videos) != 1 || videos[0].ID != "16500000" {
+		t.Errorf("Expected the program's episode, got %+v", videos)
+	}
+
+	for _, id := range []string{"", "aqui-la-tierra", "-1", "48150/"} {
+		if _, err := NewScrapperByID(id); err == nil {
+			t.Errorf("Expected error for program ID %q", id)
+		}
+	}
+}
+
+func TestRegisterShowInvalid(t *testing.T) {
+	tests := map[string]*Show{
+		"":         {ID: "1"},
+		"no-id":    {},
+		"nil":      nil,
+		"no-page":  {URL: "https://www.rtve.es/play/videos/modulos/capitulos/1/"},
+		"bad-expr": {ID: "1", Regex: "("},
+	}
+
+	for name, show := range tests {
+		if err := RegisterShow(name, show); err == nil {
+			t.Errorf("Expected error registering %q", name)
+		}
+		if name != "" && ShowMap(name) != nil {
+			t.Errorf("Invalid show %q was registered", name)
+		}
+	}
+}
+
+func TestScrapePageUnknownShow(t *testing.T) {
+	s := NewScrapper("no-such-show")
+	if _, err := s.ScrapePage(0); err == nil {
+		t.Error("Expected error for an unregistered show")
+	}
+}
+
+func TestLoadShows(t *testing.T) {
+	t.Cleanup(func() {
+		showsMu.Lock()
+		delete(urlMap, "aqui-la-tierra")
+		delete(urlMap, "la-noche-de")
+		showsMu.Unlock()
+	})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "shows.json")
+	data := `{
+  "aqui-la-tierra": {"id": "48150"},
+  "la-noche-de": {"id": "1", "url": "https://www.rtve.es/play/videos/modulos/capitulos/1/?page=%d&order=asc", "regex": "https://www\\.rtve\\.es/play/videos/la-noche-de/[0-9]+/"}
+}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := LoadShows(path)
+	if err != nil {
+		t.Fatalf("LoadShows failed: %v", err)
+	}
+	if !slices.Equal(names, []string{"aqui-la-tierra", "la-noche-de"}) {
+		t.Errorf("Unexpected shows loaded: %v", names)
+	}
+
+	if show := ShowMap("aqui-la-tierra"); show == nil || show.URL != "https://www.rtve.es/play/videos/modulos/capitulos/48150/?page=%d" {
+		t.Errorf("Expected the default listing URL, got %+v", show)
+	}
+	if show := ShowMap("la-noche-de"); show == nil || show.Regex != `https://www\.rtve\.es/play/videos/la-noche-de/[0-9]+/` {
+		t.Errorf("Expected the configured pattern, got %+v", show)
+	}
+}
+
+func TestLoadShowsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"typo.json":    `{"nueva-serie": {"id": "1", "regexp": "x"}}`,
+		"invalid.json": `{"nueva-serie": {"id": "1"}, "rota": {"id": "2", "regex": "("}}`,
+		"broken.json":  `{`,
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadShows(path); err == nil {
+			t.Errorf("Expected error loading %s", name)
+		}
+	}
+
+	if ShowMap("nueva-serie") != nil {
+		t.Error("Expected no show registered from invalid files")
+	}
+
+	if _, err := LoadShows(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist for a missing file, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package rtve
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// Version, Commit and BuildDate identify the build. Release builds set
+// them at link time:
+//
+//	go build -ldflags "-X github.com/rubiojr/rtve-go.Version=v1.2.0 \
+//		-X github.com/rubiojr/rtve-go.Commit=$(git rev-parse HEAD) \
+//		-X github.com/rubiojr/rtve-go.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Left empty, ReadBuildInfo falls back to what the Go toolchain records.
+var (
+	Version   string
+	Commit    string
+	BuildDate string
+)
+
+// BuildInfo describes the build of the running binary.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"buildDate,omitempty"`
+	GoVersion string `json:"goVersion,omitempty"`
+}
+
+// ReadBuildInfo returns the build of the running binary: the values set
+// with -ldflags if any, otherwise the module version and VCS revision the
+// Go toolchain embeds, e.g. for binaries built with go install. The
+// version is "devel" when unknown.
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{Version: Version, Commit: Commit, BuildDate: BuildDate}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.GoVersion = bi.GoVersion
+		if info.Version == "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, setting := range bi.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "devel"
+	}
+
+	return info
+}
+
+// String formats the build as "v1.2.0 (commit abc1234, built 2025-03-14)".
+func (b BuildInfo) String() string {
+	var details []string
+	if b.Commit != "" {
+		commit := b.Commit
+		if len(commit) > 12 {
+			commit = commit[:12]
+		}
+		details = append(details, "commit "+commit)
+	}
+	if b.BuildDate != "" {
+		details = append(details, "built "+b.BuildDate)
+	}
+	if b.GoVersion != "" {
+		details = append(details, b.GoVersion)
+	}
+
+	if len(details) == 0 {
+		return b.Version
+	}
+	return fmt.Sprintf("%s (%s)", b.Version, strings.Join(details, ", "))
+}
@@ -0,0 +1,28 @@
+package rtve
+
+import "testing"
+
+func TestReadBuildInfo(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, BuildDate = v, c, d }(Version, Commit, BuildDate)
+
+	Version, Commit, BuildDate = "v1.2.0", "0123456789abcdef", "2025-03-14T21:00:00Z"
+	info := ReadBuildInfo()
+	if info.Version != "v1.2.0" || info.Commit != "0123456789abcdef" || info.BuildDate != "2025-03-14T21:00:00Z" {
+		t.Errorf("Link time values not used: %+v", info)
+	}
+
+	info.GoVersion = "go1.23.0"
+	expected := "v1.2.0 (commit 0123456789ab, built 2025-03-14T21:00:00Z, go1.23.0)"
+	if got := info.String(); got != expected {
+		t.Errorf("String() = %q, want %q", got, expected)
+	}
+
+	Version = ""
+	if info := ReadBuildInfo(); info.Version == "" {
+		t.Error("Expected a version even without link time values")
+	}
+
+	if got := (BuildInfo{Version: "devel"}).String(); got != "devel" {
+		t.Errorf("String() = %q, want devel", got)
+	}
+}
@@ -0,0 +1,243 @@
+package rtve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionsFile is the name of the file, stored in a day folder, that
+// records the episodes RTVE re-published on that day, e.g. with corrected
+// subtitles, under a new video ID.
+const VersionsFile = "versions.json"
+
+// EpisodeVersions lists the versions of an episode RTVE published more
+// than once on the same day. Every version is kept: their files are told
+// apart by their video ID, e.g. video_<id>.json and subs/<id>_es.vtt.
+type EpisodeVersions struct {
+	// Title is the title the versions share
+	Title string `json:"title"`
+	// IDs are the video IDs of the versions, oldest first, so version n
+	// is IDs[n-1]
+	IDs []string `json:"ids"`
+	// Current is the ID of the newest version, which replaces the others
+	Current string `json:"current"`
+	// UpdatedAt is when the last version was archived
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Version returns the version number of videoID, starting at 1, or 0 if
+// it isn't one of the versions.
+func (v EpisodeVersions) Version(videoID string) int {
+	for i, id := range v.IDs {
+		if id == videoID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ReadVersions returns the re-published episodes recorded in a day folder.
+// It returns an empty slice if no episode was re-published.
+func ReadVersions(folder string) ([]EpisodeVersions, error) {
+	data, err := os.ReadFile(filepath.Join(folder, VersionsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return []EpisodeVersions{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading episode versions: %w", err)
+	}
+
+	var versions []EpisodeVersions
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("parsing episode versions: %w", err)
+	}
+	return versions, nil
+}
+
+// CurrentVersion returns the ID of the current version of the episode
+// videoID, archived in folder, belongs to: videoID itself unless RTVE
+// re-published the episode.
+func CurrentVersion(folder, videoID string) (string, error) {
+	versions, err := ReadVersions(folder)
+	if err != nil {
+		return "", err
+	}
+	for _, v := range versions {
+		if v.Version(videoID) > 0 {
+			return v.Current, nil
+		}
+	}
+	return videoID, nil
+}
+
+// recordVersion checks whether meta, just archived in folder, re-publishes
+// an episode archived there before, with the same title and publication
+// day, and records the versions in the folder's VersionsFile. Every
+// version's subtitles are also saved with a version suffix, see
+// VersionedSubtitleFile. It returns the IDs of the versions meta replaces.
+func (s *Scrapper) recordVersion(meta *VideoMetadata, folder string) ([]string, error) {
+	title := normalizeTitle(meta.LongTitle)
+	day := publicationDay(meta)
+	if title == "" || day == "" {
+		return nil, nil
+	}
+
+	// Most episodes are published once: only take the lock, and leave a
+	// lock file behind, once the folder holds another version
+	same, err := sameEpisode(folder, title, day)
+	if err != nil || len(same) < 2 {
+		return nil, err
+	}
+
+	// Concurrent downloads into the same folder must not lose versions,
+	// so the versions are listed again while holding the lock
+	lock, err := Lock(filepath.Join(folder, VersionsFile+".lock"), true)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	same, err = sameEpisode(folder, title, day)
+	if err != nil {
+		return nil, err
+	}
+
+	sortVersions(same)
+	entry := EpisodeVersions{Title: meta.LongTitle, UpdatedAt: time.Now().UTC()}
+	for _, v := range same {
+		entry.IDs = append(entry.IDs, v.ID)
+	}
+	entry.Current = entry.IDs[len(entry.IDs)-1]
+
+	versions, err := ReadVersions(folder)
+	if err != nil {
+		return nil, err
+	}
+	// Folders can hold episodes of other days with the same title, e.g.
+	// with a {slug} layout, so entries are matched by their IDs
+	kept := versions[:0]
+	for _, v := range versions {
+		if !slices.ContainsFunc(v.IDs, func(id string) bool { return entry.Version(id) > 0 }) {
+			kept = append(kept, v)
+		}
+	}
+	kept = append(kept, entry)
+
+	jsonData, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling episode versions: %w", err)
+	}
+	if err := s.writeFile(filepath.Join(folder, VersionsFile), jsonData, true); err != nil {
+		return nil, fmt.Errorf("writing episode versions: %w", err)
+	}
+
+	for n, id := range entry.IDs {
+		if err := s.saveVersionedSubtitles(folder, id, n+1); err != nil {
+			return nil, err
+		}
+	}
+
+	var replaced []string
+	for _, id := range entry.IDs {
+		if id != entry.Current {
+			replaced = append(replaced, id)
+		}
+	}
+	return replaced, nil
+}
+
+// VersionedSubtitleFile returns the name, in the subs folder, of the copy
+// of a re-published episode's subtitles kept with its version number, e.g.
+// 16492499_es.v1.vtt. Tools reading the archive use the <id>_<lang>.vtt
+// file and ignore these copies.
+func VersionedSubtitleFile(videoID, lang string, version int) string {
+	return fmt.Sprintf("%s_%s.v%d.vtt", videoID, lang, version)
+}
+
+// saveVersionedSubtitles saves a copy of the subtitles of version n of an
+// episode, videoID, with a version suffix.
+func (s *Scrapper) saveVersionedSubtitles(folder, videoID string, n int) error {
+	tracks, err := downloadedSubtitles(folder, videoID)
+	if err != nil {
+		return err
+	}
+	for _, track := range tracks {
+		data, err := os.ReadFile(track.path)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(folder, "subs", VersionedSubtitleFile(videoID, track.lang, n))
+		if err := s.writeFile(path, data, false); err != nil {
+			return fmt.Errorf("saving version %d of %s subtitles: %w", n, videoID, err)
+		}
+	}
+	return nil
+}
+
+// sameEpisode returns the metadata of the videos archived in folder whose
+// normalized title is title, published on day.
+func sameEpisode(folder, title, day string) ([]*VideoMetadata, error) {
+	paths, err := filepath.Glob(filepath.Join(folder, "video_*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var same []*VideoMetadata
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		other := &VideoMetadata{}
+		if err := json.Unmarshal(data, other); err != nil {
+			// Damaged files are reported by the tools that read them
+			continue
+		}
+		if normalizeTitle(other.LongTitle) == title && publicationDay(other) == day {
+			same = append(same, other)
+		}
+	}
+	return same, nil
+}
+
+// publicationDay returns the day meta was published on, or "" if its
+// publication date can't be parsed.
+func publicationDay(meta *VideoMetadata) string {
+	date, err := ParseRTVEDate(meta.PublicationDate)
+	if err != nil {
+		return ""
+	}
+	return date.Format(time.DateOnly)
+}
+
+// sortVersions sorts the versions of an episode oldest first, by
+// publication date and then by video ID, which RTVE assigns in increasing
+// order.
+func sortVersions(versions []*VideoMetadata) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		di, erri := ParseRTVEDate(versions[i].PublicationDate)
+		dj, errj := ParseRTVEDate(versions[j].PublicationDate)
+		if erri == nil && errj == nil && !di.Equal(dj) {
+			return di.Before(dj)
+		}
+
+		ni, erri := strconv.Atoi(versions[i].ID)
+		nj, errj := strconv.Atoi(versions[j].ID)
+		if erri == nil && errj == nil {
+			return ni < nj
+		}
+		return versions[i].ID < versions[j].ID
+	})
+}
+
+func normalizeTitle(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(title), " "))
+}
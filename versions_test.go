@@ -0,0 +1,131 @@
+package rtve
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordVersion(t *testing.T) {
+	folder := t.TempDir()
+	s := NewScrapper("telediario-2", WithOutputPath(folder))
+
+	first := &VideoMetadata{ID: "16492499", LongTitle: "Telediario - 21 horas - 14/03/25", PublicationDate: "14-03-2025 21:00:00"}
+	other := &VideoMetadata{ID: "16492510", LongTitle: "Telediario - 15 horas - 14/03/25", PublicationDate: "14-03-2025 15:00:00"}
+	for _, meta := range []*VideoMetadata{first, other} {
+		if err := s.SaveVideoToFile(meta, folder); err != nil {
+			t.Fatal(err)
+		}
+		replaced, err := s.recordVersion(meta, folder)
+		if err != nil || replaced != nil {
+			t.Fatalf("Expected no versions for %s, got %v, %v", meta.ID, replaced, err)
+		}
+	}
+	for _, name := range []string{VersionsFile, VersionsFile + ".lock"} {
+		if _, err := os.Stat(filepath.Join(folder, name)); !os.IsNotExist(err) {
+			t.Fatalf("Expected no %s for episodes published once", name)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(folder, "subs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "subs", "16492499_es.vtt"), []byte("WEBVTT\n\nfirst"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The corrected episode is published later under a new ID
+	corrected := &VideoMetadata{ID: "16492530", LongTitle: " telediario - 21 horas -  14/03/25", PublicationDate: "14-03-2025 23:10:00"}
+	if err := s.SaveVideoToFile(corrected, folder); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "subs", "16492530_es.vtt"), []byte("WEBVTT\n\ncorrected"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	replaced, err := s.recordVersion(corrected, folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replaced, []string{"16492499"}) {
+		t.Errorf("replaced = %v, want [16492499]", replaced)
+	}
+
+	versions, err := ReadVersions(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 {
+		t.Fatalf("Expected 1 re-published episode, got %+v", versions)
+	}
+	v := versions[0]
+	if !reflect.DeepEqual(v.IDs, []string{"16492499", "16492530"}) || v.Current != "16492530" {
+		t.Errorf("Unexpected versions: %+v", v)
+	}
+	if v.Version("16492499") != 1 || v.Version("16492530") != 2 || v.Version("16492510") != 0 {
+		t.Errorf("Unexpected version numbers: %+v", v)
+	}
+
+	// Both versions are kept
+	for _, id := range v.IDs {
+		if _, err := os.Stat(filepath.Join(folder, "video_"+id+".json")); err != nil {
+			t.Errorf("Version %s missing: %v", id, err)
+		}
+	}
+
+	// Subtitles are also kept with version suffixes
+	for version, expected := range map[string]string{"16492499_es.v1.vtt": "first", "16492530_es.v2.vtt": "corrected"} {
+		data, err := os.ReadFile(filepath.Join(folder, "subs", version))
+		if err != nil || !strings.HasSuffix(string(data), expected) {
+			t.Errorf("Unexpected %s: %q, %v", version, data, err)
+		}
+	}
+
+	for id, want := range map[string]string{"16492499": "16492530", "16492530": "16492530", "16492510": "16492510"} {
+		if current, err := CurrentVersion(folder, id); err != nil || current != want {
+			t.Errorf("CurrentVersion(%s) = %q, %v, want %q", id, current, err, want)
+		}
+	}
+}
+
+func TestRecordVersionSlugLayout(t *testing.T) {
+	root := t.TempDir()
+	s := NewScrapper("informe-semanal", WithOutputPath(root), WithLayout("{slug}"))
+
+	// Weekly episodes share their title and, with this layout, a folder
+	var folder string
+	for _, meta := range []*VideoMetadata{
+		{ID: "16492499", LongTitle: "Informe Semanal", PublicationDate: "08-03-2025 21:30:00"},
+		{ID: "16492530", LongTitle: "Informe Semanal", PublicationDate: "15-03-2025 21:30:00"},
+	} {
+		var err error
+		if folder, err = s.folderForVideo(meta); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(folder, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SaveVideoToFile(meta, folder); err != nil {
+			t.Fatal(err)
+		}
+		replaced, err := s.recordVersion(meta, folder)
+		if err != nil || replaced != nil {
+			t.Fatalf("Expected no versions for %s, got %v, %v", meta.ID, replaced, err)
+		}
+	}
+
+	if filepath.Dir(folder) != root {
+		t.Fatalf("Expected a single slug folder, got %s", folder)
+	}
+	if _, err := os.Stat(filepath.Join(folder, VersionsFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected no %s for episodes of different days", VersionsFile)
+	}
+}
+
+func TestReadVersionsEmpty(t *testing.T) {
+	versions, err := ReadVersions(t.TempDir())
+	if err != nil || len(versions) != 0 {
+		t.Errorf("Expected no versions, got %v, %v", versions, err)
+	}
+}
@@ -2,7 +2,11 @@ package rtve
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // VideoMetadata represents essential metadata from a video
@@ -12,6 +16,260 @@ type VideoMetadata struct {
 	ID              string `json:"id"`
 	LongTitle       string `json:"longTitle"`
 	PublicationDate string `json:"publicationDate"`
+	// Duration is the length of the video in milliseconds, 0 if unknown
+	Duration int64 `json:"duration"`
+	// Language is the ISO 639-1 code of the video's language, normalized
+	// with NormalizeLanguage, empty if RTVE doesn't give one
+	Language string `json:"language,omitempty"`
+
+	// Season is the season number RTVE orders the video by
+	// ("temporadaOrden"), 0 for shows without numbered seasons. It's saved
+	// as seasonNumber, since RTVE's own fields aren't always numbers.
+	Season int `json:"seasonNumber,omitempty"`
+	// Episode is the episode number within the season ("episode"), 0 if
+	// unknown, saved as episodeNumber
+	Episode int `json:"episodeNumber,omitempty"`
+
+	// ExpiresAt is when the video stops being available on RTVE Play, per
+	// its expirationDate (or contentEndDate). It's zero for videos without
+	// an availability window.
+	ExpiresAt time.Time `json:"-"`
+
+	// Raw is the complete JSON object the metadata was decoded from,
+	// including fields not modeled above. It's kept when marshaling, so
+	// saved metadata files don't lose any information.
+	Raw json.RawMessage `json:"-"`
+}
+
+// videoMetadataFields has the fields of VideoMetadata without its JSON
+// methods, to encode and decode the modeled fields.
+type videoMetadataFields VideoMetadata
+
+// UnmarshalJSON decodes the modeled fields and keeps the whole object in Raw.
+// Season and episode numbers are decoded leniently, since RTVE sends them
+// as numbers, strings or null depending on the program, and ExpiresAt is
+// parsed from the availability dates.
+func (m *VideoMetadata) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*videoMetadataFields)(m)); err != nil {
+		return err
+	}
+
+	var extra struct {
+		Season         json.RawMessage `json:"temporadaOrden"`
+		Episode        json.RawMessage `json:"episode"`
+		ExpirationDate json.RawMessage `json:"expirationDate"`
+		ContentEndDate json.RawMessage `json:"contentEndDate"`
+	}
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	if n, ok := parseLenientInt(extra.Season); ok {
+		m.Season = n
+	}
+	if n, ok := parseLenientInt(extra.Episode); ok {
+		m.Episode = n
+	}
+	// Malformed dates are ignored like missing ones, availability is
+	// informative only
+	for _, raw := range []json.RawMessage{extra.ExpirationDate, extra.ContentEndDate} {
+		var date string
+		if json.Unmarshal(raw, &date) != nil || date == "" {
+			continue
+		}
+		if t, err := ParseRTVEDate(date); err == nil {
+			m.ExpiresAt = t
+			break
+		}
+	}
+
+	m.Language = NormalizeLanguage(m.Language)
+
+	m.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// parseLenientInt decodes a JSON number or a string holding one.
+func parseLenientInt(data json.RawMessage) (int, bool) {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil && string(data) != "null" {
+		return n, true
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(str))
+	return n, err == nil
+}
+
+// MarshalJSON encodes Raw with the modeled fields on top, so changes to
+// them (e.g. corrections) take precedence over the original values. The
+// slug of the title (see Slug) is added as "slug", for tools reading the
+// archive.
+func (m VideoMetadata) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(struct {
+		videoMetadataFields
+		Slug string `json:"slug"`
+	}{videoMetadataFields(m), m.Slug()})
+	if err != nil || len(m.Raw) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return mergeJSONObject(m.Raw, fields)
+}
+
+// mergeJSONObject returns the JSON object base with fields added or replaced.
+func mergeJSONObject(base json.RawMessage, fields map[string]json.RawMessage) (json.RawMessage, error) {
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(base, &merged); err != nil {
+		return nil, err
+	}
+	if merged == nil {
+		merged = make(map[string]json.RawMessage)
+	}
+
+	for name, value := range fields {
+		merged[name] = value
+	}
+
+	return json.Marshal(merged)
+}
+
+// VideoMetadataFull is the complete metadata RTVE publishes for a video,
+// for archivists that need more than VideoMetadata models. See
+// VideoMetadata.Full. Dates use RTVE's DateLayout format and fields RTVE
+// leaves null are zero values.
+type VideoMetadataFull struct {
+	URI              string   `json:"uri"`
+	HTMLUrl          string   `json:"htmlUrl"`
+	HTMLShortURL     string   `json:"htmlShortUrl"`
+	ID               string   `json:"id"`
+	Language         string   `json:"language"`
+	Title            string   `json:"title"`
+	LongTitle        string   `json:"longTitle"`
+	ShortTitle       string   `json:"shortTitle"`
+	ShortDescription string   `json:"shortDescription"`
+	Description      string   `json:"description"` // HTML
+	MainTopic        string   `json:"mainTopic"`
+	TopicsName       []string `json:"topicsName"`
+
+	PublicationDate          string `json:"publicationDate"`
+	PublicationDateTimestamp int64  `json:"publicationDateTimestamp"` // Unix milliseconds
+	ModificationDate         string `json:"modificationDate"`
+	DateOfEmission           string `json:"dateOfEmission"`
+	ExpirationDate           string `json:"expirationDate"`
+
+	// Duration is the length of the video in milliseconds
+	Duration    int64     `json:"duration"`
+	ContentType string    `json:"contentType"`
+	AssetType   string    `json:"assetType"`
+	Consumption string    `json:"consumption"`
+	Type        VideoType `json:"type"`
+	AspectRatio string    `json:"aspectRatio"`
+
+	// Season and Episode are decoded like VideoMetadata's
+	Season  int `json:"-"`
+	Episode int `json:"-"`
+
+	ImageSEO  string   `json:"imageSEO"`
+	Thumbnail string   `json:"thumbnail"`
+	Previews  Previews `json:"previews"`
+
+	Qualities   []Quality   `json:"qualities"`
+	ProgramInfo ProgramInfo `json:"programInfo"`
+	Genres      []Genre     `json:"generos"`
+	Director    string      `json:"director"`
+
+	AllowedInCountry bool   `json:"allowedInCountry"`
+	Country          string `json:"country"`
+	PaidContent      bool   `json:"paidContent"`
+	HasDRM           bool   `json:"hasDRM"`
+	NotDownloadable  bool   `json:"notDownloadable"`
+
+	SubtitleRef      string `json:"subtitleRef"`
+	TranscriptionRef string `json:"transcriptionRef"`
+	ProgramRef       string `json:"programRef"`
+}
+
+// VideoType is the editorial type of a video, e.g. "Completo" for full
+// episodes.
+type VideoType struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Previews has the URLs of the preview images of a video in each aspect.
+type Previews struct {
+	Horizontal  string `json:"horizontal"`
+	Horizontal2 string `json:"horizontal2"`
+	Vertical    string `json:"vertical"`
+	Vertical2   string `json:"vertical2"`
+	Square      string `json:"square"`
+	Square2     string `json:"square2"`
+}
+
+// Quality describes one of the encodings a video is available in.
+type Quality struct {
+	Identifier  int64  `json:"identifier"`
+	FilePath    string `json:"filePath"`
+	Preset      string `json:"preset"`
+	FileSize    int64  `json:"filesize"` // bytes
+	Type        string `json:"type"`
+	Duration    int64  `json:"duration"` // milliseconds
+	BitRate     int64  `json:"bitRate"`
+	BitRateUnit string `json:"bitRateUnit"`
+	Language    string `json:"language"`
+	PreviewPath string `json:"previewPath"`
+	Height      int    `json:"height"`
+	Width       int    `json:"width"`
+}
+
+// ProgramInfo describes the program a video belongs to.
+type ProgramInfo struct {
+	ID               string `json:"id"`
+	Title            string `json:"title"`
+	HTMLUrl          string `json:"htmlUrl"`
+	ChannelPermalink string `json:"channelPermalink"`
+	AgeRangeUID      string `json:"ageRangeUid"`
+	AgeRange         string `json:"ageRange"`
+	ProgramType      string `json:"programType"`
+	ProgramTypeID    string `json:"programTypeId"`
+	OutOfEmission    bool   `json:"outOfEmission"`
+}
+
+// Genre is a genre RTVE files a video under.
+type Genre struct {
+	Name string `json:"generoInf"`
+	UID  string `json:"generoInfUid"`
+	ID   string `json:"generoId"`
+}
+
+// Full decodes the complete RTVE metadata the video was parsed from. It
+// fails if the metadata wasn't decoded from JSON, so there's no Raw.
+func (m *VideoMetadata) Full() (*VideoMetadataFull, error) {
+	if len(m.Raw) == 0 {
+		return nil, fmt.Errorf("no raw metadata for video %s", m.ID)
+	}
+
+	// Decode the raw object as saved, so corrections to modeled fields apply
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	full := &VideoMetadataFull{}
+	if err := json.Unmarshal(data, full); err != nil {
+		return nil, fmt.Errorf("error decoding full metadata for video %s: %w", m.ID, err)
+	}
+	full.Season, full.Episode = m.Season, m.Episode
+
+	return full, nil
 }
 
 // VideoPage represents the page of video items
@@ -37,10 +295,123 @@ func (m *VideoMetadata) Parse(body string) error {
 	}
 
 	if len(videoResp.Page.Items) == 0 {
-		return fmt.Errorf("no video metadata found")
+		return ErrMetadataNotFound
 	}
 
 	*m = videoResp.Page.Items[0]
 
 	return nil
 }
+
+// GeoBlocked reports whether RTVE flags the video as not available in the
+// client's country, per the allowedInCountry field of the raw metadata.
+func (m *VideoMetadata) GeoBlocked() bool {
+	var fields struct {
+		AllowedInCountry *bool `json:"allowedInCountry"`
+	}
+	if len(m.Raw) == 0 || json.Unmarshal(m.Raw, &fields) != nil || fields.AllowedInCountry == nil {
+		return false
+	}
+	return !*fields.AllowedInCountry
+}
+
+// EpisodeCode returns the SxxEyy code of the video, e.g. "S02E05", for
+// naming files of non-daily shows. Videos of shows without numbered
+// seasons get just the episode ("E05"), and an empty string is returned
+// if the episode number is unknown.
+func (m *VideoMetadata) EpisodeCode() string {
+	switch {
+	case m.Episode <= 0:
+		return ""
+	case m.Season <= 0:
+		return fmt.Sprintf("E%02d", m.Episode)
+	}
+	return fmt.Sprintf("S%02dE%02d", m.Season, m.Episode)
+}
+
+// ExpiresWithin reports whether the video stops being available within d
+// of now, or already has. Videos without an expiry date never expire.
+func (m *VideoMetadata) ExpiresWithin(d time.Duration, now time.Time) bool {
+	return !m.ExpiresAt.IsZero() && m.ExpiresAt.Sub(now) <= d
+}
+
+// Length returns the duration of the video, 0 if unknown.
+func (m *VideoMetadata) Length() time.Duration {
+	return time.Duration(m.Duration) * time.Millisecond
+}
+
+// DurationInRange reports whether the video length is within [min, max].
+// A zero min or max disables that bound, and videos with an unknown
+// duration are always in range.
+func (m *VideoMetadata) DurationInRange(min, max time.Duration) bool {
+	length := m.Length()
+	if length == 0 {
+		return true
+	}
+	if min > 0 && length < min {
+		return false
+	}
+	if max > 0 && length > max {
+		return false
+	}
+	return true
+}
+
+// ErrInvalidMetadata is matched by the errors Validate returns.
+var ErrInvalidMetadata = errors.New("invalid video metadata")
+
+// MetadataError reports a missing or malformed metadata field.
+type MetadataError struct {
+	// VideoID is the ID of the video, empty if the ID itself is missing
+	VideoID string
+	// Field is the JSON name of the offending field
+	Field string
+	// Err describes the problem
+	Err error
+}
+
+func (e *MetadataError) Error() string {
+	return fmt.Sprintf("metadata for video %s: field %s: %v", e.VideoID, e.Field, e.Err)
+}
+
+func (e *MetadataError) Unwrap() error {
+	return e.Err
+}
+
+func (e *MetadataError) Is(target error) bool {
+	return target == ErrInvalidMetadata
+}
+
+// Validate checks that the fields the archive relies on are present and
+// well formed, returning a *MetadataError for each one that isn't, joined.
+// Decoding alone is lenient and leaves missing fields as zero values.
+func (m *VideoMetadata) Validate() error {
+	var errs []error
+	invalid := func(field string, err error) {
+		errs = append(errs, &MetadataError{VideoID: m.ID, Field: field, Err: err})
+	}
+	missing := errors.New("missing")
+
+	if m.ID == "" {
+		invalid("id", missing)
+	}
+	if m.URI == "" {
+		invalid("uri", missing)
+	}
+	if m.HTMLUrl == "" {
+		invalid("htmlUrl", missing)
+	}
+	if m.LongTitle == "" {
+		invalid("longTitle", missing)
+	}
+	if m.PublicationDate == "" {
+		invalid("publicationDate", missing)
+	} else if _, err := ParseRTVEDate(m.PublicationDate); err != nil {
+		invalid("publicationDate", err)
+	}
+	if m.Duration <= 0 {
+		invalid("duration", missing)
+	}
+
+	return errors.Join(errs...)
+}
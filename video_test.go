@@ -1,8 +1,12 @@
 package rtve
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestParseMetadata(t *testing.T) {
@@ -67,3 +71,278 @@ func TestParseMetadataMalformedJSON(t *testing.T) {
 		t.Error("Expected error for malformed JSON, got nil")
 	}
 }
+
+func TestParseMetadataDuration(t *testing.T) {
+	data, err := os.ReadFile("fixtures/video.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+
+	m := &VideoMetadata{}
+	if err := m.Parse(string(data)); err != nil {
+		t.Fatalf("Failed to parse metadata: %v", err)
+	}
+
+	if m.Duration != 2753040 {
+		t.Errorf("Expected Duration to be 2753040, got %d", m.Duration)
+	}
+
+	expected := 45*time.Minute + 53*time.Second + 40*time.Millisecond
+	if m.Length() != expected {
+		t.Errorf("Expected Length to be %s, got %s", expected, m.Length())
+	}
+}
+
+func TestDurationInRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration int64
+		min      time.Duration
+		max      time.Duration
+		expected bool
+	}{
+		{"no limits", 120000, 0, 0, true},
+		{"unknown duration", 0, 5 * time.Minute, 10 * time.Minute, true},
+		{"shorter than min", 120000, 5 * time.Minute, 0, false},
+		{"equal to min", 300000, 5 * time.Minute, 0, true},
+		{"longer than max", 3600000, 0, 30 * time.Minute, false},
+		{"within limits", 1800000, 5 * time.Minute, time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &VideoMetadata{Duration: tt.duration}
+			if got := m.DurationInRange(tt.min, tt.max); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestVideoMetadataRaw(t *testing.T) {
+	data, err := os.ReadFile("fixtures/video.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+
+	meta := &VideoMetadata{}
+	if err := meta.Parse(string(data)); err != nil {
+		t.Fatalf("Failed to parse metadata: %v", err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(meta.Raw, &raw); err != nil {
+		t.Fatalf("Raw is not a JSON object: %v", err)
+	}
+	if raw["mainCategoryRef"] != "https://www.rtve.es/api/tematicas/135930" {
+		t.Errorf("Raw is missing unmodeled fields: %v", raw["mainCategoryRef"])
+	}
+
+	// Saved files keep unmodeled fields, with modeled fields taking precedence
+	meta.LongTitle = "Corrected title"
+	dir := t.TempDir()
+	if err := NewScrapper("telediario-2").SaveVideoToFile(meta, dir); err != nil {
+		t.Fatalf("Failed to save metadata: %v", err)
+	}
+
+	saved, err := os.ReadFile(filepath.Join(dir, "video_16492499.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reloaded VideoMetadata
+	if err := json.Unmarshal(saved, &reloaded); err != nil {
+		t.Fatalf("Failed to decode saved metadata: %v", err)
+	}
+	if reloaded.LongTitle != "Corrected title" || reloaded.ID != "16492499" {
+		t.Errorf("Unexpected modeled fields in saved file: %+v", reloaded)
+	}
+
+	var savedRaw map[string]any
+	if err := json.Unmarshal(saved, &savedRaw); err != nil {
+		t.Fatal(err)
+	}
+	if savedRaw["mainCategoryRef"] != raw["mainCategoryRef"] {
+		t.Errorf("Saved file lost unmodeled fields")
+	}
+}
+
+func TestVideoMetadataMarshalWithoutRaw(t *testing.T) {
+	data, err := json.Marshal(VideoMetadata{ID: "1", Duration: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"uri":"","htmlUrl":"","id":"1","longTitle":"","publicationDate":"","duration":5,"slug":"1"}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestVideoMetadataValidate(t *testing.T) {
+	data, err := os.ReadFile("fixtures/video.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+
+	meta := &VideoMetadata{}
+	if err := meta.Parse(string(data)); err != nil {
+		t.Fatalf("Failed to parse metadata: %v", err)
+	}
+	if err := meta.Validate(); err != nil {
+		t.Errorf("Expected fixture metadata to be valid, got %v", err)
+	}
+
+	meta.PublicationDate = "2025-03-14"
+	meta.Duration = 0
+	err = meta.Validate()
+	if !errors.Is(err, ErrInvalidMetadata) {
+		t.Fatalf("Expected ErrInvalidMetadata, got %v", err)
+	}
+
+	var metaErr *MetadataError
+	if !errors.As(err, &metaErr) || metaErr.VideoID != "16492499" || metaErr.Field != "publicationDate" {
+		t.Errorf("Expected a MetadataError for publicationDate, got %v", err)
+	}
+	if len(err.(interface{ Unwrap() []error }).Unwrap()) != 2 {
+		t.Errorf("Expected both invalid fields to be reported, got %v", err)
+	}
+}
+
+func TestVideoMetadataFull(t *testing.T) {
+	data, err := os.ReadFile("fixtures/video.json")
+	if err != nil {
+		t.Fatalf("Failed to read test fixture: %v", err)
+	}
+
+	meta := &VideoMetadata{}
+	if err := meta.Parse(string(data)); err != nil {
+		t.Fatalf("Failed to parse metadata: %v", err)
+	}
+	meta.LongTitle = "Corrected title"
+
+	full, err := meta.Full()
+	if err != nil {
+		t.Fatalf("Full failed: %v", err)
+	}
+
+	if full.LongTitle != "Corrected title" {
+		t.Errorf("Expected changes to modeled fields to be kept, got %q", full.LongTitle)
+	}
+	if full.Duration != 2753040 || full.AssetType != "video" || full.Type.Name != "Completo" {
+		t.Errorf("Unexpected basic fields: %+v", full)
+	}
+	if len(full.Qualities) != 4 || full.Qualities[0].Preset != "HD_FULL" || full.Qualities[0].Width != 1920 {
+		t.Errorf("Unexpected qualities: %+v", full.Qualities)
+	}
+	if full.ProgramInfo.Title != "Telediario 2" || full.ProgramInfo.ChannelPermalink != "la1" {
+		t.Errorf("Unexpected program info: %+v", full.ProgramInfo)
+	}
+	if len(full.Genres) != 1 || full.Genres[0].UID != "GE_INFOR" {
+		t.Errorf("Unexpected genres: %+v", full.Genres)
+	}
+	if full.Previews.Vertical == "" || full.Thumbnail == "" || full.Description == "" {
+		t.Errorf("Expected images and description to be decoded: %+v", full)
+	}
+	if !full.AllowedInCountry || full.ExpirationDate != "" {
+		t.Errorf("Unexpected availability: allowed %v, expires %q", full.AllowedInCountry, full.ExpirationDate)
+	}
+
+	if _, err := (&VideoMetadata{ID: "1"}).Full(); err == nil {
+		t.Error("Expected an error for metadata without raw JSON")
+	}
+}
+
+func TestVideoMetadataSeasonEpisode(t *testing.T) {
+	tests := []struct {
+		json    string
+		season  int
+		episode int
+		code    string
+	}{
+		{`{"id":"1","temporadaOrden":2,"episode":5}`, 2, 5, "S02E05"},
+		{`{"id":"1","temporadaOrden":"3","episode":"12"}`, 3, 12, "S03E12"},
+		{`{"id":"1","temporadaOrden":null,"episode":7}`, 0, 7, "E07"},
+		{`{"id":"1","temporadaOrden":null,"episode":0}`, 0, 0, ""},
+		{`{"id":"1","temporadaOrden":{"bogus":true},"episode":"n/a"}`, 0, 0, ""},
+	}
+
+	for _, tt := range tests {
+		var meta VideoMetadata
+		if err := json.Unmarshal([]byte(tt.json), &meta); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", tt.json, err)
+		}
+		if meta.Season != tt.season || meta.Episode != tt.episode || meta.EpisodeCode() != tt.code {
+			t.Errorf("Unmarshal(%s) = season %d, episode %d, code %q; want %d, %d, %q",
+				tt.json, meta.Season, meta.Episode, meta.EpisodeCode(), tt.season, tt.episode, tt.code)
+		}
+	}
+
+	// Numbers are saved as modeled fields, next to RTVE's own
+	data, err := json.Marshal(&VideoMetadata{ID: "1", Season: 2, Episode: 5, Raw: json.RawMessage(`{"id":"1","temporadaOrden":"2","episode":"5"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]any
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved["seasonNumber"] != 2.0 || saved["episodeNumber"] != 5.0 || saved["episode"] != "5" {
+		t.Errorf("Unexpected saved numbering: %s", data)
+	}
+	var reloaded VideoMetadata
+	if err := json.Unmarshal(data, &reloaded); err != nil || reloaded.EpisodeCode() != "S02E05" {
+		t.Errorf("Expected saved numbering to load back, got %q, %v", reloaded.EpisodeCode(), err)
+	}
+
+	// Corrections can fix the numbering
+	meta := &VideoMetadata{}
+	if err := json.Unmarshal([]byte(`{"id":"1","temporadaOrden":1,"episode":4}`), meta); err != nil {
+		t.Fatal(err)
+	}
+	c := Corrections{"1": {"episode": json.RawMessage(`3`)}}
+	if err := c.Apply(meta); err != nil {
+		t.Fatal(err)
+	}
+	if meta.EpisodeCode() != "S01E03" {
+		t.Errorf("Expected corrected episode code S01E03, got %q", meta.EpisodeCode())
+	}
+}
+
+func TestVideoMetadataExpiresAt(t *testing.T) {
+	expires := time.Date(2025, 4, 13, 23, 59, 0, 0, time.UTC)
+	tests := []struct {
+		json    string
+		expires time.Time
+	}{
+		{`{"id":"1","expirationDate":"13-04-2025 23:59:00"}`, expires},
+		{`{"id":"1","expirationDate":null,"contentEndDate":"13-04-2025 23:59:00"}`, expires},
+		{`{"id":"1","expirationDate":"soon","contentEndDate":"13-04-2025 23:59:00"}`, expires},
+		{`{"id":"1","expirationDate":1744588740000}`, time.Time{}},
+		{`{"id":"1"}`, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		var meta VideoMetadata
+		if err := json.Unmarshal([]byte(tt.json), &meta); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", tt.json, err)
+		}
+		if !meta.ExpiresAt.Equal(tt.expires) {
+			t.Errorf("Unmarshal(%s): ExpiresAt = %v, want %v", tt.json, meta.ExpiresAt, tt.expires)
+		}
+	}
+
+	meta := VideoMetadata{ExpiresAt: expires}
+	if !meta.ExpiresWithin(48*time.Hour, expires.Add(-24*time.Hour)) {
+		t.Error("Expected the video to expire within 48h")
+	}
+	if meta.ExpiresWithin(time.Hour, expires.Add(-24*time.Hour)) {
+		t.Error("Expected the video not to expire within 1h")
+	}
+	if !meta.ExpiresWithin(0, expires.Add(time.Hour)) {
+		t.Error("Expected an expired video to count as expiring")
+	}
+	if (&VideoMetadata{}).ExpiresWithin(24*time.Hour, expires) {
+		t.Error("Expected videos without expiry date never to expire")
+	}
+}
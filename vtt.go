@@ -0,0 +1,155 @@
+package rtve
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cue is a single timed caption in a WebVTT file.
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	// Text is the caption text as found in the file, including markup such
+	// as <c.yellow> or <v Speaker> tags. Lines are separated by "\n".
+	Text string
+}
+
+// PlainText returns the cue text without markup, with lines joined by
+// spaces.
+func (c Cue) PlainText() string {
+	return strings.Join(strings.Fields(vttTagPattern.ReplaceAllString(c.Text, "")), " ")
+}
+
+var vttTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// ParseVTT parses the cues of a WebVTT subtitle file. Comments, style and
+// region blocks are skipped.
+func ParseVTT(r io.Reader) ([]Cue, error) {
+	scanner := bufio.NewScanner(r)
+
+	var cues []Cue
+	var cue *Cue
+	skipping := false
+	lineNo := 0
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		lineNo++
+
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+			if !strings.HasPrefix(line, "WEBVTT") {
+				return nil, fmt.Errorf("not a WebVTT file: missing WEBVTT header")
+			}
+			skipping = true
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			if cue != nil {
+				cues = append(cues, *cue)
+				cue = nil
+			}
+			skipping = false
+			continue
+		}
+
+		if skipping {
+			continue
+		}
+
+		if cue != nil {
+			if cue.Text != "" {
+				cue.Text += "\n"
+			}
+			cue.Text += line
+			continue
+		}
+
+		if !strings.Contains(line, "-->") {
+			// Cue identifier, or a NOTE, STYLE or REGION block
+			if strings.HasPrefix(line, "NOTE") || line == "STYLE" || line == "REGION" {
+				skipping = true
+			}
+			continue
+		}
+
+		start, end, err := parseCueTiming(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		cue = &Cue{Start: start, End: end}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading subtitles: %w", err)
+	}
+
+	if cue != nil {
+		cues = append(cues, *cue)
+	}
+
+	return cues, nil
+}
+
+// parseCueTiming parses a "00:00:01.000 --> 00:00:03.000 [settings]" line.
+func parseCueTiming(line string) (time.Duration, time.Duration, error) {
+	from, to, _ := strings.Cut(line, "-->")
+	fields := strings.Fields(to)
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("invalid cue timing %q", line)
+	}
+
+	start, err := parseVTTTimestamp(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end, err := parseVTTTimestamp(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return start, end, nil
+}
+
+// parseVTTTimestamp parses "hh:mm:ss.ttt" or "mm:ss.ttt" timestamps.
+func parseVTTTimestamp(ts string) (time.Duration, error) {
+	parts := strings.Split(ts, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", ts)
+	}
+
+	secs, millis, _ := strings.Cut(parts[len(parts)-1], ".")
+	fields := append(parts[:len(parts)-1], secs)
+	if millis == "" {
+		millis = "0"
+	}
+
+	var d time.Duration
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", ts)
+		}
+		d = d*60 + time.Duration(n)
+	}
+
+	ms, err := strconv.Atoi(millis)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", ts)
+	}
+
+	return d*time.Second + time.Duration(ms)*time.Millisecond, nil
+}
+
+// FormatVTTTimestamp formats d as a WebVTT timestamp, e.g. "01:02:03.456".
+func FormatVTTTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,140 @@
+package rtve
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testVTT = `WEBVTT
+Kind: captions
+
+NOTE This comment
+spans two lines
+
+1
+00:00:01.000 --> 00:00:03.500 line:90%
+<c.yellow>Buenas tardes,</c>
+bienvenidos al Telediario.
+
+2
+00:00:04.000 --> 00:00:06.000
+El Gobierno ha aprobado hoy
+
+00:01:06.250 --> 01:00:08.000
+la nueva ley. ¿Qué cambia?
+`
+
+func TestParseVTT(t *testing.T) {
+	cues, err := ParseVTT(strings.NewReader(testVTT))
+	if err != nil {
+		t.Fatalf("ParseVTT failed: %v", err)
+	}
+
+	if len(cues) != 3 {
+		t.Fatalf("Expected 3 cues, got %d: %+v", len(cues), cues)
+	}
+
+	if cues[0].Start != time.Second || cues[0].End != 3500*time.Millisecond {
+		t.Errorf("Unexpected timing for first cue: %v --> %v", cues[0].Start, cues[0].End)
+	}
+
+	if cues[0].Text != "<c.yellow>Buenas tardes,</c>\nbienvenidos al Telediario." {
+		t.Errorf("Unexpected text for first cue: %q", cues[0].Text)
+	}
+
+	if cues[0].PlainText() != "Buenas tardes, bienvenidos al Telediario." {
+		t.Errorf("Unexpected plain text for first cue: %q", cues[0].PlainText())
+	}
+
+	if cues[2].Start != 66250*time.Millisecond || cues[2].End != time.Hour+8*time.Second {
+		t.Errorf("Unexpected timing for last cue: %v --> %v", cues[2].Start, cues[2].End)
+	}
+}
+
+func TestParseVTTInvalid(t *testing.T) {
+	if _, err := ParseVTT(strings.NewReader("1\n00:00:01.000 --> 00:00:02.000\nHola\n")); err == nil {
+		t.Error("Expected error for missing header")
+	}
+
+	if _, err := ParseVTT(strings.NewReader("WEBVTT\n\n00:00:aa.000 --> 00:00:02.000\nHola\n")); err == nil {
+		t.Error("Expected error for invalid timestamp")
+	}
+}
+
+func TestSentences(t *testing.T) {
+	cues, err := ParseVTT(strings.NewReader(testVTT))
+	if err != nil {
+		t.Fatalf("ParseVTT failed: %v", err)
+	}
+
+	expected := []Sentence{
+		{Start: time.Second, End: 3500 * time.Millisecond, Text: "Buenas tardes, bienvenidos al Telediario."},
+		{Start: 4 * time.Second, End: time.Hour + 8*time.Second, Text: "El Gobierno ha aprobado hoy la nueva ley."},
+		{Start: 66250 * time.Millisecond, End: time.Hour + 8*time.Second, Text: "¿Qué cambia?"},
+	}
+
+	sentences := Sentences(cues)
+	if len(sentences) != len(expected) {
+		t.Fatalf("Expected %d sentences, got %d: %+v", len(expected), len(sentences), sentences)
+	}
+
+	for i, s := range sentences {
+		if s != expected[i] {
+			t.Errorf("Sentence %d: expected %+v, got %+v", i, expected[i], s)
+		}
+	}
+}
+
+func TestSentencesUnterminated(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: time.Second, Text: "Sin punto"},
+		{Start: time.Second, End: 2 * time.Second, Text: "final"},
+	}
+
+	sentences := Sentences(cues)
+	if len(sentences) != 1 || sentences[0].Text != "Sin punto final" || sentences[0].End != 2*time.Second {
+		t.Errorf("Unexpected sentences: %+v", sentences)
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	cues, err := ParseVTT(strings.NewReader(testVTT + "\n00:01:10.000 --> 00:01:12.000\nI+D &amp; <i>innovación</i>.\n"))
+	if err != nil {
+		t.Fatalf("ParseVTT failed: %v", err)
+	}
+
+	expected := "Buenas tardes, bienvenidos al Telediario.\n" +
+		"El Gobierno ha aprobado hoy la nueva ley.\n" +
+		"¿Qué cambia?\n" +
+		"I+D & innovación.\n"
+	if got := Transcript(cues); got != expected {
+		t.Errorf("Unexpected transcript:\n%s", got)
+	}
+}
+
+func TestSaveTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "16492499_es.vtt")
+	if err := os.WriteFile(path, []byte(testVTT), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := SaveTranscript(path)
+	if err != nil {
+		t.Fatalf("SaveTranscript failed: %v", err)
+	}
+	if saved != strings.TrimSuffix(path, ".vtt")+".txt" {
+		t.Errorf("Unexpected transcript path: %s", saved)
+	}
+	if data, _ := os.ReadFile(saved); !strings.HasPrefix(string(data), "Buenas tardes,") {
+		t.Errorf("Unexpected transcript: %q", data)
+	}
+}
+
+func TestFormatVTTTimestamp(t *testing.T) {
+	if got := FormatVTTTimestamp(time.Hour + 2*time.Minute + 3456*time.Millisecond); got != "01:02:03.456" {
+		t.Errorf("Expected 01:02:03.456, got %s", got)
+	}
+}